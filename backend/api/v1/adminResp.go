@@ -0,0 +1,32 @@
+package v1
+
+import "time"
+
+// AdminUserData 管理端展示的用户信息（不包含密码）
+type AdminUserData struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Disabled  bool      `json:"disabled"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AdminUserListData 用户分页列表
+type AdminUserListData struct {
+	Total int64           `json:"total"`
+	Items []AdminUserData `json:"items"`
+}
+
+// AuditLogListData 审计日志分页列表
+type AuditLogListData struct {
+	Total int64 `json:"total"`
+	Items any   `json:"items"`
+}
+
+// AuditVerifyData 审计日志哈希链校验结果
+type AuditVerifyData struct {
+	Checked   int64 `json:"checked"`   // 已校验的条数
+	Intact    bool  `json:"intact"`    // 哈希链是否完整
+	BrokenID  uint  `json:"broken_id"` // 第一条异常记录的ID
+	Truncated bool  `json:"truncated"` // 末尾的日志是否被删除
+}
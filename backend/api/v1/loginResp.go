@@ -1,7 +1,52 @@
 package v1
 
+import "time"
+
 type LoginResponseData struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	UserID       uint   `json:"user_id"`
 }
+
+// OAuthAuthorizeData 第三方授权地址
+type OAuthAuthorizeData struct {
+	AuthURL string `json:"auth_url"`
+	State   string `json:"state"`
+}
+
+// OAuthCallbackData 第三方授权回调结果
+type OAuthCallbackData struct {
+	Action   string             `json:"action"` // login 登录 / link 绑定
+	Provider string             `json:"provider"`
+	UserID   uint               `json:"user_id"`
+	Login    *LoginResponseData `json:"login,omitempty"`
+}
+
+// 邮件链接登录的状态
+const (
+	MagicLinkPending          = "pending"           // 等待用户打开链接
+	MagicLinkApprovalRequired = "approval_required" // 在其他设备上打开，需要用户确认后批准
+	MagicLinkApproved         = "approved"          // 已批准，发起方轮询时完成登录
+	MagicLinkLoggedIn         = "logged_in"         // 已登录，login 中是令牌
+)
+
+// MagicLinkStartData 发起邮件链接登录的结果。nonce 只返回给发起方，轮询和在本设备打开链接时都需要提交
+type MagicLinkStartData struct {
+	RequestID string `json:"request_id"`
+	Nonce     string `json:"nonce"`
+	ExpiresIn int    `json:"expires_in"` // 有效期（秒）
+}
+
+// MagicLinkData 打开链接、批准或轮询的结果
+type MagicLinkData struct {
+	Status    string              `json:"status"`
+	Login     *LoginResponseData  `json:"login,omitempty"`
+	Requester *MagicLinkRequester `json:"requester,omitempty"` // approval_required 时发起登录的设备，供用户确认
+}
+
+// MagicLinkRequester 发起邮件链接登录的设备
+type MagicLinkRequester struct {
+	IP        string    `json:"ip"`
+	Device    string    `json:"device"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"blueLock/backend/init"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "配置工具",
+}
+
+func init() {
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "打印合并环境变量和密钥文件后的最终配置，密码、密钥和令牌会被隐藏",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inits.EnvInit()
+			inits.ConfigInit()
+
+			enc := yaml.NewEncoder(os.Stdout)
+			enc.SetIndent(2)
+			defer enc.Close()
+			return enc.Encode(redact(viper.AllSettings(), "", inits.EnvOnlyKeys()))
+		},
+	})
+}
+
+// redact 递归隐藏敏感配置的非空值：envOnly 中列出的键，以及键名包含 password、secret、token 或以 _key 结尾的键。
+// prefix 为当前层级的完整键名前缀，如 "app.metrics."
+func redact(settings map[string]any, prefix string, envOnly []string) map[string]any {
+	out := make(map[string]any, len(settings))
+	for k, v := range settings {
+		path := prefix + strings.ToLower(k)
+		if sensitive(path, envOnly) && v != nil && v != "" {
+			out[k] = "******"
+			continue
+		}
+		out[k] = redactValue(v, path+".", envOnly)
+	}
+	return out
+}
+
+// sensitive 判断完整键名 path 对应的配置是否需要隐藏
+func sensitive(path string, envOnly []string) bool {
+	if slices.Contains(envOnly, path) {
+		return true
+	}
+	name := path[strings.LastIndex(path, ".")+1:]
+	for _, s := range []string{"password", "secret", "token"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return name == "key" || strings.HasSuffix(name, "_key")
+}
+
+func redactValue(v any, prefix string, envOnly []string) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return redact(val, prefix, envOnly)
+	case []any:
+		list := make([]any, len(val))
+		for i, item := range val {
+			list[i] = redactValue(item, prefix, envOnly)
+		}
+		return list
+	default:
+		return v
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"blueLock/backend/init"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/pkg/mail"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var mailCmd = &cobra.Command{
+	Use:   "mail",
+	Short: "邮件相关",
+}
+
+var mailLocale string
+
+func init() {
+	testCmd := &cobra.Command{
+		Use:   "test <addr>",
+		Short: "按当前 mail 配置发送一封测试邮件",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// 发信只需要配置，不连数据库和 Redis
+			inits.EnvInit()
+			inits.ConfigInit()
+
+			if !i18n.IsSupported(mailLocale) {
+				return fmt.Errorf("不支持的语言: %s", mailLocale)
+			}
+			err := mail.Send(cmd.Context(), globals.AppConfig.Mail, mail.Message{
+				To:       args[0],
+				FromName: "blueLock",
+				Subject:  i18n.T(mailLocale, "mail.test.subject"),
+				HTML:     "<p>" + i18n.T(mailLocale, "mail.test.body") + "</p>",
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("测试邮件已发送至 %s\n", args[0])
+			return nil
+		},
+	}
+	testCmd.Flags().StringVar(&mailLocale, "locale", "zh-CN", "邮件语言")
+	mailCmd.AddCommand(testCmd)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"blueLock/backend/init"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/migrate"
+	"context"
+	"fmt"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// 数据库迁移需在部署时显式执行：backend migrate up
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "数据库迁移",
+}
+
+func init() {
+	migrateCmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "执行全部未执行的迁移",
+			Args:  cobra.NoArgs,
+			RunE: withMigrator(func(ctx context.Context, m *migrate.Migrator, args []string) error {
+				n, err := m.Up(ctx)
+				if err != nil {
+					return fmt.Errorf("迁移失败（已执行 %d 个）: %w", n, err)
+				}
+				fmt.Printf("已执行 %d 个迁移\n", n)
+				return nil
+			}),
+		},
+		&cobra.Command{
+			Use:   "down [n]",
+			Short: "回滚最近 n 个迁移，默认 1",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: withMigrator(func(ctx context.Context, m *migrate.Migrator, args []string) error {
+				steps := 1
+				if len(args) > 0 {
+					var err error
+					steps, err = strconv.Atoi(args[0])
+					if err != nil || steps <= 0 {
+						return fmt.Errorf("回滚数量无效: %s", args[0])
+					}
+				}
+				n, err := m.Down(ctx, steps)
+				if err != nil {
+					return fmt.Errorf("回滚失败（已回滚 %d 个）: %w", n, err)
+				}
+				fmt.Printf("已回滚 %d 个迁移\n", n)
+				return nil
+			}),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "查看迁移状态",
+			Args:  cobra.NoArgs,
+			RunE: withMigrator(func(ctx context.Context, m *migrate.Migrator, args []string) error {
+				list, err := m.Status(ctx)
+				if err != nil {
+					return err
+				}
+				for _, s := range list {
+					state := "pending"
+					if s.Applied {
+						state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+					}
+					fmt.Printf("%d  %-30s %s\n", s.Version, s.Name, state)
+				}
+				return nil
+			}),
+		},
+		&cobra.Command{
+			Use:   "force-unlock",
+			Short: "强制释放迁移锁（仅在迁移进程异常退出后使用）",
+			Args:  cobra.NoArgs,
+			RunE: withMigrator(func(ctx context.Context, m *migrate.Migrator, args []string) error {
+				if err := m.ForceUnlock(ctx); err != nil {
+					return err
+				}
+				fmt.Println("迁移锁已释放")
+				return nil
+			}),
+		},
+	)
+}
+
+// withMigrator 只初始化配置、日志和数据库，构造迁移器后执行 fn，收到中断信号时取消 ctx
+func withMigrator(fn func(ctx context.Context, m *migrate.Migrator, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		inits.Base()
+		defer func() { _ = globals.Log.Sync() }()
+
+		m, err := inits.NewMigrator()
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		return fn(ctx, m, args)
+	}
+}
@@ -0,0 +1,30 @@
+// Package cmd 后端命令行入口：serve、migrate、user、mail、keys、config
+package cmd
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:          "backend",
+	Short:        "blueLock 后端服务与运维命令",
+	SilenceUsage: true,
+	// 不带子命令时直接启动服务，兼容原来的启动方式
+	RunE: serveCmd.RunE,
+}
+
+func init() {
+	// 运行环境，对应 configs/<env>.yaml；不指定时读取 BLUELOCK_ENV，默认 dev
+	rootCmd.PersistentFlags().StringVar(&globals.Env, "env", "", "运行环境（configs/<env>.yaml）")
+	rootCmd.AddCommand(serveCmd, migrateCmd, userCmd, mailCmd, keysCmd, configCmd)
+}
+
+// Execute 解析命令行并执行对应命令，出错时以非零状态码退出
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"blueLock/backend/server"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "启动 HTTP 服务",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server.Run()
+		return nil
+	},
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"blueLock/backend/internal/pkg/password"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestPasswordFlagUsage --password 的说明与密码策略的默认最小长度一致
+func TestPasswordFlagUsage(t *testing.T) {
+	for _, name := range []string{"create", "reset-password"} {
+		c, _, err := userCmd.Find([]string{name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		usage := c.Flags().Lookup("password").Usage
+		if !strings.Contains(usage, strconv.Itoa(password.DefaultMinLength)+" 个字符") {
+			t.Fatalf("%s 的 --password 说明与密码策略不一致: %s", name, usage)
+		}
+	}
+}
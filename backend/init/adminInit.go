@@ -0,0 +1,75 @@
+package inits
+
+import (
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/emailaddr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/password"
+	"blueLock/backend/internal/pkg/rbac"
+	"blueLock/backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AdminInit 同步内置角色权限，并在系统中还没有管理员时根据配置创建初始管理员
+func AdminInit() {
+	ctx := context.Background()
+	if err := SyncRoles(ctx); err != nil {
+		globals.Log.Fatalf("%v", err)
+	}
+	roleRepo := repository.NewRoleRepository(globals.DB)
+
+	email := emailaddr.Normalize(globals.AppConfig.Admin.Email)
+	if email == "" {
+		return
+	}
+	count, err := roleRepo.CountUsersWithRole(ctx, rbac.RoleAdmin)
+	if err != nil {
+		globals.Log.Fatalf("查询管理员失败: %v", err)
+	}
+	if count > 0 {
+		return
+	}
+	if err := SeedAdmin(ctx, email, globals.AppConfig.Admin.Password); err != nil {
+		globals.Log.Fatalf("创建初始管理员失败: %v", err)
+	}
+	globals.Log.Infof("已创建初始管理员: %s", email)
+}
+
+// SyncRoles 把代码中定义的内置角色及其权限同步到数据库
+func SyncRoles(ctx context.Context) error {
+	roleRepo := repository.NewRoleRepository(globals.DB)
+	for name, perms := range rbac.BuiltinRoles {
+		if err := roleRepo.EnsureRole(ctx, name, perms); err != nil {
+			return fmt.Errorf("同步内置角色 %s 失败: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SeedAdmin 将邮箱对应的用户设为管理员，用户不存在时用给定密码创建，密码需符合密码策略
+func SeedAdmin(ctx context.Context, email, pass string) error {
+	userRepo := repository.NewLoginRepository(globals.DB)
+	roleRepo := repository.NewRoleRepository(globals.DB)
+
+	user, err := userRepo.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, apperr.ErrUserNotFound) {
+		return err
+	}
+	if user == nil {
+		if err := password.NewPolicy(globals.AppConfig.Password).Validate("admin.password", pass); err != nil {
+			return err
+		}
+		hashed, err := password.NewHasher(globals.AppConfig.Password.Hash).Hash(ctx, pass)
+		if err != nil {
+			return err
+		}
+		user = &models.User{Email: email, PassWord: hashed}
+		if err := userRepo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+	}
+	return roleRepo.AssignRole(ctx, user.ID, rbac.RoleAdmin)
+}
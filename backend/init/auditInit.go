@@ -0,0 +1,16 @@
+package inits
+
+import (
+	"blueLock/backend/internal/pkg/audit"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/repository"
+)
+
+// auditInit 启动审计日志异步写入器
+func auditInit() {
+	audit.Init(repository.NewAuditRepository(globals.DB), globals.Log, audit.Config{
+		BufferSize:    globals.AppConfig.Audit.BufferSize,
+		BatchSize:     globals.AppConfig.Audit.BatchSize,
+		FlushInterval: globals.AppConfig.Audit.FlushInterval,
+	})
+}
@@ -2,22 +2,255 @@ package inits
 
 import (
 	"blueLock/backend/internal/pkg/globals"
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
+// envOnlyKeys 只能通过环境变量或密钥文件提供的配置，写在配置文件中时拒绝启动，避免把凭据提交到仓库
+var envOnlyKeys = []string{"mail.password", "app.metrics.token"}
+
+// EnvOnlyKeys 返回只能通过环境变量或密钥文件提供的配置键，打印配置时据此隐藏
+func EnvOnlyKeys() []string {
+	return append([]string(nil), envOnlyKeys...)
+}
+
+// envPrefix 环境变量前缀：database.password 对应 BLUELOCK_DATABASE_PASSWORD，
+// 以 _FILE 结尾的变量（如 BLUELOCK_JWT_SECRET_KEY_FILE）表示从文件读取该值，用于挂载的密钥文件
+const envPrefix = "BLUELOCK"
+
+var (
+	// loadedConfig 最近一次加载成功的原始配置（未经各模块填充默认值），用于热更新时比较哪些配置变了
+	loadedConfig   globals.Config
+	loadedConfigMu sync.Mutex
+)
+
+// ConfigInit 读取配置文件，叠加环境变量和密钥文件后校验，任何错误都直接退出
 func ConfigInit() {
 	viper.SetConfigName(globals.Env)         // 配置文件名称
 	viper.SetConfigType("yaml")              // 如果配置问文件没有扩展名，则需要配置此项
 	viper.AddConfigPath("./backend/configs") // 查找配置文件所在路径
+	viper.AddConfigPath(".")                 // 还可以在工作目录中查找配置
+
+	if err := viper.ReadInConfig(); err != nil { // 查找并读取配置文件
+		log.Fatalf("读取配置文件 %s.yaml 错误: %v", globals.Env, err)
+	}
+
+	// 此时还没有叠加环境变量，读到的都是配置文件中的值
+	for _, key := range envOnlyKeys {
+		if viper.GetString(key) != "" {
+			env := envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+			log.Fatalf("配置文件 %s.yaml 中不能写 %s，请通过环境变量 %s 或 %s_FILE 提供", globals.Env, key, env, env)
+		}
+	}
 
-	viper.AddConfigPath(".")    // 还可以在工作目录中查找配置
-	err := viper.ReadInConfig() // 查找并读取配置文件
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	// AutomaticEnv 只对 viper 已知的键生效，这里把配置结构体中的每个键都注册一遍，配置文件中没写的键也能用环境变量设置
+	if err := bindEnvs(reflect.TypeOf(globals.Config{}), ""); err != nil {
+		log.Fatalf("读取密钥文件错误: %v", err)
+	}
+
+	cfg, err := decodeConfig()
 	if err != nil {
-		fmt.Println("读取配置文件错误", err)
+		log.Fatalf("配置错误:\n%v", err)
 	}
+	loadedConfig = *cfg
 	// 将配置解析到 AppConfig 结构体
-	if err := viper.Unmarshal(&globals.AppConfig); err != nil {
-		fmt.Println("配置解析错误", err)
+	globals.AppConfig = *cfg
+}
+
+// WatchConfig 监听配置文件变化。只有日志级别和跨域白名单可以热更新，修改后回调 onChange；
+// 其他配置的修改只记录警告，重启后生效。修改后的配置校验失败时忽略本次修改。
+// 返回的 stop 停止监听，可以重复调用
+func WatchConfig(onChange func(cfg *globals.Config)) (stop func() error, err error) {
+	configFile := filepath.Clean(viper.ConfigFileUsed())
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("监听配置文件失败: %w", err)
+	}
+	// 监听所在目录而不是文件本身：编辑器保存、k8s ConfigMap 更新都是替换文件或符号链接
+	if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("监听配置目录失败: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		realFile, _ := filepath.EvalSymlinks(configFile)
+		for {
+			select {
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				current, _ := filepath.EvalSymlinks(configFile)
+				modified := filepath.Clean(ev.Name) == configFile && ev.Op&(fsnotify.Write|fsnotify.Create) != 0
+				if !modified && (current == "" || current == realFile) {
+					continue
+				}
+				realFile = current
+				if err := viper.ReadInConfig(); err != nil {
+					globals.Log.Errorf("重新读取配置文件 %s 失败，忽略本次修改: %v", configFile, err)
+					continue
+				}
+				applyConfigChange(configFile, onChange)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				globals.Log.Errorf("监听配置文件出错: %v", err)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() {
+			err = watcher.Close()
+			<-done
+		})
+		return err
+	}, nil
+}
+
+// applyConfigChange 校验修改后的配置，只应用可以热更新的部分
+func applyConfigChange(name string, onChange func(cfg *globals.Config)) {
+	cfg, err := decodeConfig()
+	if err != nil {
+		globals.Log.Errorf("配置文件 %s 修改后校验失败，忽略本次修改:\n%v", name, err)
+		return
+	}
+
+	loadedConfigMu.Lock()
+	applied := loadedConfig
+	applied.Log.Level = cfg.Log.Level
+	applied.Log.FileLevel = cfg.Log.FileLevel
+	applied.Cors = cfg.Cors
+	if !reflect.DeepEqual(applied, *cfg) {
+		globals.Log.Warnf("配置文件 %s 中除 log.level、log.file_level、cors 以外的修改需要重启后生效", name)
+	}
+	loadedConfig = applied
+	loadedConfigMu.Unlock()
+
+	globals.Log.Infof("配置已热更新: log.level=%q log.file_level=%q cors.allowed_origins=%v", cfg.Log.Level, cfg.Log.FileLevel, cfg.Cors.AllowedOrigins)
+	onChange(&applied)
+}
+
+// decodeConfig 把 viper 中的配置解析为结构体并校验
+func decodeConfig() (*globals.Config, error) {
+	var cfg globals.Config
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("配置解析错误: %w", err)
+	}
+	if err := ValidateConfig(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// bindEnvs 递归注册配置结构体中的所有键，并处理 _FILE 形式的密钥文件
+func bindEnvs(t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + tag
+		if field.Type.Kind() == reflect.Struct {
+			if err := bindEnvs(field.Type, key+"."); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := viper.BindEnv(key); err != nil {
+			return err
+		}
+		envName := envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if path := os.Getenv(envName + "_FILE"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("%s_FILE: %w", envName, err)
+			}
+			// 密钥文件末尾通常带换行
+			viper.Set(key, strings.TrimRight(string(data), "\r\n"))
+		}
+	}
+	return nil
+}
+
+var (
+	configValidator     *validator.Validate
+	configValidatorOnce sync.Once
+)
+
+// ValidateConfig 校验配置，错误信息使用配置文件中的键名，如 jwt.secret_key
+func ValidateConfig(cfg *globals.Config) error {
+	configValidatorOnce.Do(func() {
+		configValidator = validator.New()
+		configValidator.RegisterTagNameFunc(func(field reflect.StructField) string {
+			return field.Tag.Get("mapstructure")
+		})
+	})
+
+	err := configValidator.Struct(cfg)
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		// Namespace 形如 Config.jwt.secret_key，去掉根结构体名
+		key := fe.Namespace()
+		if i := strings.IndexByte(key, '.'); i >= 0 {
+			key = key[i+1:]
+		}
+		msgs = append(msgs, fmt.Sprintf("  %s: %s", key, validationMessage(fe)))
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless":
+		return "不能为空"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("长度不能小于 %s", fe.Param())
+		}
+		return fmt.Sprintf("不能小于 %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("不能大于 %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("必须大于 %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("不能小于 %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("不能大于 %s", fe.Param())
+	case "file":
+		return fmt.Sprintf("文件不存在: %v", fe.Value())
+	case "gtfield":
+		return fmt.Sprintf("必须大于 %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("必须是 [%s] 之一，当前为 %v", fe.Param(), fe.Value())
+	case "url":
+		return fmt.Sprintf("不是合法的 URL: %v", fe.Value())
+	case "email":
+		return fmt.Sprintf("不是合法的邮箱: %v", fe.Value())
+	default:
+		return fmt.Sprintf("校验失败（%s）", fe.Tag())
 	}
 }
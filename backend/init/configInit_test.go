@@ -0,0 +1,51 @@
+package inits
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// TestWatchConfigStop 停止监听后修改配置文件不再触发回调
+func TestWatchConfigStop(t *testing.T) {
+	globals.Log = zap.NewNop().Sugar()
+	file := filepath.Join(t.TempDir(), "test.yaml")
+	if err := os.WriteFile(file, []byte("log:\n  level: info\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile(file)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	var called atomic.Int32
+	stop, err := WatchConfig(func(*globals.Config) { called.Add(1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stop(); err != nil {
+		t.Fatalf("重复停止: %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte("log:\n  level: debug\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if called.Load() != 0 {
+		t.Fatal("停止监听后仍触发了回调")
+	}
+	if got := viper.GetString("log.level"); got != "info" {
+		t.Fatalf("停止监听后仍重新读取了配置: %s", got)
+	}
+}
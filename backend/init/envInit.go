@@ -2,9 +2,14 @@ package inits
 
 import (
 	"blueLock/backend/internal/pkg/globals"
+	"os"
 )
 
+// EnvInit 选择环境：命令行 -env 优先（main 中解析后写入 globals.Env），其次环境变量 BLUELOCK_ENV，默认 dev
 func EnvInit() {
+	if len(globals.Env) == 0 {
+		globals.Env = os.Getenv(envPrefix + "_ENV")
+	}
 	// 项目配置环境 本地 local.yaml
 	if len(globals.Env) == 0 {
 		globals.Env = "dev"
@@ -0,0 +1,20 @@
+package inits
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+)
+
+// i18nInit 校验语言包完整性。语言包随二进制一起编译，完整性由 i18n 包的单元测试保证；
+// 这里只记录警告，缺失的文案运行时回退到默认语言，不影响启动
+func i18nInit() {
+	// 字段错误没有对应文案时使用 validation.invalid
+	required := []string{"validation.invalid"}
+	for _, key := range apperr.Keys() {
+		required = append(required, "error."+key)
+	}
+	if err := i18n.Validate(required...); err != nil {
+		globals.Log.Warnf("多语言文案不完整，缺失部分将使用默认语言: %v", err)
+	}
+}
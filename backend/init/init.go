@@ -1,6 +1,7 @@
 package inits
 
-func Init() {
+// Base 初始化配置、日志和数据库连接，migrate 等命令只需要这部分
+func Base() {
 	// 选择环境
 	EnvInit()
 	// 根据环境初始化配置文件
@@ -9,10 +10,27 @@ func Init() {
 	LogInit()
 	// mysql初始化
 	DBInit()
-	// 表结构初始化
-	TableInit()
-	// redis初始化
-	RedisInit()
-	// jwt 初始化
-	jwtInit()
+}
+
+// Init 启动服务需要的全部初始化
+func Init() {
+	Base()
+	// 表结构检查，迁移需通过 migrate 命令显式执行
+	SchemaCheck()
+	// 多语言
+	i18nInit()
+	// 请求参数校验规则
+	validatorInit()
+	// 审计日志写入器
+	auditInit()
+	// 内置角色与初始管理员
+	AdminInit()
+	// 键值存储初始化（redis 或进程内）
+	KVInit()
+	// 连接池指标
+	MetricsInit()
+	// 链路追踪
+	TracingInit()
+	// 第三方登录初始化
+	oauthInit()
 }
@@ -0,0 +1,21 @@
+package inits
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/kv"
+)
+
+// KVInit 按 cache.driver 初始化键值存储，默认使用 Redis
+func KVInit() {
+	switch globals.AppConfig.Cache.Driver {
+	case "", kv.BackendRedis:
+		RedisInit()
+		globals.KV = kv.NewRedisStore(globals.RDB, globals.AppConfig.Redis.KeyPrefix)
+	case kv.BackendMemory:
+		globals.KV = kv.NewMemoryStore(globals.AppConfig.Cache.CleanupInterval)
+		globals.Log.Warn("使用进程内键值存储：验证码、刷新令牌等数据不会在实例间共享，重启后丢失，只适合单机部署")
+	default:
+		globals.Log.Fatalf("不支持的 cache.driver: %s", globals.AppConfig.Cache.Driver)
+	}
+	globals.Log.Infof("键值存储后端: %s", globals.KV.Backend())
+}
@@ -3,40 +3,58 @@ package inits
 import (
 	"blueLock/backend/internal/pkg/globals"
 	"blueLock/backend/internal/pkg/logger"
-	"fmt"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"log"
 	"os"
 )
 
 // LogInit 日志初始化
 func LogInit() {
-	if err := viper.UnmarshalKey("log", &globals.AppConfig.Log); err != nil {
-		fmt.Printf("无法解码结构: %v\n", err.Error())
+	cfg := globals.AppConfig.Log
+	// 获取日志输出目标（按大小和日期轮转的文件）
+	writeSyncer, err := logger.GetLogWriter(cfg)
+	if err != nil {
+		log.Fatalf("日志初始化失败: %v", err)
 	}
-
-	logPath := globals.AppConfig.Log.LogPath
-	appName := globals.AppConfig.Log.AppName
-	// 获取日志输出目标（文件）
-	writeSyncer := logger.GetLogWriter(logPath, appName)
+	globals.LogFile = writeSyncer
 	// 创建日志编码器（通常是 JSON 格式）
 	encoder := logger.GetEncoder()
 
-	// 将日志输出到文件
-	fileCore := zapcore.NewCore(encoder, writeSyncer, zapcore.DebugLevel)
+	// 将日志输出到文件，级别由 log.file_level 决定（默认 debug），可热更新
+	globals.FileLogLevel = zap.NewAtomicLevelAt(ParseFileLogLevel(cfg.FileLevel))
+	fileCore := zapcore.NewCore(encoder, writeSyncer, globals.FileLogLevel)
 	// 将日志输出到控制台
 	consoleWriteSyncer := zapcore.AddSync(
 		os.Stdout,
 	) // 输出到控制台（os.Stdout）
+	globals.LogLevel = zap.NewAtomicLevelAt(ParseLogLevel(cfg.Level))
 	consoleCore := zapcore.NewCore(
 		encoder,
 		consoleWriteSyncer,
-		zapcore.InfoLevel,
-	) // 控制台输出设置为InfoLevel
+		globals.LogLevel,
+	) // 控制台输出级别由 log.level 决定（默认 info），可热更新
+
+	// 合并控制台输出和文件输出，写入前统一脱敏
+	core := logger.NewRedactCore(zapcore.NewTee(fileCore, consoleCore))
+	zl := zap.New(core, zap.AddCaller())
+	globals.Log = zl.Sugar()
+}
 
-	// 合并控制台输出和文件输出
-	core := zapcore.NewTee(fileCore, consoleCore)
-	log := zap.New(core, zap.AddCaller())
-	globals.Log = log.Sugar()
+// ParseLogLevel 解析日志级别，为空或无法识别时使用 info
+func ParseLogLevel(level string) zapcore.Level {
+	l, err := zapcore.ParseLevel(level)
+	if err != nil || level == "" {
+		return zapcore.InfoLevel
+	}
+	return l
+}
+
+// ParseFileLogLevel 解析文件日志级别，为空或无法识别时使用 debug
+func ParseFileLogLevel(level string) zapcore.Level {
+	l, err := zapcore.ParseLevel(level)
+	if err != nil || level == "" {
+		return zapcore.DebugLevel
+	}
+	return l
 }
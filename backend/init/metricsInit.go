@@ -0,0 +1,23 @@
+package inits
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/metrics"
+)
+
+// MetricsInit 注册数据库和 Redis 连接池指标，需在 DBInit、KVInit 之后调用
+func MetricsInit() {
+	sqlDB, err := globals.DB.DB()
+	if err != nil {
+		globals.Log.Fatalf("获取数据库连接池失败: %v", err)
+	}
+	if err := metrics.RegisterDB(sqlDB, globals.DB.Dialector.Name()); err != nil {
+		globals.Log.Fatalf("注册数据库指标失败: %v", err)
+	}
+	// 使用进程内键值存储时没有 Redis 连接
+	if globals.RDB != nil {
+		if err := metrics.RegisterRedis(globals.RDB); err != nil {
+			globals.Log.Fatalf("注册 Redis 指标失败: %v", err)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package inits
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"time"
+)
+
+func oauthInit() {
+	if globals.AppConfig.OAuth.StateExpiry == 0 {
+		globals.AppConfig.OAuth.StateExpiry = 10 * time.Minute
+	}
+}
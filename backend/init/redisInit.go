@@ -5,46 +5,91 @@ import (
 	"blueLock/backend/internal/pkg/globals"
 	"context"
 	"fmt"
+	"strings"
+
 	"github.com/go-redis/redis/v8"
+)
 
-	"github.com/spf13/viper"
+// Redis 部署模式
+const (
+	redisModeSingle   = "single"
+	redisModeSentinel = "sentinel"
+	redisModeCluster  = "cluster"
 )
 
-// RedisInit 初始化Redis
+// RedisInit 初始化Redis，按 redis.mode 创建单节点、哨兵或集群客户端
 func RedisInit() {
-	if err := viper.UnmarshalKey("redis", &globals.AppConfig.Redis); err != nil {
-		globals.Log.Panicf("无法解码为结构: %s", err)
-	}
-
-	// 验证配置值
-	if globals.AppConfig.Redis.Host == "" {
-		globals.Log.Panicf("Redis配置错误: Host 不能为空")
-	}
-	if globals.AppConfig.Redis.Port == 0 {
-		globals.Log.Panicf("Redis配置错误: Port 不能为0")
+	client, err := NewRedisClient(globals.AppConfig.Redis)
+	if err != nil {
+		globals.Log.Panicf("Redis配置错误: %v", err)
 	}
+	globals.RDB = client
 
-	globals.RDB = redis.NewClient(&redis.Options{
-		Addr: fmt.Sprintf(
-			"%s:%d",
-			globals.AppConfig.Redis.Host,
-			globals.AppConfig.Redis.Port,
-		),
-		Password:     globals.AppConfig.Redis.Password,
-		DB:           globals.AppConfig.Redis.DB,
-		PoolSize:     globals.AppConfig.Redis.PoolSize,
-		MinIdleConns: globals.AppConfig.Redis.MinIdleConns,
-		IdleTimeout:  globals.AppConfig.Redis.IdleTimeout,
-		DialTimeout:  globals.AppConfig.Redis.DialTimeout,
-		ReadTimeout:  globals.AppConfig.Redis.ReadTimeout,
-		WriteTimeout: globals.AppConfig.Redis.WriteTimeout,
-		MaxRetries:   globals.AppConfig.Redis.MaxRetries,
-	})
 	ctx := context.Background()
-	_, err := globals.RDB.Ping(ctx).Result()
+	_, err = globals.RDB.Ping(ctx).Result()
 	if err != nil {
 		globals.Log.Panicf("Redis连接失败: %v", err)
 	} else {
-		globals.Log.Infof("Redis连接成功")
+		globals.Log.Infof("Redis连接成功，模式: %s", redisMode(globals.AppConfig.Redis))
+	}
+}
+
+// NewRedisClient 根据配置创建客户端并校验配置，不做连通性检查
+func NewRedisClient(cfg globals.RedisConfig) (redis.UniversalClient, error) {
+	if strings.ContainsAny(cfg.KeyPrefix, "{}") {
+		// 前缀里出现花括号会改变集群模式下的哈希标签
+		return nil, fmt.Errorf("key_prefix 不能包含花括号: %q", cfg.KeyPrefix)
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		MasterName:       cfg.MasterName,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		IdleTimeout:      cfg.IdleTimeout,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		MaxRetries:       cfg.MaxRetries,
+	}
+
+	switch redisMode(cfg) {
+	case redisModeSingle:
+		if len(opts.Addrs) == 0 {
+			// 验证配置值
+			if cfg.Host == "" {
+				return nil, fmt.Errorf("Host 不能为空")
+			}
+			if cfg.Port == 0 {
+				return nil, fmt.Errorf("Port 不能为0")
+			}
+			opts.Addrs = []string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)}
+		}
+		return redis.NewClient(opts.Simple()), nil
+	case redisModeSentinel:
+		if cfg.MasterName == "" || len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("sentinel 模式需要配置 master_name 和 addrs")
+		}
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case redisModeCluster:
+		if len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("cluster 模式需要配置 addrs")
+		}
+		if cfg.DB != 0 {
+			return nil, fmt.Errorf("cluster 模式只支持 db 0")
+		}
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("不支持的 mode: %s", cfg.Mode)
+	}
+}
+
+func redisMode(cfg globals.RedisConfig) string {
+	if cfg.Mode == "" {
+		return redisModeSingle
 	}
+	return strings.ToLower(cfg.Mode)
 }
@@ -0,0 +1,24 @@
+package inits
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/tracing"
+)
+
+// TracingInit 初始化链路追踪，并为数据库和 Redis 注册埋点，需在 DBInit、KVInit 之后调用
+func TracingInit() {
+	cfg := globals.AppConfig.Tracing
+	if err := tracing.Init(cfg); err != nil {
+		globals.Log.Fatalf("链路追踪初始化失败: %v", err)
+	}
+	if err := globals.DB.Use(tracing.GormPlugin()); err != nil {
+		globals.Log.Fatalf("注册 gorm 链路追踪插件失败: %v", err)
+	}
+	// 使用进程内键值存储时没有 Redis 连接
+	if globals.RDB != nil {
+		globals.RDB.AddHook(tracing.RedisHook())
+	}
+	if cfg.Exporter != "" && cfg.Exporter != tracing.ExporterNone {
+		globals.Log.Infof("链路追踪已启用，导出器: %s", cfg.Exporter)
+	}
+}
@@ -0,0 +1,13 @@
+package inits
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/validate"
+)
+
+// validatorInit 注册请求参数的自定义校验规则（email_addr 等）
+func validatorInit() {
+	if err := validate.Setup(); err != nil {
+		globals.Log.Fatalf("参数校验器初始化失败: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package app 应用容器：在启动时一次性构造配置、日志、数据库连接和各层服务，再注入到处理器中
+package app
+
+import (
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/middleware"
+	"blueLock/backend/internal/pkg/challenge"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/health"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/pkg/kv"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/pkg/mail"
+	"blueLock/backend/internal/pkg/notify"
+	"blueLock/backend/internal/pkg/password"
+	"blueLock/backend/internal/pkg/token"
+	"blueLock/backend/internal/repository"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// App 应用依赖的集合
+type App struct {
+	Config    *globals.Config
+	Log       *zap.SugaredLogger
+	LogLevels logger.Levels
+	DB        *gorm.DB
+	KV        kv.Store
+
+	TokenService *token.Service
+	Passwords    *password.Policy
+	Hasher       *password.Hasher
+	Cors         *middleware.CorsPolicy
+	Health       *health.Checker
+	Mailer       *notify.Mailer
+	Notifier     *notify.Notifier
+	Challenge    *challenge.Gate
+
+	UserRepo     repository.UserStore
+	TokenRepo    repository.TokenStore
+	CodeRepo     repository.CodeStore
+	SecurityRepo *repository.SecurityRepository
+	MagicRepo    *repository.MagicLinkRepository
+	IdentityRepo *repository.IdentityRepository
+	RoleRepo     *repository.RoleRepository
+	DeviceRepo   *repository.DeviceRepository
+	AuditRepo    *repository.AuditRepository
+
+	Login  *logic.LoginLogic
+	OAuth  *logic.OAuthLogic
+	Magic  *logic.MagicLinkLogic
+	Admin  *logic.AdminLogic
+	Device *logic.DeviceLogic
+	Audit  *logic.AuditLogic
+}
+
+// New 根据已初始化好的基础设施构造应用容器
+func New(cfg *globals.Config, log *zap.SugaredLogger, levels logger.Levels, db *gorm.DB, store kv.Store) *App {
+	a := &App{
+		Config:    cfg,
+		Log:       log,
+		LogLevels: levels,
+		DB:        db,
+		KV:        store,
+	}
+
+	a.TokenService = token.NewService(token.Config{
+		SecretKey:          cfg.JWT.SecretKey,
+		AccessTokenExpiry:  cfg.JWT.AccessTokenExpiry,
+		RefreshTokenExpiry: cfg.JWT.RefreshTokenExpiry,
+	})
+
+	a.Passwords = password.NewPolicy(cfg.Password)
+	a.Hasher = password.NewHasher(cfg.Password.Hash)
+	a.Cors = middleware.NewCorsPolicy(cfg.Cors.AllowedOrigins)
+	a.Health = newHealthChecker(cfg, db, store)
+	a.Mailer = notify.NewMailer(cfg.Mail)
+	a.Notifier = notify.NewNotifier(a.Mailer, cfg.Notify, log)
+	a.Challenge = challenge.NewGate(store, cfg.Challenge)
+
+	a.UserRepo = repository.NewLoginRepository(db)
+	a.TokenRepo = repository.NewTokenRepository(store)
+	a.CodeRepo = repository.NewCodeRepository(store)
+	a.SecurityRepo = repository.NewSecurityRepository(store)
+	a.MagicRepo = repository.NewMagicLinkRepository(store)
+	a.IdentityRepo = repository.NewIdentityRepository(db)
+	a.RoleRepo = repository.NewRoleRepository(db)
+	a.DeviceRepo = repository.NewDeviceRepository(db)
+	a.AuditRepo = repository.NewAuditRepository(db)
+
+	// 未配置撤销邮箱修改的页面时，按域名推导默认地址
+	notifyCfg := cfg.Notify
+	if notifyCfg.EmailRevertURL == "" && cfg.App.Domain != "" {
+		notifyCfg.EmailRevertURL = "https://" + cfg.App.Domain + "/account/email-revert"
+	}
+	a.Login = logic.NewLoginLogic(a.UserRepo, a.TokenService, a.TokenRepo, a.CodeRepo, a.SecurityRepo,
+		a.Passwords, a.Hasher, a.Mailer, a.Notifier, cfg.JWT, notifyCfg, log)
+	a.OAuth = logic.NewOAuthLogic(a.UserRepo, a.IdentityRepo, a.Login, store, cfg.OAuth)
+	magicCfg := cfg.MagicLink
+	if magicCfg.URL == "" && cfg.App.Domain != "" {
+		magicCfg.URL = "https://" + cfg.App.Domain + "/login/magic"
+	}
+	a.Magic = logic.NewMagicLinkLogic(a.UserRepo, a.MagicRepo, a.Login, a.TokenService, a.Notifier, magicCfg, log)
+	a.Admin = logic.NewAdminLogic(a.UserRepo, a.TokenRepo, a.DeviceRepo, cfg.JWT)
+	a.Device = logic.NewDeviceLogic(a.DeviceRepo)
+	a.Audit = logic.NewAuditLogic(a.AuditRepo)
+
+	// 响应语言在没有 Accept-Language 时使用已登录用户保存的偏好
+	i18n.SetUserLocaleResolver(func(ctx context.Context, userID uint) string {
+		user, err := a.UserRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return ""
+		}
+		return user.Locale
+	})
+	return a
+}
+
+// mailHealthCacheFor 邮件服务的检查结果缓存时间。每次检查都要建立一次 SMTP 会话，
+// 不缓存时负载均衡的每次探测都会连接邮件服务器，可能触发对方的频率限制
+const mailHealthCacheFor = time.Minute
+
+// newHealthChecker 就绪检查的组件：数据库和键值存储不可用时不接收流量，邮件服务只影响发信，不可用时标记为 degraded
+func newHealthChecker(cfg *globals.Config, db *gorm.DB, store kv.Store) *health.Checker {
+	components := []health.Component{
+		{
+			Name:     "database",
+			Critical: true,
+			Detail:   db.Dialector.Name(),
+			Check: func(ctx context.Context) error {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
+			},
+		},
+		{
+			Name:     "kv",
+			Critical: true,
+			Detail:   store.Backend(),
+			Check:    store.Ping,
+		},
+	}
+	if cfg.Mail.SMTPHost != "" {
+		components = append(components, health.Component{
+			Name:     "mail",
+			Detail:   cfg.Mail.SMTPHost,
+			CacheFor: mailHealthCacheFor,
+			Check: func(ctx context.Context) error {
+				return mail.Ping(ctx, cfg.Mail)
+			},
+		})
+	}
+	return health.NewChecker(cfg.App.HealthTimeout, components...)
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/audit"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/request"
+	"blueLock/backend/internal/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// AdminHandler 管理后台接口
+type AdminHandler struct {
+	admin *logic.AdminLogic
+	audit *logic.AuditLogic
+}
+
+// NewAdminHandler 创建AdminHandler
+func NewAdminHandler(admin *logic.AdminLogic, audit *logic.AuditLogic) *AdminHandler {
+	return &AdminHandler{admin: admin, audit: audit}
+}
+
+// ListUsers 查询、搜索用户列表
+func (h *AdminHandler) ListUsers(ctx *gin.Context) {
+	var req request.AdminListUsersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	data, err := h.admin.ListUsers(ctx, &req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// GetUser 查询单个用户
+func (h *AdminHandler) GetUser(ctx *gin.Context) {
+	var uri request.AdminUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	data, err := h.admin.GetUser(ctx, uri.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// DisableUser 禁用账号
+func (h *AdminHandler) DisableUser(ctx *gin.Context) {
+	h.setDisabled(ctx, true)
+}
+
+// EnableUser 启用账号
+func (h *AdminHandler) EnableUser(ctx *gin.Context) {
+	h.setDisabled(ctx, false)
+}
+
+// setDisabled 禁用(disabled=true)或启用(disabled=false)账号
+func (h *AdminHandler) setDisabled(ctx *gin.Context, disabled bool) {
+	var uri request.AdminUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	entry := audit.FromGin(ctx, audit.ActionUserEnable)
+	if disabled {
+		entry.Action = audit.ActionUserDisable
+	}
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = audit.FormatID(uri.ID)
+	if err := h.admin.SetDisabled(ctx, uri.ID, disabled); err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	msg := "msg.account_enabled"
+	if disabled {
+		msg = "msg.account_disabled"
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), msg),
+	})
+}
+
+// ForceLogout 强制用户下线
+func (h *AdminHandler) ForceLogout(ctx *gin.Context) {
+	var uri request.AdminUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	entry := audit.FromGin(ctx, audit.ActionForceLogout)
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = audit.FormatID(uri.ID)
+	if err := h.admin.ForceLogout(ctx, uri.ID); err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), "msg.force_logout"),
+	})
+}
+
+// ListDevices 查询用户绑定的设备
+func (h *AdminHandler) ListDevices(ctx *gin.Context) {
+	var uri request.AdminUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	devices, err := h.admin.ListDevices(ctx, uri.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: devices,
+	})
+}
+
+// AuditQuery 查询审计日志
+func (h *AdminHandler) AuditQuery(ctx *gin.Context) {
+	var req request.AdminAuditQueryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	data, err := h.audit.Query(ctx, &req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// AuditVerify 校验审计日志哈希链
+func (h *AdminHandler) AuditVerify(ctx *gin.Context) {
+	data, err := h.audit.Verify(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
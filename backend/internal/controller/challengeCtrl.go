@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/challenge"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/request"
+	"blueLock/backend/internal/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ChallengeHandler 人机校验题目接口
+type ChallengeHandler struct {
+	gate *challenge.Gate
+}
+
+// NewChallengeHandler 创建ChallengeHandler
+func NewChallengeHandler(gate *challenge.Gate) *ChallengeHandler {
+	return &ChallengeHandler{gate: gate}
+}
+
+// Types 列出可用的校验方式
+func (h *ChallengeHandler) Types(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: h.gate.Types(),
+	})
+}
+
+// Issue 获取一道题目，解答后在受保护接口的请求头中提交题目 ID 和解答
+func (h *ChallengeHandler) Issue(ctx *gin.Context) {
+	var uri request.ChallengeURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	c, err := h.gate.Issue(ctx, uri.Type, ctx.ClientIP())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: c,
+	})
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// DeviceGatewayHandler 设备通过 mTLS 调用的网关接口
+type DeviceGatewayHandler struct {
+	device *logic.DeviceLogic
+}
+
+// NewDeviceGatewayHandler 创建DeviceGatewayHandler
+func NewDeviceGatewayHandler(device *logic.DeviceLogic) *DeviceGatewayHandler {
+	return &DeviceGatewayHandler{device: device}
+}
+
+// Heartbeat 设备上报在线，返回设备信息
+func (h *DeviceGatewayHandler) Heartbeat(ctx *gin.Context) {
+	value, exists := ctx.Get("device")
+	if !exists {
+		ctx.Error(apperr.ErrClientCert)
+		return
+	}
+	device, err := h.device.Heartbeat(ctx, value.(*models.Device))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: device,
+	})
+}
@@ -2,161 +2,183 @@ package controller
 
 import (
 	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/audit"
 	"blueLock/backend/internal/pkg/globals"
-	"blueLock/backend/internal/pkg/token"
-	"blueLock/backend/internal/repository"
+	"blueLock/backend/internal/pkg/i18n"
 	"blueLock/backend/internal/request"
 	"blueLock/backend/internal/response"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
-func buildLoginLogic() *logic.LoginLogic {
-	repo := repository.NewLoginRepository(globals.DB)
-	tokenRepo := repository.NewTokenRepository(globals.DB, globals.RDB)
-	tokenService := token.NewService(token.Config{
-		SecretKey:          globals.AppConfig.JWT.SecretKey,
-		AccessTokenExpiry:  globals.AppConfig.JWT.AccessTokenExpiry,
-		RefreshTokenExpiry: globals.AppConfig.JWT.RefreshTokenExpiry,
-	})
-	return logic.NewLoginLogic(repo, tokenService, tokenRepo)
+// AuthHandler 邮箱注册、登录相关接口
+type AuthHandler struct {
+	login *logic.LoginLogic
+}
+
+// NewAuthHandler 创建AuthHandler
+func NewAuthHandler(login *logic.LoginLogic) *AuthHandler {
+	return &AuthHandler{login: login}
 }
 
 // SendVerificationCode 发送验证码处理器
-func SendVerificationCode() func(ctx *gin.Context) {
-	return func(ctx *gin.Context) {
-		loginLogic := buildLoginLogic()
-		var req request.SendVerificationCodeRequest
-		if err := ctx.ShouldBind(&req); err != nil {
-			ctx.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Code:  globals.StatusBadRequest,
-				Error: fmt.Sprintf("参数绑定错误 err: %s", err),
-			})
-		}
-		// 调用logic层代码
-		err := loginLogic.SendVerificationCode(ctx, req.Email)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{
-				Code:  globals.StatusInternalServerError,
-				Error: fmt.Sprintf("验证码发送失败：%s", err),
-			})
-			return
-		}
-		// 返回信息
-		ctx.JSON(http.StatusOK, response.Success{
-			Code: globals.StatusOK,
-			Data: "验证码发送成功",
-		})
+func (h *AuthHandler) SendVerificationCode(ctx *gin.Context) {
+	var req request.SendVerificationCodeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
 	}
+	// 调用logic层代码
+	err := h.login.SendVerificationCode(ctx, req.Email, i18n.Requested(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	// 返回信息
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), "msg.code_sent"),
+	})
 }
 
-// RegisterHandler 注册
-func RegisterHandler() func(ctx *gin.Context) {
-	return func(ctx *gin.Context) {
-		regisLogic := buildLoginLogic()
-		var req request.RegisterByVerificationCodeRequest
-		if err := ctx.ShouldBind(&req); err != nil {
-			ctx.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Code:  globals.StatusBadRequest,
-				Error: fmt.Sprintf("参数绑定错误 err: %s", err),
-			})
-			return
-		}
-		user, err := regisLogic.RegisterEmail(ctx, &req)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{
-				Code:  globals.StatusInternalServerError,
-				Error: fmt.Sprintf("注册账号出现错误 err: %s", err),
-			})
-			return
-		}
-		ctx.JSON(http.StatusOK, response.Success{
-			Code: globals.StatusOK,
-			Data: user,
-		})
+// Register 注册
+func (h *AuthHandler) Register(ctx *gin.Context) {
+	var req request.RegisterByVerificationCodeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	if req.Locale == "" {
+		req.Locale = i18n.FromGin(ctx)
 	}
+	user, err := h.login.RegisterEmail(ctx, &req)
+	entry := audit.FromGin(ctx, audit.ActionRegister)
+	entry.TargetType = audit.TargetUser
+	entry.Details = map[string]any{"email": req.Email}
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details["reason"] = audit.Reason(err)
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	entry.ActorID = user.ID
+	entry.TargetID = audit.FormatID(user.ID)
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: user,
+	})
 }
 
-// LoginHandler 登录
-func LoginHandler() func(ctx *gin.Context) {
-	return func(ctx *gin.Context) {
-		loginLogic := buildLoginLogic()
-		var req request.LoginByPassORCode
-		if err := ctx.ShouldBind(&req); err != nil {
-			ctx.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Code:  globals.StatusBadRequest,
-				Error: fmt.Sprintf("参数绑定错误 err: %s", err),
-			})
-			return
-		}
-		respData, err := loginLogic.LoginByPass(ctx, &req)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{
-				Code:  globals.StatusInternalServerError,
-				Error: fmt.Sprintf("登录账号出现错误 err: %s", err),
-			})
-			return
-		}
-		ctx.JSON(http.StatusOK, response.Success{
-			Code: globals.StatusOK,
-			Data: respData,
-		})
+// Login 登录
+func (h *AuthHandler) Login(ctx *gin.Context) {
+	var req request.LoginByPassORCode
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	respData, err := h.login.LoginByPass(ctx, &req)
+	method := "password"
+	if req.Password == "" {
+		method = "code"
 	}
+	entry := audit.FromGin(ctx, audit.ActionLogin)
+	entry.TargetType = audit.TargetUser
+	entry.Details = map[string]any{"email": req.Email, "method": method}
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details["reason"] = audit.Reason(err)
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	entry.ActorID = respData.UserID
+	entry.TargetID = audit.FormatID(respData.UserID)
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: respData,
+	})
 }
 
 // RefreshToken 刷新令牌
-func RefreshToken() func(ctx *gin.Context) {
-	return func(ctx *gin.Context) {
-		loginLogic := buildLoginLogic()
-		var req request.RefreshTokenRequest
-		if err := ctx.ShouldBindJSON(&req); err != nil {
-			ctx.JSON(http.StatusBadRequest, response.ErrorResponse{
-				Code:  globals.StatusBadRequest,
-				Error: fmt.Sprintf("参数绑定错误 err: %s", err),
-			})
-			return
-		}
+func (h *AuthHandler) RefreshToken(ctx *gin.Context) {
+	var req request.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+
+	respData, err := h.login.RefreshToken(ctx, req.RefreshToken)
+	entry := audit.FromGin(ctx, audit.ActionTokenRefresh)
+	entry.TargetType = audit.TargetUser
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	entry.ActorID = respData.UserID
+	entry.TargetID = audit.FormatID(respData.UserID)
+	audit.Record(entry)
 
-		respData, err := loginLogic.RefreshToken(ctx, req.RefreshToken)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{
-				Code:  globals.StatusUnauthorized,
-				Error: err.Error(),
-			})
-			return
-		}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: respData,
+	})
+}
 
-		ctx.JSON(http.StatusOK, response.Success{
-			Code: globals.StatusOK,
-			Data: respData,
-		})
+// Logout 登出处理器
+func (h *AuthHandler) Logout(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
 	}
+	entry := audit.FromGin(ctx, audit.ActionLogout)
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = audit.FormatID(entry.ActorID)
+	if err := h.login.Logout(ctx, uint(userID.(uint64))); err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), "msg.logout_success"),
+	})
 }
 
-// LogoutHandler 登出处理器
-func LogoutHandler() func(ctx *gin.Context) {
-	return func(ctx *gin.Context) {
-		logoutLoginLogic := buildLoginLogic()
-		userID, exists := ctx.Get("user_id")
-		if !exists {
-			ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{
-				Code:    globals.StatusUnauthorized,
-				Message: "未登录",
-			})
-			return
-		}
-		if err := logoutLoginLogic.Logout(ctx, uint(userID.(uint64))); err != nil {
-			ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{
-				Code:    globals.StatusInternalServerError,
-				Message: "登出失败",
-				Error:   fmt.Sprintf("登出出现错误: %s", err),
-			})
-			return
-		}
-		ctx.JSON(http.StatusOK, response.Success{
-			Code: globals.StatusOK,
-			Data: "登出成功",
-		})
+// RevertEmail 撤销邮箱修改，无需登录，凭发往旧邮箱的链接中的令牌操作
+func (h *AuthHandler) RevertEmail(ctx *gin.Context) {
+	var req request.RevertEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	userID, err := h.login.RevertEmail(ctx, req.Token)
+	entry := audit.FromGin(ctx, audit.ActionEmailRevert)
+	entry.TargetType = audit.TargetUser
+	if userID != 0 {
+		entry.ActorID = userID
+		entry.TargetID = audit.FormatID(userID)
 	}
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), "msg.email_reverted"),
+	})
 }
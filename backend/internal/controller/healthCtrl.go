@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"blueLock/backend/internal/pkg/health"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// HealthHandler 存活和就绪检查接口，返回体不经过统一的 response 包装，便于编排系统直接判断
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler 创建HealthHandler
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// Healthz 存活检查，不探测依赖，进程能响应即返回 200
+func (h *HealthHandler) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.checker.Liveness())
+}
+
+// Readyz 就绪检查，关键依赖不可用或服务正在关闭时返回 503
+func (h *HealthHandler) Readyz(ctx *gin.Context) {
+	report, ok := h.checker.Readiness(ctx.Request.Context())
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
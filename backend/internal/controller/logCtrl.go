@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/audit"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/request"
+	"blueLock/backend/internal/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+)
+
+// LogLevelHandler 运行时查看、修改日志级别，修改只在本进程生效，重启或配置文件热更新后以配置为准
+type LogLevelHandler struct {
+	levels logger.Levels
+}
+
+// NewLogLevelHandler 创建LogLevelHandler
+func NewLogLevelHandler(levels logger.Levels) *LogLevelHandler {
+	return &LogLevelHandler{levels: levels}
+}
+
+// GetLevels 查看各输出当前的日志级别
+func (h *LogLevelHandler) GetLevels(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: h.current(),
+	})
+}
+
+// SetLevel 修改某个输出的日志级别
+func (h *LogLevelHandler) SetLevel(ctx *gin.Context) {
+	var req request.AdminLogLevelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	atomic, ok := h.levels.Get(req.Output)
+	if !ok {
+		ctx.Error(apperr.ErrBadRequest)
+		return
+	}
+	old := atomic.Level()
+	atomic.SetLevel(level)
+
+	entry := audit.FromGin(ctx, audit.ActionLogLevelChange)
+	entry.Details = map[string]any{"output": req.Output, "from": old.String(), "to": level.String()}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: h.current(),
+	})
+}
+
+func (h *LogLevelHandler) current() map[string]string {
+	return map[string]string{
+		"console": h.levels.Console.Level().String(),
+		"file":    h.levels.File.Level().String(),
+	}
+}
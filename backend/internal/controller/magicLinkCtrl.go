@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/audit"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/request"
+	"blueLock/backend/internal/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// MagicLinkHandler 邮件链接登录接口
+type MagicLinkHandler struct {
+	magic *logic.MagicLinkLogic
+}
+
+// NewMagicLinkHandler 创建MagicLinkHandler
+func NewMagicLinkHandler(magic *logic.MagicLinkLogic) *MagicLinkHandler {
+	return &MagicLinkHandler{magic: magic}
+}
+
+// Start 发起邮件链接登录
+func (h *MagicLinkHandler) Start(ctx *gin.Context) {
+	var req request.MagicLinkStartRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	locale := req.Locale
+	if locale == "" {
+		locale = i18n.Requested(ctx)
+	}
+	data, err := h.magic.Start(ctx, req.Email, locale)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// Verify 打开邮件链接，在发起登录的设备上直接登录，在其他设备上返回待批准的请求信息
+func (h *MagicLinkHandler) Verify(ctx *gin.Context) {
+	var req request.MagicLinkVerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	data, err := h.magic.Verify(ctx, req.Token, req.Nonce)
+	if err != nil {
+		entry := audit.FromGin(ctx, audit.ActionMagicLinkLogin)
+		entry.TargetType = audit.TargetUser
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	if data.Login != nil {
+		recordMagicLinkLogin(ctx, data.Login.UserID)
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// Approve 在其他设备上批准登录
+func (h *MagicLinkHandler) Approve(ctx *gin.Context) {
+	var req request.MagicLinkApproveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	data, err := h.magic.Approve(ctx, req.Token)
+	entry := audit.FromGin(ctx, audit.ActionMagicApprove)
+	entry.TargetType = audit.TargetUser
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// Poll 发起方轮询登录结果，wait 大于 0 时在批准前最多等待 wait 秒
+func (h *MagicLinkHandler) Poll(ctx *gin.Context) {
+	var req request.MagicLinkPollRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	data, err := h.magic.Poll(ctx, req.RequestID, req.Nonce, time.Duration(req.Wait)*time.Second)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if data.Login != nil {
+		recordMagicLinkLogin(ctx, data.Login.UserID)
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+func recordMagicLinkLogin(ctx *gin.Context, userID uint) {
+	entry := audit.FromGin(ctx, audit.ActionMagicLinkLogin)
+	entry.TargetType = audit.TargetUser
+	entry.ActorID = userID
+	entry.TargetID = audit.FormatID(userID)
+	audit.Record(entry)
+}
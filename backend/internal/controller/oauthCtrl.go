@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/audit"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// oauthStateCookie 保存发起授权时的 state，回调时与查询参数比对，把授权流程绑定到发起它的浏览器
+const oauthStateCookie = "oauth_state"
+
+// OAuthHandler 第三方登录相关接口
+type OAuthHandler struct {
+	oauth *logic.OAuthLogic
+}
+
+// NewOAuthHandler 创建OAuthHandler
+func NewOAuthHandler(oauth *logic.OAuthLogic) *OAuthHandler {
+	return &OAuthHandler{oauth: oauth}
+}
+
+// Providers 查询可用的第三方登录方式
+func (h *OAuthHandler) Providers(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: h.oauth.Providers(),
+	})
+}
+
+// Authorize 获取第三方登录授权地址
+func (h *OAuthHandler) Authorize(ctx *gin.Context) {
+	data, err := h.oauth.Authorize(ctx, ctx.Param("provider"), 0)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	setOAuthStateCookie(ctx, data.State)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// Callback 第三方授权回调，完成登录或绑定
+func (h *OAuthHandler) Callback(ctx *gin.Context) {
+	// 用户在提供方页面拒绝授权等情况
+	if errCode := ctx.Query("error"); errCode != "" {
+		ctx.Error(apperr.ErrOAuthFailed.Wrap(fmt.Errorf("%s: %s", errCode, ctx.Query("error_description"))))
+		return
+	}
+	browserState, _ := ctx.Cookie(oauthStateCookie)
+	setOAuthStateCookie(ctx, "")
+	data, err := h.oauth.Callback(ctx, ctx.Param("provider"), ctx.Query("code"), ctx.Query("state"), browserState)
+	entry := audit.FromGin(ctx, audit.ActionOAuthLogin)
+	entry.TargetType = audit.TargetUser
+	entry.Details = map[string]any{"provider": ctx.Param("provider")}
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details["reason"] = audit.Reason(err)
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	if data.Action == logic.OAuthActionLink {
+		entry.Action = audit.ActionOAuthLink
+	}
+	entry.ActorID = data.UserID
+	entry.TargetID = audit.FormatID(data.UserID)
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// Link 为当前用户生成绑定第三方账号的授权地址
+func (h *OAuthHandler) Link(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	data, err := h.oauth.Authorize(ctx, ctx.Param("provider"), uint(userID.(uint64)))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	setOAuthStateCookie(ctx, data.State)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: data,
+	})
+}
+
+// Unlink 解绑第三方账号
+func (h *OAuthHandler) Unlink(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	entry := audit.FromGin(ctx, audit.ActionOAuthUnlink)
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = audit.FormatID(entry.ActorID)
+	entry.Details = map[string]any{"provider": ctx.Param("provider")}
+	if err := h.oauth.Unlink(ctx, uint(userID.(uint64)), ctx.Param("provider")); err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details["reason"] = audit.Reason(err)
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), "msg.unlink_success"),
+	})
+}
+
+// Identities 查询当前用户已绑定的第三方账号
+func (h *OAuthHandler) Identities(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	identities, err := h.oauth.ListIdentities(ctx, uint(userID.(uint64)))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: identities,
+	})
+}
+
+// setOAuthStateCookie 写入 state Cookie，state 为空时删除。SameSite=Lax 保证从提供方跳转回来的回调请求会带上它
+func setOAuthStateCookie(ctx *gin.Context, state string) {
+	maxAge := 0
+	if state == "" {
+		maxAge = -1
+	}
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oauthStateCookie, state, maxAge, "/oauth", "", ctx.Request.TLS != nil, true)
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/audit"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/request"
+	"blueLock/backend/internal/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// UserHandler 用户自助接口（密码、邮箱、设备、语言偏好、通知设置）
+type UserHandler struct {
+	login  *logic.LoginLogic
+	device *logic.DeviceLogic
+}
+
+// NewUserHandler 创建UserHandler
+func NewUserHandler(login *logic.LoginLogic, device *logic.DeviceLogic) *UserHandler {
+	return &UserHandler{login: login, device: device}
+}
+
+// ChangePassword 修改密码
+func (h *UserHandler) ChangePassword(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	var req request.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	entry := audit.FromGin(ctx, audit.ActionPasswordChange)
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = audit.FormatID(entry.ActorID)
+	if err := h.login.ChangePassword(ctx, uint(userID.(uint64)), &req); err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), "msg.password_changed"),
+	})
+}
+
+// ChangeEmail 修改邮箱
+func (h *UserHandler) ChangeEmail(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	var req request.ChangeEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	entry := audit.FromGin(ctx, audit.ActionEmailChange)
+	entry.TargetType = audit.TargetUser
+	entry.TargetID = audit.FormatID(entry.ActorID)
+	if err := h.login.ChangeEmail(ctx, uint(userID.(uint64)), &req); err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), "msg.email_changed"),
+	})
+}
+
+// ListDevices 查询当前用户绑定的设备
+func (h *UserHandler) ListDevices(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	devices, err := h.device.List(ctx, uint(userID.(uint64)))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: devices,
+	})
+}
+
+// BindDevice 绑定设备
+func (h *UserHandler) BindDevice(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	var req request.BindDeviceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	device, err := h.device.Bind(ctx, uint(userID.(uint64)), &req)
+	entry := audit.FromGin(ctx, audit.ActionDeviceBind)
+	entry.TargetType = audit.TargetDevice
+	entry.Details = map[string]any{"serial": req.Serial}
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details["reason"] = audit.Reason(err)
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	entry.TargetID = audit.FormatID(device.ID)
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: device,
+	})
+}
+
+// UnbindDevice 解绑设备
+func (h *UserHandler) UnbindDevice(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	var uri request.DeviceURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	entry := audit.FromGin(ctx, audit.ActionDeviceUnbind)
+	entry.TargetType = audit.TargetDevice
+	entry.TargetID = audit.FormatID(uri.ID)
+	if err := h.device.Unbind(ctx, uint(userID.(uint64)), uri.ID); err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Details = map[string]any{"reason": audit.Reason(err)}
+		audit.Record(entry)
+		ctx.Error(err)
+		return
+	}
+	audit.Record(entry)
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(i18n.FromGin(ctx), "msg.device_unbound"),
+	})
+}
+
+// UpdateLocale 修改语言偏好
+func (h *UserHandler) UpdateLocale(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	var req request.UpdateLocaleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	if err := h.login.UpdateLocale(ctx, uint(userID.(uint64)), req.Locale); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: i18n.T(req.Locale, "msg.locale_updated"),
+	})
+}
+
+// NotificationSettings 查询通知设置
+func (h *UserHandler) NotificationSettings(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	settings, err := h.login.NotificationSettings(ctx, uint(userID.(uint64)))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: settings,
+	})
+}
+
+// UpdateNotification 开启或关闭一种通知，返回更新后的全部设置
+func (h *UserHandler) UpdateNotification(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.Error(apperr.ErrUnauthorized)
+		return
+	}
+	var req request.UpdateNotificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(apperr.ErrBadRequest.Wrap(err))
+		return
+	}
+	settings, err := h.login.UpdateNotification(ctx, uint(userID.(uint64)), req.Kind, *req.Enabled)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.Success{
+		Code: globals.StatusOK,
+		Data: settings,
+	})
+}
@@ -0,0 +1,109 @@
+package logic
+
+import (
+	v1 "blueLock/backend/api/v1"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/repository"
+	"blueLock/backend/internal/request"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AdminLogic 管理端业务逻辑
+type AdminLogic struct {
+	userRepo   repository.UserStore
+	tokenRepo  repository.TokenStore
+	deviceRepo *repository.DeviceRepository
+	jwt        globals.JWTConfig
+}
+
+// NewAdminLogic 创建并返回一个新的 AdminLogic 实例
+func NewAdminLogic(
+	userRepo repository.UserStore,
+	tokenRepo repository.TokenStore,
+	deviceRepo *repository.DeviceRepository,
+	jwt globals.JWTConfig,
+) *AdminLogic {
+	return &AdminLogic{
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		deviceRepo: deviceRepo,
+		jwt:        jwt,
+	}
+}
+
+// ListUsers 分页查询、搜索用户
+func (l *AdminLogic) ListUsers(ctx context.Context, req *request.AdminListUsersRequest) (*v1.AdminUserListData, error) {
+	page, size := req.Page, req.Size
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = 20
+	}
+	keyword := strings.ToLower(strings.TrimSpace(req.Keyword))
+	users, total, err := l.userRepo.ListUsers(ctx, keyword, (page-1)*size, size)
+	if err != nil {
+		return nil, fmt.Errorf("查询用户列表失败: %w", err)
+	}
+	items := make([]v1.AdminUserData, 0, len(users))
+	for i := range users {
+		items = append(items, toAdminUserData(&users[i]))
+	}
+	return &v1.AdminUserListData{Total: total, Items: items}, nil
+}
+
+// GetUser 查询单个用户
+func (l *AdminLogic) GetUser(ctx context.Context, userID uint) (*v1.AdminUserData, error) {
+	user, err := l.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data := toAdminUserData(user)
+	return &data, nil
+}
+
+// SetDisabled 禁用或启用账号，禁用时同时强制下线
+func (l *AdminLogic) SetDisabled(ctx context.Context, userID uint, disabled bool) error {
+	if err := l.userRepo.SetDisabled(ctx, userID, disabled); err != nil {
+		return err
+	}
+	if disabled {
+		return l.ForceLogout(ctx, userID)
+	}
+	return nil
+}
+
+// ForceLogout 强制用户下线
+func (l *AdminLogic) ForceLogout(ctx context.Context, userID uint) error {
+	// 吊销记录至少要保留到最后一个访问令牌过期
+	if err := l.tokenRepo.RevokeUserTokens(ctx, userID, l.jwt.AccessTokenExpiry); err != nil {
+		return fmt.Errorf("强制下线失败: %w", err)
+	}
+	return nil
+}
+
+// ListDevices 查询用户绑定的设备
+func (l *AdminLogic) ListDevices(ctx context.Context, userID uint) ([]models.Device, error) {
+	devices, err := l.deviceRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询设备失败: %w", err)
+	}
+	return devices, nil
+}
+
+func toAdminUserData(user *models.User) v1.AdminUserData {
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+	return v1.AdminUserData{
+		ID:        user.ID,
+		Email:     user.Email,
+		Disabled:  user.Disabled,
+		Roles:     roles,
+		CreatedAt: user.CreatedAt,
+	}
+}
@@ -0,0 +1,58 @@
+package logic
+
+import (
+	v1 "blueLock/backend/api/v1"
+	"blueLock/backend/internal/repository"
+	"blueLock/backend/internal/request"
+	"context"
+	"fmt"
+)
+
+// AuditLogic 审计日志查询与校验
+type AuditLogic struct {
+	repo *repository.AuditRepository
+}
+
+// NewAuditLogic 创建并返回一个新的 AuditLogic 实例
+func NewAuditLogic(repo *repository.AuditRepository) *AuditLogic {
+	return &AuditLogic{repo: repo}
+}
+
+// Query 分页查询审计日志
+func (l *AuditLogic) Query(ctx context.Context, req *request.AdminAuditQueryRequest) (*v1.AuditLogListData, error) {
+	page, size := req.Page, req.Size
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = 20
+	}
+	logs, total, err := l.repo.Query(ctx, repository.AuditFilter{
+		ActorID:  req.ActorID,
+		Action:   req.Action,
+		TargetID: req.TargetID,
+		Result:   req.Result,
+		From:     req.From,
+		To:       req.To,
+		Offset:   (page - 1) * size,
+		Limit:    size,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+	return &v1.AuditLogListData{Total: total, Items: logs}, nil
+}
+
+// Verify 校验审计日志哈希链是否被篡改
+func (l *AuditLogic) Verify(ctx context.Context) (*v1.AuditVerifyData, error) {
+	checked, brokenID, truncated, err := l.repo.VerifyChain(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("校验审计日志失败: %w", err)
+	}
+	return &v1.AuditVerifyData{
+		Checked:   checked,
+		Intact:    brokenID == 0 && !truncated,
+		BrokenID:  brokenID,
+		Truncated: truncated,
+	}, nil
+}
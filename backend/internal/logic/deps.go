@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"blueLock/backend/internal/pkg/notify"
+	"blueLock/backend/internal/pkg/password"
+	"context"
+)
+
+// PasswordHasher 计算和校验密码哈希，password.Hasher 实现了它
+type PasswordHasher interface {
+	// Hash 计算密码哈希
+	Hash(ctx context.Context, password string) (string, error)
+	// Verify 校验密码，rehash 为 true 表示哈希需要按当前配置重新计算
+	Verify(ctx context.Context, encoded, password string) (ok, rehash bool, err error)
+}
+
+// MailSender 按模板发送邮件，notify.Mailer 实现了它
+type MailSender interface {
+	Send(ctx context.Context, to, locale, name string, data map[string]any) error
+}
+
+// EventNotifier 发送安全通知，notify.Notifier 实现了它
+type EventNotifier interface {
+	Notify(ctx context.Context, e notify.Event)
+}
+
+// MailQueue 在后台发送模板邮件，notify.Notifier 实现了它
+type MailQueue interface {
+	Deliver(ctx context.Context, to, locale, name string, data map[string]any)
+}
+
+var (
+	_ PasswordHasher = (*password.Hasher)(nil)
+	_ MailSender     = (*notify.Mailer)(nil)
+	_ EventNotifier  = (*notify.Notifier)(nil)
+	_ MailQueue      = (*notify.Notifier)(nil)
+)
@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/repository"
+	"blueLock/backend/internal/request"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DeviceLogic 设备绑定相关业务逻辑
+type DeviceLogic struct {
+	repo *repository.DeviceRepository
+}
+
+// NewDeviceLogic 创建并返回一个新的 DeviceLogic 实例
+func NewDeviceLogic(repo *repository.DeviceRepository) *DeviceLogic {
+	return &DeviceLogic{repo: repo}
+}
+
+// Bind 绑定设备，一个设备同一时间只能属于一个用户
+func (l *DeviceLogic) Bind(ctx context.Context, userID uint, req *request.BindDeviceRequest) (*models.Device, error) {
+	serial := strings.ToUpper(strings.TrimSpace(req.Serial))
+	if serial == "" {
+		return nil, apperr.ErrBadRequest.Wrap(errors.New("设备序列号不能为空"))
+	}
+	existing, err := l.repo.GetBySerial(ctx, serial)
+	if err != nil {
+		return nil, fmt.Errorf("查询设备失败: %w", err)
+	}
+	if existing != nil {
+		if existing.UserID == userID {
+			return existing, nil
+		}
+		return nil, apperr.ErrDeviceTaken
+	}
+	device := &models.Device{
+		UserID: userID,
+		Serial: serial,
+		Name:   strings.TrimSpace(req.Name),
+	}
+	if err := l.repo.Create(ctx, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// Unbind 解绑设备
+func (l *DeviceLogic) Unbind(ctx context.Context, userID, deviceID uint) error {
+	rows, err := l.repo.Delete(ctx, userID, deviceID)
+	if err != nil {
+		return fmt.Errorf("解绑设备失败: %w", err)
+	}
+	if rows == 0 {
+		return apperr.ErrDeviceNotFound
+	}
+	return nil
+}
+
+// Heartbeat 设备通过网关上报在线，记录最近一次连接的时间
+func (l *DeviceLogic) Heartbeat(ctx context.Context, device *models.Device) (*models.Device, error) {
+	now := time.Now()
+	if err := l.repo.TouchLastSeen(ctx, device.ID, now); err != nil {
+		return nil, fmt.Errorf("更新设备在线时间失败: %w", err)
+	}
+	device.LastSeenAt = &now
+	return device, nil
+}
+
+// List 查询用户绑定的设备
+func (l *DeviceLogic) List(ctx context.Context, userID uint) ([]models.Device, error) {
+	return l.repo.ListByUserID(ctx, userID)
+}
@@ -0,0 +1,169 @@
+package logic_test
+
+import (
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/migrations"
+	"blueLock/backend/internal/pkg/clientinfo"
+	"blueLock/backend/internal/pkg/database"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/kv"
+	"blueLock/backend/internal/pkg/migrate"
+	"blueLock/backend/internal/pkg/notify"
+	"blueLock/backend/internal/pkg/password"
+	"blueLock/backend/internal/pkg/token"
+	"blueLock/backend/internal/repository"
+	"blueLock/backend/internal/repository/memory"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// testEnv 全部使用内存实现的 logic 层依赖，不需要数据库、Redis 和 SMTP
+type testEnv struct {
+	users    *memory.UserRepository
+	tokens   *memory.TokenRepository
+	codes    *memory.CodeRepository
+	store    kv.Store
+	service  *token.Service
+	mailer   *fakeMailer
+	notifier *fakeNotifier
+	hasher   *password.Hasher
+	jwt      globals.JWTConfig
+	login    *logic.LoginLogic
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	env := &testEnv{
+		users:    memory.NewUserRepository(),
+		tokens:   memory.NewTokenRepository(nil),
+		codes:    memory.NewCodeRepository(nil),
+		store:    kv.NewMemoryStore(0),
+		mailer:   &fakeMailer{},
+		notifier: &fakeNotifier{},
+		// 测试中使用最小的 argon2id 参数
+		hasher: password.NewHasher(globals.PasswordHashConfig{Memory: 64, Iterations: 1, Parallelism: 1}),
+		jwt: globals.JWTConfig{
+			SecretKey:          "test-secret-key-test-secret-key-0123",
+			AccessTokenExpiry:  time.Minute,
+			RefreshTokenExpiry: time.Hour,
+		},
+	}
+	t.Cleanup(func() { _ = env.store.Close() })
+	env.service = token.NewService(token.Config{
+		SecretKey:          env.jwt.SecretKey,
+		AccessTokenExpiry:  env.jwt.AccessTokenExpiry,
+		RefreshTokenExpiry: env.jwt.RefreshTokenExpiry,
+	})
+	env.login = newLoginLogic(env)
+	return env
+}
+
+// newLoginLogic 用 env 中的依赖构造登录逻辑，替换 env 中的依赖后重新调用
+func newLoginLogic(env *testEnv) *logic.LoginLogic {
+	return logic.NewLoginLogic(env.users, env.service, env.tokens, env.codes,
+		repository.NewSecurityRepository(env.store), password.NewPolicy(globals.PasswordConfig{}),
+		env.hasher, env.mailer, env.notifier, env.jwt, globals.NotifyConfig{}, zap.NewNop().Sugar())
+}
+
+// withDevice 模拟来自某个浏览器的请求
+func withDevice(userAgent string) context.Context {
+	return clientinfo.NewContext(context.Background(), clientinfo.Info{IP: "192.0.2.1", UserAgent: userAgent})
+}
+
+const (
+	chromeLinux  = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+	firefoxMacOS = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14.1; rv:121.0) Gecko/20100101 Firefox/121.0"
+)
+
+type sentMail struct {
+	To, Locale, Name string
+	Data             map[string]any
+}
+
+// fakeMailer 记录发出的邮件，err 非空时发送失败
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []sentMail
+	err  error
+}
+
+func (m *fakeMailer) Send(_ context.Context, to, locale, name string, data map[string]any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, sentMail{To: to, Locale: locale, Name: name, Data: data})
+	return nil
+}
+
+// Deliver 同步发送，失败时与 notify.Notifier 一样丢弃
+func (m *fakeMailer) Deliver(ctx context.Context, to, locale, name string, data map[string]any) {
+	_ = m.Send(ctx, to, locale, name, data)
+}
+
+// last 最近一封发给 to 的邮件
+func (m *fakeMailer) last(t *testing.T, to string) sentMail {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if strings.EqualFold(m.sent[i].To, to) {
+			return m.sent[i]
+		}
+	}
+	t.Fatalf("没有发给 %s 的邮件", to)
+	return sentMail{}
+}
+
+// fakeNotifier 记录通知事件
+type fakeNotifier struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, e notify.Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, e)
+}
+
+func (n *fakeNotifier) kinds() []notify.Kind {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	kinds := make([]notify.Kind, 0, len(n.events))
+	for _, e := range n.events {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+// openSQLite 打开内存 SQLite 数据库并执行全部迁移，用于只有 gorm 实现的仓储
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(globals.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"},
+		gormlogger.Default.LogMode(gormlogger.Silent))
+	if err != nil {
+		t.Fatalf("打开 SQLite 失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	m, err := migrate.New(db, migrations.All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return db
+}
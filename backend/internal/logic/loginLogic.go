@@ -3,79 +3,121 @@ package logic
 import (
 	v1 "blueLock/backend/api/v1"
 	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/clientinfo"
+	"blueLock/backend/internal/pkg/emailaddr"
 	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/pkg/metrics"
+	"blueLock/backend/internal/pkg/notify"
+	"blueLock/backend/internal/pkg/password"
 	"blueLock/backend/internal/pkg/token"
 	"blueLock/backend/internal/repository"
 	"blueLock/backend/internal/request"
 	"context"
-	"crypto/tls"
+	crand "crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"github.com/go-redis/redis/v8"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 	"math/rand"
-	"net/smtp"
+	"net/url"
 	"strings"
 	"time"
-
-	"github.com/patrickmn/go-cache"
 )
 
 // LoginLogic 提供了登录相关的业务逻辑操作
 type LoginLogic struct {
-	repo         *repository.LoginRepository
+	repo         repository.UserStore
 	tokenService *token.Service
-	tokenRepo    *repository.TokenRepository
+	tokenRepo    repository.TokenStore
+	codes        repository.CodeStore
+	security     repository.SecurityStore
+	passwords    *password.Policy
+	hasher       PasswordHasher
+	mailer       MailSender
+	notifier     EventNotifier
+	jwt          globals.JWTConfig
+	notify       globals.NotifyConfig
+	log          *zap.SugaredLogger
 }
 
+// 安全通知相关的默认值
+const (
+	defaultEmailRevertTTL = 7 * 24 * time.Hour
+	defaultKnownDeviceTTL = 180 * 24 * time.Hour
+)
+
 // NewLoginLogic 创建并返回一个新的 LoginLogic 实例
 func NewLoginLogic(
-	repo *repository.LoginRepository,
+	repo repository.UserStore,
 	tokenService *token.Service,
-	tokenRepo *repository.TokenRepository,
+	tokenRepo repository.TokenStore,
+	codes repository.CodeStore,
+	security repository.SecurityStore,
+	passwords *password.Policy,
+	hasher PasswordHasher,
+	mailer MailSender,
+	notifier EventNotifier,
+	jwt globals.JWTConfig,
+	notifyCfg globals.NotifyConfig,
+	log *zap.SugaredLogger,
 ) *LoginLogic {
+	if notifyCfg.EmailRevertTTL <= 0 {
+		notifyCfg.EmailRevertTTL = defaultEmailRevertTTL
+	}
+	if notifyCfg.KnownDeviceTTL <= 0 {
+		notifyCfg.KnownDeviceTTL = defaultKnownDeviceTTL
+	}
 	return &LoginLogic{
 		repo:         repo,
 		tokenService: tokenService,
 		tokenRepo:    tokenRepo,
+		codes:        codes,
+		security:     security,
+		passwords:    passwords,
+		hasher:       hasher,
+		mailer:       mailer,
+		notifier:     notifier,
+		jwt:          jwt,
+		notify:       notifyCfg,
+		log:          log,
 	}
 }
 
-var (
-	verificationCodeCache = cache.New(5*time.Minute, 10*time.Minute)
-)
-
-type EmailConfig struct {
-	SMTPHost  string // SMTP服务器地址
-	SMTPPort  int    // SMTP端口
-	FromEmail string // 发件人邮箱
-	Password  string // 授权码
-	FromName  string // 发件人名称
+// logger 返回请求上下文中的日志器（带请求 ID、用户 ID 等字段），不在请求中时使用注入的日志器
+func (l *LoginLogic) logger(ctx context.Context) *zap.SugaredLogger {
+	return logger.FromContext(ctx, l.log)
 }
 
-// SendVerificationCode 发送验证码
-func (l *LoginLogic) SendVerificationCode(c context.Context, toUser string) error {
+// SendVerificationCode 发送验证码，locale 为空时使用该邮箱用户保存的语言偏好
+func (l *LoginLogic) SendVerificationCode(c context.Context, toUser string, locale string) error {
 	code := l.GenerateVerificationCode()
 
-	// 先存储验证码到Redis，确保即使邮件发送失败也能存储
-	// 标准化email（转小写、去除空格）确保存储和读取时key一致
-	normalizedEmail := strings.ToLower(strings.TrimSpace(toUser))
-	key := fmt.Sprintf("verify_code:%s", normalizedEmail)
-	err := globals.RDB.SetEX(c, key, code, 5*time.Minute).Err()
+	// 先存储验证码，确保即使邮件发送失败也能存储
+	// 规范化邮箱确保存储和读取时key一致
+	normalizedEmail := emailaddr.Normalize(toUser)
+	err := l.codes.SaveCode(c, normalizedEmail, code, 5*time.Minute)
 	if err != nil {
 		return fmt.Errorf("验证码存储失败: %w", err)
 	}
 
-	// 记录日志以便调试
-	globals.Log.Infof("验证码已存储到Redis，key: %s, code: %s, email: %s", key, code, normalizedEmail)
+	// 记录日志以便调试，不记录验证码本身
+	l.logger(c).Debugf("验证码已存储，email: %s", normalizedEmail)
 
 	// 再发送邮件
-	err = l.SendCode(toUser, code)
+	if locale == "" {
+		locale = l.userLocaleByEmail(c, normalizedEmail)
+	}
+	err = l.SendCode(c, normalizedEmail, code, locale)
 	if err != nil {
 		// 即使邮件发送失败，验证码也已经存储，用户可以重试
-		globals.Log.Warnf("邮件发送失败，但验证码已存储: %v", err)
-		return fmt.Errorf("邮件发送失败: %w", err)
+		l.logger(c).Warnf("邮件发送失败，但验证码已存储: %v", err)
+		metrics.VerificationCodes.WithLabelValues("failed").Inc()
+		return apperr.ErrMailSendFailed.Wrap(err)
 	}
+	metrics.VerificationCodes.WithLabelValues("sent").Inc()
 
 	return nil
 }
@@ -87,189 +129,183 @@ func (l *LoginLogic) GenerateVerificationCode() string {
 	return code
 }
 
-// SendCode 发送验证码过程 - 使用SMTP方式（修复short response问题）
-func (l *LoginLogic) SendCode(to string, code string) error {
-	config := &EmailConfig{
-		SMTPHost:  "smtp.qq.com",
-		SMTPPort:  587,
-		FromEmail: "3095660240@qq.com",
-		Password:  "dlionbtrfwogdcha",
-		FromName:  "验证码系统",
-	}
-
-	// 构建邮件内容
-	subject := "Subject: Verification Code\r\n"
-	from := fmt.Sprintf("From: %s <%s>\r\n", config.FromName, config.FromEmail)
-	toHeader := fmt.Sprintf("To: %s\r\n", to)
-	mime := "MIME-Version: 1.0\r\n"
-	contentType := "Content-Type: text/html; charset=UTF-8\r\n"
-	body := fmt.Sprintf("\r\n<h1>验证码</h1><p>您的验证码是: <strong>%s</strong></p><p>5分钟内有效，请勿泄露</p>", code)
-
-	msg := []byte(from + toHeader + subject + mime + contentType + "\r\n" + body)
-
-	// 配置TLS
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: false,
-		ServerName:         config.SMTPHost,
-	}
-
-	// 连接到SMTP服务器
-	addr := fmt.Sprintf("%s:%d", config.SMTPHost, config.SMTPPort)
-	client, err := smtp.Dial(addr)
-	if err != nil {
-		return fmt.Errorf("连接SMTP服务器失败: %w", err)
-	}
-
-	// 使用defer确保连接关闭，但不使用Quit()避免short response错误
-	defer func() {
-		if client != nil {
-			client.Close()
-		}
-	}()
-
-	// 启动TLS
-	if err = client.StartTLS(tlsConfig); err != nil {
-		return fmt.Errorf("启动TLS失败: %w", err)
-	}
-
-	// 认证
-	auth := smtp.PlainAuth("", config.FromEmail, config.Password, config.SMTPHost)
-	if err = client.Auth(auth); err != nil {
-		return fmt.Errorf("SMTP认证失败: %w", err)
-	}
-
-	// 设置发件人和收件人
-	if err = client.Mail(config.FromEmail); err != nil {
-		return fmt.Errorf("设置发件人失败: %w", err)
-	}
-	if err = client.Rcpt(to); err != nil {
-		return fmt.Errorf("设置收件人失败: %w", err)
-	}
-
-	// 发送邮件内容
-	writer, err := client.Data()
-	if err != nil {
-		return fmt.Errorf("准备发送数据失败: %w", err)
-	}
-	if _, err = writer.Write(msg); err != nil {
-		return fmt.Errorf("写入邮件内容失败: %w", err)
-	}
-	if err = writer.Close(); err != nil {
-		return fmt.Errorf("关闭数据流失败: %w", err)
+// userLocaleByEmail 查询邮箱对应用户的语言偏好，用户不存在或未设置时返回默认语言
+func (l *LoginLogic) userLocaleByEmail(ctx context.Context, email string) string {
+	user, err := l.repo.GetUserByEmail(ctx, email)
+	if err != nil || !i18n.IsSupported(user.Locale) {
+		return i18n.DefaultLocale
 	}
+	return user.Locale
+}
 
-	// 邮件已成功发送，直接返回，让defer处理连接关闭
-	// 不调用Quit()避免short response错误
-	return nil
+// SendCode 发送验证码邮件
+func (l *LoginLogic) SendCode(ctx context.Context, to string, code string, locale string) error {
+	return l.mailer.Send(ctx, to, locale, "verification_code", map[string]any{
+		"Code":          code,
+		"ExpireMinutes": 5,
+	})
 }
 
 // RegisterEmail 注册邮箱
 func (l *LoginLogic) RegisterEmail(ctx context.Context, req *request.RegisterByVerificationCodeRequest) (*models.User, error) {
+	req.Email = emailaddr.Normalize(req.Email)
 	// 1.判断验证码是否正确
-	isTrue := l.VerifyVerificationCode(ctx, req.Email, req.Code)
-	if !isTrue {
-		return nil, fmt.Errorf("验证码存在错误")
+	if err := l.VerifyVerificationCode(ctx, req.Email, req.Code); err != nil {
+		return nil, err
 	}
 
 	// 2. 验证用户信息
 	err := l.VerifyMes(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("验证用户信息错误 err: %s", err)
+		return nil, err
 	}
 	// 3. 密码加密生成 hash
-	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashed, err := l.hasher.Hash(ctx, req.Password)
 	if err != nil {
 		return nil, err
 	}
 	// 4. 邮箱密码存入数据库
+	locale := req.Locale
+	if !i18n.IsSupported(locale) {
+		locale = i18n.DefaultLocale
+	}
 	user := &models.User{
 		Email:    req.Email,
-		PassWord: string(hashed),
+		PassWord: hashed,
+		Locale:   locale,
 	}
 	err = l.repo.CreateUser(ctx, user)
 	if err != nil {
 		return nil, err
 	}
-	user, err = l.repo.GetUserByID(ctx, user.ID)
-	if err != nil {
-		return nil, fmt.Errorf("查询用户时存在错误 err: %w", err)
-	}
-	return user, nil
+	return l.repo.GetUserByID(ctx, user.ID)
 }
 
 // VerifyMes 验证信息
 func (l *LoginLogic) VerifyMes(ctx context.Context, req *request.RegisterByVerificationCodeRequest) error {
-	// 1. 判断密码是否符合格式
-	if len(req.Password) < 6 {
-		return fmt.Errorf("用户输入密码至少六位")
+	// 1. 判断密码是否符合密码策略
+	if err := l.passwords.Validate("password", req.Password); err != nil {
+		return err
 	}
 	// 2. 判断邮箱是否存在
 	isExists, err := l.repo.ExistsByEmail(ctx, req.Email)
 	if err != nil {
-		return fmt.Errorf("该用户已存在 err: %w", err)
+		return fmt.Errorf("查询邮箱是否存在失败: %w", err)
 	}
 	if isExists {
-		return fmt.Errorf("已经存在邮箱为 %v 的用户", req.Email)
+		return apperr.ErrEmailTaken
 	}
 	return nil
 }
 
-// VerifyVerificationCode 验证邮箱验证码是否正确
-func (l *LoginLogic) VerifyVerificationCode(ctx context.Context, email string, code string) bool {
-	// 标准化email（转小写、去除空格）确保存储和读取时key一致
-	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
-	key := fmt.Sprintf("verify_code:%s", normalizedEmail)
+// VerifyVerificationCode 验证邮箱验证码是否正确，错误时返回 ErrCodeExpired 或 ErrCodeInvalid
+func (l *LoginLogic) VerifyVerificationCode(ctx context.Context, email string, code string) error {
+	// 规范化邮箱确保存储和读取时key一致
+	normalizedEmail := emailaddr.Normalize(email)
 
-	globals.Log.Infof("尝试从Redis读取验证码，key: %s, email: %s, 输入的code: %s", key, normalizedEmail, code)
+	l.logger(ctx).Debugf("尝试校验验证码，email: %s", normalizedEmail)
 
-	storedCode, err := globals.RDB.Get(ctx, key).Result()
-	if err == redis.Nil {
-		// redis 返回 nil，已过期或者根本没发送
-		globals.Log.Warnf("验证码已过期或不存在，key: %s, email: %s", key, normalizedEmail)
-		return false
-	}
+	// 比较与删除是原子的，验证码只能成功使用一次（防止重复使用）
+	ok, err := l.codes.ConsumeCode(ctx, normalizedEmail, code)
 	if err != nil {
-		globals.Log.Errorf("redis查询失败，key: %s, error: %v", key, err)
-		return false
+		l.logger(ctx).Errorf("验证码校验失败，email: %s, error: %v", normalizedEmail, err)
+		return fmt.Errorf("校验验证码失败: %w", err)
+	}
+	if ok {
+		l.logger(ctx).Debugf("验证码验证成功，已删除，email: %s", normalizedEmail)
+		return nil
 	}
 
-	globals.Log.Infof("从Redis读取到验证码，key: %s, storedCode: %s, inputCode: %s", key, storedCode, code)
-
-	// 验证成功后立即删除（防止重复使用）
-	if storedCode == code {
-		globals.RDB.Del(ctx, key)
-		globals.Log.Infof("验证码验证成功，已删除key: %s", key)
-		return true
+	// 校验未通过，区分是过期还是输错
+	storedCode, err := l.codes.GetCode(ctx, normalizedEmail)
+	if err != nil {
+		l.logger(ctx).Errorf("验证码查询失败，email: %s, error: %v", normalizedEmail, err)
+		return fmt.Errorf("查询验证码失败: %w", err)
+	}
+	if storedCode == "" {
+		// 已过期、已被使用或者根本没发送
+		l.logger(ctx).Warnf("验证码已过期或不存在，email: %s", normalizedEmail)
+		return apperr.ErrCodeExpired
 	}
 
-	globals.Log.Warnf("验证码不匹配，key: %s, storedCode: %s, inputCode: %s", key, storedCode, code)
-	return false
+	l.logger(ctx).Warnf("验证码不匹配，email: %s", normalizedEmail)
+	return apperr.ErrCodeInvalid
 }
 
 // LoginByPass 登录验证逻辑
-func (l *LoginLogic) LoginByPass(ctx context.Context, req *request.LoginByPassORCode) (*v1.LoginResponseData, error) {
-	if req.Email == "" {
-		return nil, fmt.Errorf("邮箱不能为空")
+func (l *LoginLogic) LoginByPass(ctx context.Context, req *request.LoginByPassORCode) (data *v1.LoginResponseData, err error) {
+	method := metrics.LoginMethodPassword
+	if req.Password == "" {
+		method = metrics.LoginMethodCode
+	}
+	defer func() { metrics.Logins.WithLabelValues(method, metrics.Result(err)).Inc() }()
+
+	email := emailaddr.Normalize(req.Email)
+	if email == "" {
+		return nil, apperr.ErrBadRequest.Wrap(errors.New("邮箱不能为空"))
 	}
 	// 1. 如果传的是密码，验证邮箱和密码
 	if req.Password != "" {
-		if err := l.repo.GetPasswordByEmail(ctx, req.Email, req.Password); err != nil {
-			return nil, fmt.Errorf("邮箱或者密码错误err: %v", err)
+		user, err := l.checkPassword(ctx, email, req.Password)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		// 2. 如果传的是验证码，就验证验证码和邮箱是否正确
-		if req.Code == "" {
-			return nil, fmt.Errorf("密码或验证码必须提供其一")
+		return l.IssueTokens(ctx, user)
+	}
+
+	// 2. 如果传的是验证码，就验证验证码和邮箱是否正确
+	if req.Code == "" {
+		return nil, apperr.ErrCredentialRequired
+	}
+	if err := l.VerifyVerificationCode(ctx, email, req.Code); err != nil {
+		return nil, err
+	}
+	user, err := l.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.IssueTokens(ctx, user)
+}
+
+// checkPassword 校验邮箱和密码，不匹配时返回 ErrInvalidCredentials。
+// 哈希的算法或参数已过时则按当前配置重新计算并保存，保存失败不影响本次登录
+func (l *LoginLogic) checkPassword(ctx context.Context, email, pass string) (*models.User, error) {
+	user, err := l.repo.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, apperr.ErrUserNotFound) {
+		return nil, err
+	}
+	encoded := ""
+	if user != nil {
+		encoded = user.PassWord
+	}
+	// 用户不存在时 Verify 也会计算一次哈希，响应时间与密码错误时一致
+	ok, rehash, err := l.hasher.Verify(ctx, encoded, pass)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || user == nil {
+		return nil, apperr.ErrInvalidCredentials
+	}
+
+	if rehash {
+		hashed, err := l.hasher.Hash(ctx, pass)
+		if err == nil {
+			err = l.repo.UpdatePassword(ctx, user.ID, hashed)
 		}
-		isRight := l.VerifyVerificationCode(ctx, req.Email, req.Code)
-		if !isRight {
-			return nil, fmt.Errorf("邮箱或验证码不正确")
+		if err != nil {
+			l.logger(ctx).Warnf("重新计算密码哈希失败 userID=%d err=%v", user.ID, err)
+		} else {
+			l.logger(ctx).Infof("密码哈希已升级 userID=%d", user.ID)
 		}
 	}
+	return user, nil
+}
 
-	user, err := l.repo.GetUserByEmail(ctx, req.Email)
-	if err != nil {
-		return nil, fmt.Errorf("查询用户失败: %w", err)
+// IssueTokens 为已通过认证的用户签发访问令牌和刷新令牌
+func (l *LoginLogic) IssueTokens(ctx context.Context, user *models.User) (*v1.LoginResponseData, error) {
+	if user.Disabled {
+		return nil, apperr.ErrAccountDisabled
 	}
 
 	accessToken, err := l.tokenService.GenerateAccessToken(uint64(user.ID))
@@ -281,10 +317,11 @@ func (l *LoginLogic) LoginByPass(ctx context.Context, req *request.LoginByPassOR
 		return nil, fmt.Errorf("生成刷新令牌失败: %w", err)
 	}
 
-	if err := l.tokenRepo.SaveRefreshToken(ctx, user.ID, refreshToken, globals.AppConfig.JWT.RefreshTokenExpiry); err != nil {
+	if err := l.tokenRepo.SaveRefreshToken(ctx, user.ID, refreshToken, l.jwt.RefreshTokenExpiry); err != nil {
 		// 不中断登录流程，但记录日志，方便排查
-		globals.Log.Warnf("保存刷新令牌失败 userID=%d err=%v", user.ID, err)
+		l.logger(ctx).Warnf("保存刷新令牌失败 userID=%d err=%v", user.ID, err)
 	}
+	l.noteLoginDevice(ctx, user)
 
 	return &v1.LoginResponseData{
 		AccessToken:  accessToken,
@@ -293,46 +330,278 @@ func (l *LoginLogic) LoginByPass(ctx context.Context, req *request.LoginByPassOR
 	}, nil
 }
 
-// RefreshToken 刷新访问令牌
-func (l *LoginLogic) RefreshToken(ctx context.Context, refreshToken string) (*v1.LoginResponseData, error) {
+// noteLoginDevice 记录本次登录的设备。用户此前在其他设备上登录过、而这台设备是第一次（或长期未用后）登录时发送新设备登录通知，
+// 注册后的首次登录不通知。设备按 clientinfo.Info.Device 粗略区分，同类浏览器和系统视为同一设备
+func (l *LoginLogic) noteLoginDevice(ctx context.Context, user *models.User) {
+	device := clientinfo.FromContext(ctx).Device()
+	known, firstLogin, err := l.security.TouchLoginDevice(ctx, user.ID, device, l.notify.KnownDeviceTTL)
+	if err != nil {
+		l.logger(ctx).Warnf("记录登录设备失败 userID=%d err=%v", user.ID, err)
+		return
+	}
+	if !known && !firstLogin {
+		l.notifier.Notify(ctx, notify.Event{Kind: notify.KindNewLogin, User: user})
+	}
+}
+
+// RefreshToken 刷新访问令牌，同时轮换刷新令牌：旧令牌作废并记录下来，
+// 已作废的刷新令牌再次出现（包括同一个令牌被并发使用）说明令牌可能被盗用，吊销该用户全部令牌并通知用户
+func (l *LoginLogic) RefreshToken(ctx context.Context, refreshToken string) (data *v1.LoginResponseData, err error) {
+	defer func() { metrics.TokenRefreshes.WithLabelValues(metrics.Result(err)).Inc() }()
+
 	if strings.TrimSpace(refreshToken) == "" {
-		return nil, errors.New("刷新令牌不能为空")
+		return nil, apperr.ErrTokenInvalid
 	}
 
 	claims, err := l.tokenService.ParseToken(refreshToken)
 	if err != nil {
-		return nil, errors.New("刷新令牌无效或已过期")
+		return nil, apperr.ErrTokenInvalid.Wrap(err)
 	}
 
 	if !token.IsRefreshToken(claims) {
-		return nil, errors.New("无效的刷新令牌类型")
+		return nil, apperr.ErrTokenInvalid.Wrap(errors.New("无效的刷新令牌类型"))
 	}
 
-	storedToken, err := l.tokenRepo.GetRefreshToken(ctx, uint(claims.UserID))
+	userID := uint(claims.UserID)
+	// 吊销之后才保存的令牌（与吊销并发的刷新）同样无效
+	revokedAt, err := l.tokenRepo.GetRevokedAt(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询令牌吊销时间失败: %w", err)
+	}
+	if token.IsRevoked(claims, revokedAt) {
+		return nil, apperr.ErrTokenRevoked
+	}
+
+	storedToken, err := l.tokenRepo.GetRefreshToken(ctx, userID)
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			return nil, errors.New("刷新令牌无效或已过期")
-		}
 		return nil, fmt.Errorf("查询刷新令牌失败: %w", err)
 	}
 
-	if storedToken != refreshToken {
-		return nil, errors.New("刷新令牌无效或已过期")
+	// 未保存（已登出、已过期）或已被新令牌替换
+	if storedToken == "" || storedToken != refreshToken {
+		used, err := l.tokenRepo.IsRefreshTokenUsed(ctx, userID, refreshToken)
+		if err != nil {
+			l.logger(ctx).Warnf("查询刷新令牌轮换记录失败 userID=%d err=%v", userID, err)
+		} else if used {
+			l.handleTokenReuse(ctx, userID)
+		}
+		return nil, apperr.ErrTokenInvalid
 	}
 
+	// 新令牌在使用旧令牌之前签发：并发请求中落败的一方吊销令牌时，胜出的一方拿到的令牌一定早于吊销时间，随之失效
 	newAccessToken, err := l.tokenService.GenerateAccessToken(claims.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("生成访问令牌失败: %w", err)
 	}
+	newRefreshToken, err := l.tokenService.GenerateRefreshToken(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	// 先记录轮换再使用旧令牌，并发请求中落败的一方一定能查到轮换记录。
+	// 记录保留到旧令牌原本过期为止，之后旧令牌自身就无法通过签名校验
+	if remaining := time.Until(claims.ExpiresAt.Time); remaining > 0 {
+		if err := l.tokenRepo.MarkRefreshTokenUsed(ctx, userID, refreshToken, remaining); err != nil {
+			l.logger(ctx).Warnf("记录刷新令牌轮换失败 userID=%d err=%v", userID, err)
+		}
+	}
+	// 比较与删除是原子的，同一个令牌只有一个请求能使用
+	consumed, err := l.tokenRepo.ConsumeRefreshToken(ctx, userID, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("使用刷新令牌失败: %w", err)
+	}
+	if !consumed {
+		l.handleTokenReuse(ctx, userID)
+		return nil, apperr.ErrTokenInvalid
+	}
+	if err := l.tokenRepo.SaveRefreshToken(ctx, userID, newRefreshToken, l.jwt.RefreshTokenExpiry); err != nil {
+		return nil, fmt.Errorf("保存刷新令牌失败: %w", err)
+	}
 
 	return &v1.LoginResponseData{
 		AccessToken:  newAccessToken,
-		RefreshToken: refreshToken,
-		UserID:       uint(claims.UserID),
+		RefreshToken: newRefreshToken,
+		UserID:       userID,
 	}, nil
 }
 
+// handleTokenReuse 已轮换的刷新令牌被再次使用：吊销该用户全部令牌，合法用户和盗用者都需要重新登录
+func (l *LoginLogic) handleTokenReuse(ctx context.Context, userID uint) {
+	l.logger(ctx).Warnf("已轮换的刷新令牌被再次使用，吊销全部令牌 userID=%d", userID)
+	if err := l.tokenRepo.RevokeUserTokens(ctx, userID, l.jwt.AccessTokenExpiry); err != nil {
+		l.logger(ctx).Errorf("吊销令牌失败 userID=%d err=%v", userID, err)
+	}
+	user, err := l.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		l.logger(ctx).Warnf("查询用户失败，无法发送令牌重用通知 userID=%d err=%v", userID, err)
+		return
+	}
+	l.notifier.Notify(ctx, notify.Event{Kind: notify.KindTokenReuse, User: user})
+}
+
 // Logout 登出删除token逻辑
 func (l *LoginLogic) Logout(ctx context.Context, userID uint) error {
 	return l.tokenRepo.DeleteRefreshToken(ctx, userID)
 }
+
+// ChangePassword 修改密码，成功后吊销该用户已签发的全部令牌
+func (l *LoginLogic) ChangePassword(ctx context.Context, userID uint, req *request.ChangePasswordRequest) error {
+	if err := l.passwords.Validate("new_password", req.NewPassword); err != nil {
+		return err
+	}
+	user, err := l.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	// 已设置过密码的账号必须校验旧密码
+	if user.PassWord != "" {
+		ok, _, err := l.hasher.Verify(ctx, user.PassWord, req.OldPassword)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperr.ErrWrongPassword
+		}
+	}
+	hashed, err := l.hasher.Hash(ctx, req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := l.repo.UpdatePassword(ctx, userID, hashed); err != nil {
+		return fmt.Errorf("更新密码失败: %w", err)
+	}
+	if err := l.tokenRepo.RevokeUserTokens(ctx, userID, l.jwt.AccessTokenExpiry); err != nil {
+		return err
+	}
+	l.notifier.Notify(ctx, notify.Event{Kind: notify.KindPasswordChanged, User: user})
+	return nil
+}
+
+// ChangeEmail 修改邮箱：验证码须发送到新邮箱，已设置密码的账号还要校验当前密码。
+// 成功后吊销该用户已签发的全部令牌，并向旧邮箱发送带撤销链接的通知
+func (l *LoginLogic) ChangeEmail(ctx context.Context, userID uint, req *request.ChangeEmailRequest) error {
+	user, err := l.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	newEmail := emailaddr.Normalize(req.Email)
+	if newEmail == user.Email {
+		return apperr.ErrValidation.WithFields(apperr.FieldError{Field: "email", Rule: "email_unchanged"})
+	}
+	if user.PassWord != "" {
+		ok, _, err := l.hasher.Verify(ctx, user.PassWord, req.Password)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperr.ErrWrongPassword
+		}
+	}
+	// 先检查邮箱是否可用，避免验证码被白白消耗
+	exists, err := l.repo.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return fmt.Errorf("查询邮箱是否存在失败: %w", err)
+	}
+	if exists {
+		return apperr.ErrEmailTaken
+	}
+	if err := l.VerifyVerificationCode(ctx, newEmail, req.Code); err != nil {
+		return err
+	}
+	if err := l.repo.UpdateEmail(ctx, userID, newEmail); err != nil {
+		return err
+	}
+	if err := l.tokenRepo.RevokeUserTokens(ctx, userID, l.jwt.AccessTokenExpiry); err != nil {
+		return err
+	}
+
+	// 撤销令牌保存失败时仍然通知旧邮箱，只是不带撤销链接
+	data := map[string]any{
+		"NewEmail":   newEmail,
+		"ExpireDays": int(l.notify.EmailRevertTTL.Hours() / 24),
+	}
+	revert, err := l.saveEmailRevert(ctx, repository.EmailRevert{UserID: userID, OldEmail: user.Email, NewEmail: newEmail})
+	if err != nil {
+		l.logger(ctx).Errorf("保存邮箱撤销令牌失败 userID=%d err=%v", userID, err)
+	} else if revert != "" {
+		data["RevertURL"] = revert
+	}
+	l.notifier.Notify(ctx, notify.Event{Kind: notify.KindEmailChanged, User: user, Data: data})
+	return nil
+}
+
+// saveEmailRevert 生成并保存撤销令牌，返回撤销链接；未配置撤销页面地址时返回空字符串
+func (l *LoginLogic) saveEmailRevert(ctx context.Context, rec repository.EmailRevert) (string, error) {
+	if l.notify.EmailRevertURL == "" {
+		return "", nil
+	}
+	b := make([]byte, 32)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	revertToken := base64.RawURLEncoding.EncodeToString(b)
+	if err := l.security.SaveEmailRevert(ctx, revertToken, rec, l.notify.EmailRevertTTL); err != nil {
+		return "", err
+	}
+	link, err := url.Parse(l.notify.EmailRevertURL)
+	if err != nil {
+		return "", err
+	}
+	q := link.Query()
+	q.Set("token", revertToken)
+	link.RawQuery = q.Encode()
+	return link.String(), nil
+}
+
+// RevertEmail 通过旧邮箱收到的链接撤销邮箱修改，改回旧邮箱并吊销全部令牌，返回被撤销的用户 ID。
+// 令牌只能使用一次；旧邮箱在此期间被其他用户注册时返回 ErrEmailTaken
+func (l *LoginLogic) RevertEmail(ctx context.Context, revertToken string) (uint, error) {
+	rec, err := l.security.TakeEmailRevert(ctx, revertToken)
+	if err != nil {
+		return 0, fmt.Errorf("查询邮箱撤销令牌失败: %w", err)
+	}
+	if rec == nil {
+		return 0, apperr.ErrEmailRevertInvalid
+	}
+	if err := l.repo.UpdateEmail(ctx, rec.UserID, rec.OldEmail); err != nil {
+		return rec.UserID, err
+	}
+	l.logger(ctx).Infof("邮箱修改已撤销 userID=%d", rec.UserID)
+	return rec.UserID, l.tokenRepo.RevokeUserTokens(ctx, rec.UserID, l.jwt.AccessTokenExpiry)
+}
+
+// NotificationSettings 查询用户的通知设置
+func (l *LoginLogic) NotificationSettings(ctx context.Context, userID uint) ([]notify.Setting, error) {
+	user, err := l.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return notify.Settings(user.NotifyOptOut), nil
+}
+
+// UpdateNotification 开启或关闭一种通知，关键通知不能关闭
+func (l *LoginLogic) UpdateNotification(ctx context.Context, userID uint, kind string, enabled bool) ([]notify.Setting, error) {
+	critical, ok := notify.Lookup(notify.Kind(kind))
+	if !ok {
+		return nil, apperr.ErrValidation.WithFields(apperr.FieldError{Field: "kind", Rule: "notify_kind"})
+	}
+	if critical && !enabled {
+		return nil, apperr.ErrValidation.WithFields(apperr.FieldError{Field: "kind", Rule: "notify_critical"})
+	}
+	user, err := l.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	optOut := notify.SetEnabled(user.NotifyOptOut, notify.Kind(kind), enabled)
+	if err := l.repo.UpdateNotifyOptOut(ctx, userID, optOut); err != nil {
+		return nil, fmt.Errorf("更新通知设置失败: %w", err)
+	}
+	return notify.Settings(optOut), nil
+}
+
+// UpdateLocale 修改语言偏好
+func (l *LoginLogic) UpdateLocale(ctx context.Context, userID uint, locale string) error {
+	if !i18n.IsSupported(locale) {
+		return apperr.ErrBadRequest.Wrap(fmt.Errorf("不支持的语言: %s", locale))
+	}
+	return l.repo.UpdateLocale(ctx, userID, locale)
+}
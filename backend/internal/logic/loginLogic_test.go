@@ -0,0 +1,278 @@
+package logic_test
+
+import (
+	v1 "blueLock/backend/api/v1"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/notify"
+	"blueLock/backend/internal/pkg/password"
+	"blueLock/backend/internal/request"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "Str0ng-pass-123"
+
+// register 通过验证码注册一个用户
+func register(t *testing.T, env *testEnv, email string) *models.User {
+	t.Helper()
+	ctx := context.Background()
+	if err := env.login.SendVerificationCode(ctx, email, ""); err != nil {
+		t.Fatalf("SendVerificationCode: %v", err)
+	}
+	code, _ := env.mailer.last(t, email).Data["Code"].(string)
+	user, err := env.login.RegisterEmail(ctx, &request.RegisterByVerificationCodeRequest{
+		Email: email, Password: testPassword, Code: code,
+	})
+	if err != nil {
+		t.Fatalf("RegisterEmail: %v", err)
+	}
+	return user
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := register(t, env, "Alice@Example.com")
+	if user.Email != "alice@example.com" {
+		t.Fatalf("注册后邮箱 = %q，期望规范化为小写", user.Email)
+	}
+	if !strings.HasPrefix(user.PassWord, "$argon2id$") {
+		t.Fatalf("密码哈希 = %q，期望 argon2id", user.PassWord)
+	}
+
+	data, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: "ALICE@example.com", Password: testPassword})
+	if err != nil {
+		t.Fatalf("密码登录: %v", err)
+	}
+	if data.UserID != user.ID || data.AccessToken == "" || data.RefreshToken == "" {
+		t.Fatalf("登录结果 = %+v", data)
+	}
+
+	for _, tc := range []struct{ email, password string }{
+		{"alice@example.com", "wrong-password"},
+		{"nobody@example.com", testPassword},
+	} {
+		_, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: tc.email, Password: tc.password})
+		if !errors.Is(err, apperr.ErrInvalidCredentials) {
+			t.Fatalf("LoginByPass(%s, %s) 错误 = %v，期望 ErrInvalidCredentials", tc.email, tc.password, err)
+		}
+	}
+}
+
+func TestLoginByCode(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	register(t, env, "bob@example.com")
+
+	if err := env.login.SendVerificationCode(ctx, "bob@example.com", ""); err != nil {
+		t.Fatal(err)
+	}
+	code, _ := env.mailer.last(t, "bob@example.com").Data["Code"].(string)
+	req := &request.LoginByPassORCode{Email: "bob@example.com", Code: code}
+	if _, err := env.login.LoginByPass(ctx, req); err != nil {
+		t.Fatalf("验证码登录: %v", err)
+	}
+	if _, err := env.login.LoginByPass(ctx, req); !errors.Is(err, apperr.ErrCodeExpired) {
+		t.Fatalf("验证码第二次使用错误 = %v，期望 ErrCodeExpired", err)
+	}
+}
+
+// 旧的 bcrypt 哈希登录成功后升级为当前配置的 argon2id
+func TestLoginUpgradesHash(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	hashed, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Email: "legacy@example.com", PassWord: string(hashed)}
+	if err := env.users.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: user.Email, Password: testPassword}); err != nil {
+		t.Fatalf("bcrypt 哈希登录: %v", err)
+	}
+	stored, err := env.users.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored.PassWord, "$argon2id$") {
+		t.Fatalf("登录后哈希 = %q，期望升级为 argon2id", stored.PassWord)
+	}
+	if _, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: user.Email, Password: testPassword}); err != nil {
+		t.Fatalf("升级后登录: %v", err)
+	}
+}
+
+// 调整 argon2id 参数后，旧参数的哈希在登录成功时按新参数重新计算；密码错误时不修改
+func TestLoginUpgradesArgon2Params(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := register(t, env, "foo@example.com")
+	before, err := env.users.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env.hasher = password.NewHasher(globals.PasswordHashConfig{Memory: 64, Iterations: 2, Parallelism: 1})
+	env.login = newLoginLogic(env)
+	if _, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: user.Email, Password: "wrong password"}); !errors.Is(err, apperr.ErrInvalidCredentials) {
+		t.Fatalf("密码错误: %v", err)
+	}
+	if stored, _ := env.users.GetUserByID(ctx, user.ID); stored.PassWord != before.PassWord {
+		t.Fatal("密码错误时哈希被修改")
+	}
+	if _, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: user.Email, Password: testPassword}); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := env.users.GetUserByID(ctx, user.ID); !strings.Contains(stored.PassWord, "$m=64,t=2,p=1$") {
+		t.Fatalf("登录后哈希 = %q，期望使用新参数", stored.PassWord)
+	}
+}
+
+// 首次登录不通知；换一台设备登录时通知，已登录过的设备不再通知
+func TestNewDeviceNotification(t *testing.T) {
+	env := newTestEnv(t)
+	register(t, env, "carol@example.com")
+	login := func(ua string) {
+		t.Helper()
+		req := &request.LoginByPassORCode{Email: "carol@example.com", Password: testPassword}
+		if _, err := env.login.LoginByPass(withDevice(ua), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	login(chromeLinux)
+	login(chromeLinux)
+	if kinds := env.notifier.kinds(); len(kinds) != 0 {
+		t.Fatalf("同一设备登录发出了通知: %v", kinds)
+	}
+	login(firefoxMacOS)
+	login(firefoxMacOS)
+	if kinds := env.notifier.kinds(); !slices.Equal(kinds, []notify.Kind{notify.KindNewLogin}) {
+		t.Fatalf("通知 = %v，期望一次 new_login", kinds)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := register(t, env, "dave@example.com")
+	if _, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: user.Email, Password: testPassword}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := env.login.ChangePassword(ctx, user.ID, &request.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "An0ther-pass-456"})
+	if !errors.Is(err, apperr.ErrWrongPassword) {
+		t.Fatalf("旧密码错误时错误 = %v，期望 ErrWrongPassword", err)
+	}
+	err = env.login.ChangePassword(ctx, user.ID, &request.ChangePasswordRequest{OldPassword: testPassword, NewPassword: "An0ther-pass-456"})
+	if err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	if revokedAt, _ := env.tokens.GetRevokedAt(ctx, user.ID); revokedAt == 0 {
+		t.Fatal("修改密码后没有吊销令牌")
+	}
+	if stored, _ := env.tokens.GetRefreshToken(ctx, user.ID); stored != "" {
+		t.Fatal("修改密码后刷新令牌仍然有效")
+	}
+	if kinds := env.notifier.kinds(); !slices.Contains(kinds, notify.KindPasswordChanged) {
+		t.Fatalf("通知 = %v，期望包含 password_changed", kinds)
+	}
+	if _, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: user.Email, Password: "An0ther-pass-456"}); err != nil {
+		t.Fatalf("新密码登录: %v", err)
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := register(t, env, "erin@example.com")
+	first, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: user.Email, Password: testPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := env.login.RefreshToken(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("刷新后没有轮换刷新令牌")
+	}
+	third, err := env.login.RefreshToken(ctx, second.RefreshToken)
+	if err != nil {
+		t.Fatalf("用轮换后的令牌刷新: %v", err)
+	}
+
+	// 已轮换的令牌再次出现：拒绝，吊销全部令牌并通知
+	if _, err := env.login.RefreshToken(ctx, first.RefreshToken); !errors.Is(err, apperr.ErrTokenInvalid) {
+		t.Fatalf("重用旧令牌错误 = %v，期望 ErrTokenInvalid", err)
+	}
+	if kinds := env.notifier.kinds(); !slices.Contains(kinds, notify.KindTokenReuse) {
+		t.Fatalf("通知 = %v，期望包含 token_reuse", kinds)
+	}
+	if _, err := env.login.RefreshToken(ctx, third.RefreshToken); err == nil {
+		t.Fatal("检测到重用后，最新的刷新令牌仍然有效")
+	}
+}
+
+// 同一个刷新令牌并发刷新：最多一个请求成功，并且视为重用，成功的一方拿到的令牌也随之失效
+func TestRefreshTokenConcurrentUse(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := register(t, env, "frank@example.com")
+	login, err := env.login.LoginByPass(ctx, &request.LoginByPassORCode{Email: user.Email, Password: testPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 8
+	results := make(chan *v1.LoginResponseData, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if data, err := env.login.RefreshToken(ctx, login.RefreshToken); err == nil {
+				results <- data
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var winners []*v1.LoginResponseData
+	for data := range results {
+		winners = append(winners, data)
+	}
+	if len(winners) > 1 {
+		t.Fatalf("%d 个并发请求用同一个刷新令牌刷新成功", len(winners))
+	}
+	if kinds := env.notifier.kinds(); !slices.Contains(kinds, notify.KindTokenReuse) {
+		t.Fatalf("通知 = %v，期望包含 token_reuse", kinds)
+	}
+	for _, data := range winners {
+		if _, err := env.login.RefreshToken(ctx, data.RefreshToken); err == nil {
+			t.Fatal("并发重用后，胜出请求拿到的刷新令牌仍然有效")
+		}
+		claims, err := env.service.ParseToken(data.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		revokedAt, _ := env.tokens.GetRevokedAt(ctx, user.ID)
+		if claims.IssuedAt.UnixMilli() > revokedAt {
+			t.Fatal("并发重用后，胜出请求拿到的访问令牌仍然有效")
+		}
+	}
+}
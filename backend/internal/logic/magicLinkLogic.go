@@ -0,0 +1,284 @@
+package logic
+
+import (
+	v1 "blueLock/backend/api/v1"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/clientinfo"
+	"blueLock/backend/internal/pkg/emailaddr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/pkg/metrics"
+	"blueLock/backend/internal/pkg/token"
+	"blueLock/backend/internal/repository"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// magicLinkPurpose 链接签名的用途，避免与其他签名链接混用
+const magicLinkPurpose = "magic_link"
+
+const (
+	defaultMagicLinkTTL     = 10 * time.Minute
+	defaultMagicLinkMaxWait = 25 * time.Second
+	magicLinkPollInterval   = time.Second
+)
+
+// MagicLinkLogic 邮件链接登录：向用户邮箱发送一次性的签名链接。
+// 在发起登录的设备上打开链接（带上发起时返回的 nonce）直接完成登录；
+// 在其他设备上打开只能批准这次登录，令牌仍然只签发给持有 nonce 的发起方，转发邮件无法劫持会话
+type MagicLinkLogic struct {
+	userRepo     repository.UserStore
+	links        *repository.MagicLinkRepository
+	loginLogic   *LoginLogic
+	tokenService *token.Service
+	mails        MailQueue
+	cfg          globals.MagicLinkConfig
+	log          *zap.SugaredLogger
+}
+
+// NewMagicLinkLogic 创建并返回一个新的 MagicLinkLogic 实例
+func NewMagicLinkLogic(
+	userRepo repository.UserStore,
+	links *repository.MagicLinkRepository,
+	loginLogic *LoginLogic,
+	tokenService *token.Service,
+	mails MailQueue,
+	cfg globals.MagicLinkConfig,
+	log *zap.SugaredLogger,
+) *MagicLinkLogic {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultMagicLinkTTL
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = defaultMagicLinkMaxWait
+	}
+	return &MagicLinkLogic{
+		userRepo:     userRepo,
+		links:        links,
+		loginLogic:   loginLogic,
+		tokenService: tokenService,
+		mails:        mails,
+		cfg:          cfg,
+		log:          log,
+	}
+}
+
+func (l *MagicLinkLogic) logger(ctx context.Context) *zap.SugaredLogger {
+	return logger.FromContext(ctx, l.log)
+}
+
+// Start 发起邮件链接登录，locale 为空时使用用户保存的语言偏好。
+// 邮箱未注册时不发送邮件，但同样保存一条（永远不会被批准的）请求，邮件也在后台发送，
+// 响应内容、耗时以及之后的轮询结果都与已注册的邮箱一致，不能据此判断邮箱是否注册
+func (l *MagicLinkLogic) Start(ctx context.Context, email, locale string) (*v1.MagicLinkStartData, error) {
+	if l.cfg.URL == "" {
+		return nil, apperr.ErrUnavailable.Wrap(errors.New("未配置邮件链接登录的页面地址 magic_link.url"))
+	}
+	requestID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	data := &v1.MagicLinkStartData{RequestID: requestID, Nonce: nonce, ExpiresIn: int(l.cfg.TTL.Seconds())}
+
+	user, err := l.userRepo.GetUserByEmail(ctx, emailaddr.Normalize(email))
+	if err != nil && !errors.Is(err, apperr.ErrUserNotFound) {
+		return nil, err
+	}
+	var userID uint
+	if user != nil {
+		userID = user.ID
+	}
+
+	info := clientinfo.FromContext(ctx)
+	now := time.Now()
+	err = l.links.Create(ctx, requestID, repository.MagicLink{
+		UserID:    userID,
+		NonceHash: hashNonce(nonce),
+		IP:        info.IP,
+		Device:    info.Device(),
+		CreatedAt: now.Unix(),
+	}, l.cfg.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("保存邮件链接登录请求失败: %w", err)
+	}
+	if user == nil {
+		l.logger(ctx).Debugf("邮件链接登录的邮箱未注册，不发送邮件")
+		return data, nil
+	}
+
+	link, err := url.Parse(l.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	q := link.Query()
+	q.Set("token", l.tokenService.SignLink(magicLinkPurpose, requestID, now.Add(l.cfg.TTL)))
+	link.RawQuery = q.Encode()
+
+	if locale == "" || !i18n.IsSupported(locale) {
+		locale = user.Locale
+	}
+	if !i18n.IsSupported(locale) {
+		locale = i18n.DefaultLocale
+	}
+	unknown := i18n.T(locale, "notify.unknown")
+	mailData := map[string]any{
+		"Link":          link.String(),
+		"ExpireMinutes": int(l.cfg.TTL.Minutes()),
+		"IP":            orDefault(info.IP, unknown),
+		"Device":        orDefault(info.Device(), unknown),
+		"Time":          now.UTC().Format("2006-01-02 15:04:05 UTC"),
+	}
+	l.mails.Deliver(ctx, user.Email, locale, "magic_link", mailData)
+	return data, nil
+}
+
+// Verify 打开邮件中的链接。nonce 与发起时返回的一致（在发起登录的设备上打开）时使用链接并直接登录；
+// 否则不改变任何状态，返回发起方的设备信息，由用户确认后调用 Approve
+func (l *MagicLinkLogic) Verify(ctx context.Context, signed, nonce string) (data *v1.MagicLinkData, err error) {
+	requestID, link, err := l.lookup(ctx, signed)
+	if err != nil {
+		return nil, err
+	}
+	if nonce == "" || !nonceMatches(link.NonceHash, nonce) {
+		return &v1.MagicLinkData{
+			Status: v1.MagicLinkApprovalRequired,
+			Requester: &v1.MagicLinkRequester{
+				IP:        link.IP,
+				Device:    link.Device,
+				CreatedAt: time.Unix(link.CreatedAt, 0),
+			},
+		}, nil
+	}
+
+	defer func() { metrics.Logins.WithLabelValues(metrics.LoginMethodMagic, metrics.Result(err)).Inc() }()
+	ok, err := l.links.ConsumeLink(ctx, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("使用登录链接失败: %w", err)
+	}
+	if !ok {
+		return nil, apperr.ErrMagicLinkInvalid
+	}
+	return l.complete(ctx, requestID, link)
+}
+
+// Approve 在其他设备上批准登录。链接随之失效，发起方下次轮询时完成登录
+func (l *MagicLinkLogic) Approve(ctx context.Context, signed string) (*v1.MagicLinkData, error) {
+	requestID, _, err := l.lookup(ctx, signed)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := l.links.ConsumeLink(ctx, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("使用登录链接失败: %w", err)
+	}
+	if !ok {
+		return nil, apperr.ErrMagicLinkInvalid
+	}
+	if err := l.links.Approve(ctx, requestID, l.cfg.TTL); err != nil {
+		return nil, fmt.Errorf("批准登录失败: %w", err)
+	}
+	return &v1.MagicLinkData{Status: v1.MagicLinkApproved}, nil
+}
+
+// Poll 发起方查询登录结果，最多等待 wait（不超过 magic_link.max_wait）。
+// 已批准时签发令牌，否则返回 pending；请求不存在、已过期或 nonce 不匹配时返回 ErrMagicLinkInvalid
+func (l *MagicLinkLogic) Poll(ctx context.Context, requestID, nonce string, wait time.Duration) (data *v1.MagicLinkData, err error) {
+	link, err := l.links.Get(ctx, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("查询邮件链接登录请求失败: %w", err)
+	}
+	if link == nil || !nonceMatches(link.NonceHash, nonce) {
+		return nil, apperr.ErrMagicLinkInvalid
+	}
+
+	deadline := time.Now().Add(min(wait, l.cfg.MaxWait))
+	for {
+		approved, err := l.links.TakeApproval(ctx, requestID)
+		if err != nil {
+			return nil, fmt.Errorf("查询登录批准状态失败: %w", err)
+		}
+		if approved {
+			defer func() { metrics.Logins.WithLabelValues(metrics.LoginMethodMagic, metrics.Result(err)).Inc() }()
+			return l.complete(ctx, requestID, link)
+		}
+		if !time.Now().Add(magicLinkPollInterval).Before(deadline) {
+			return &v1.MagicLinkData{Status: v1.MagicLinkPending}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(magicLinkPollInterval):
+		}
+	}
+}
+
+// lookup 校验链接签名并查询对应的请求
+func (l *MagicLinkLogic) lookup(ctx context.Context, signed string) (string, *repository.MagicLink, error) {
+	requestID, err := l.tokenService.VerifyLink(magicLinkPurpose, signed)
+	if err != nil {
+		return "", nil, apperr.ErrMagicLinkInvalid.Wrap(err)
+	}
+	link, err := l.links.Get(ctx, requestID)
+	if err != nil {
+		return "", nil, fmt.Errorf("查询邮件链接登录请求失败: %w", err)
+	}
+	if link == nil || link.UserID == 0 {
+		return "", nil, apperr.ErrMagicLinkInvalid
+	}
+	return requestID, link, nil
+}
+
+// complete 删除请求并签发令牌
+func (l *MagicLinkLogic) complete(ctx context.Context, requestID string, link *repository.MagicLink) (*v1.MagicLinkData, error) {
+	if err := l.links.Delete(ctx, requestID); err != nil {
+		l.logger(ctx).Warnf("删除邮件链接登录请求失败 err=%v", err)
+	}
+	user, err := l.userRepo.GetUserByID(ctx, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	login, err := l.loginLogic.IssueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.MagicLinkData{Status: v1.MagicLinkLoggedIn, Login: login}, nil
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+func nonceMatches(hash, nonce string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hashNonce(nonce))) == 1
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,135 @@
+package logic_test
+
+import (
+	v1 "blueLock/backend/api/v1"
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/repository"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newMagicLinkLogic(env *testEnv) *logic.MagicLinkLogic {
+	return logic.NewMagicLinkLogic(env.users, repository.NewMagicLinkRepository(env.store), env.login,
+		env.service, env.mailer, globals.MagicLinkConfig{
+			URL:     "https://example.com/login/magic",
+			TTL:     time.Minute,
+			MaxWait: 2 * time.Second,
+		}, zap.NewNop().Sugar())
+}
+
+// magicToken 取出最近一封登录链接邮件中的 token
+func magicToken(t *testing.T, env *testEnv, email string) string {
+	t.Helper()
+	mail := env.mailer.last(t, email)
+	if mail.Name != "magic_link" {
+		t.Fatalf("最近一封邮件是 %s，不是登录链接", mail.Name)
+	}
+	link, _ := mail.Data["Link"].(string)
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("解析登录链接失败: %v", err)
+	}
+	return u.Query().Get("token")
+}
+
+func TestMagicLinkHidesUnknownEmail(t *testing.T) {
+	env := newTestEnv(t)
+	register(t, env, "known@example.com")
+	magic := newMagicLinkLogic(env)
+	ctx := withDevice(chromeLinux)
+
+	for _, email := range []string{"known@example.com", "unknown@example.com"} {
+		start, err := magic.Start(ctx, email, "")
+		if err != nil {
+			t.Fatalf("%s: Start: %v", email, err)
+		}
+		data, err := magic.Poll(ctx, start.RequestID, start.Nonce, 0)
+		if err != nil {
+			t.Fatalf("%s: Poll: %v", email, err)
+		}
+		if data.Status != v1.MagicLinkPending {
+			t.Fatalf("%s: Poll 状态为 %s，期望 pending", email, data.Status)
+		}
+	}
+
+	// 邮件发送失败也不能影响结果
+	env.mailer.err = errors.New("smtp down")
+	if _, err := magic.Start(ctx, "known@example.com", ""); err != nil {
+		t.Fatalf("邮件发送失败时 Start 返回了错误: %v", err)
+	}
+}
+
+func TestMagicLinkSameDevice(t *testing.T) {
+	env := newTestEnv(t)
+	user := register(t, env, "foo@example.com")
+	magic := newMagicLinkLogic(env)
+	ctx := withDevice(chromeLinux)
+
+	start, err := magic.Start(ctx, "foo@example.com", "")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	signed := magicToken(t, env, "foo@example.com")
+
+	if _, err := magic.Verify(ctx, signed, "wrong-nonce"); err != nil {
+		t.Fatalf("nonce 不匹配时应当要求批准: %v", err)
+	}
+	data, err := magic.Verify(ctx, signed, start.Nonce)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if data.Status != v1.MagicLinkLoggedIn || data.Login == nil || data.Login.UserID != user.ID {
+		t.Fatalf("Verify 结果不正确: %+v", data)
+	}
+	if _, err := magic.Verify(ctx, signed, start.Nonce); !errors.Is(err, apperr.ErrMagicLinkInvalid) {
+		t.Fatalf("链接被重复使用: %v", err)
+	}
+}
+
+func TestMagicLinkOtherDevice(t *testing.T) {
+	env := newTestEnv(t)
+	register(t, env, "foo@example.com")
+	magic := newMagicLinkLogic(env)
+	ctx := withDevice(chromeLinux)
+
+	start, err := magic.Start(ctx, "foo@example.com", "")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	signed := magicToken(t, env, "foo@example.com")
+
+	other := withDevice(firefoxMacOS)
+	data, err := magic.Verify(other, signed, "")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if data.Status != v1.MagicLinkApprovalRequired || data.Requester == nil || data.Requester.IP != "192.0.2.1" {
+		t.Fatalf("其他设备打开链接的结果不正确: %+v", data)
+	}
+	if _, err := magic.Poll(ctx, start.RequestID, "wrong-nonce", 0); !errors.Is(err, apperr.ErrMagicLinkInvalid) {
+		t.Fatalf("nonce 不匹配时轮询应当失败: %v", err)
+	}
+	if data, err = magic.Approve(other, signed); err != nil || data.Status != v1.MagicLinkApproved {
+		t.Fatalf("Approve: %+v %v", data, err)
+	}
+	if _, err := magic.Approve(other, signed); !errors.Is(err, apperr.ErrMagicLinkInvalid) {
+		t.Fatalf("链接被重复批准: %v", err)
+	}
+
+	data, err = magic.Poll(ctx, start.RequestID, start.Nonce, time.Second)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if data.Status != v1.MagicLinkLoggedIn || data.Login == nil {
+		t.Fatalf("批准后轮询没有登录: %+v", data)
+	}
+	if _, err := magic.Poll(ctx, start.RequestID, start.Nonce, 0); !errors.Is(err, apperr.ErrMagicLinkInvalid) {
+		t.Fatalf("登录后请求应当失效: %v", err)
+	}
+}
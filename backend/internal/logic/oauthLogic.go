@@ -0,0 +1,362 @@
+package logic
+
+import (
+	v1 "blueLock/backend/api/v1"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/emailaddr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/kv"
+	"blueLock/backend/internal/repository"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// OAuthActionLogin 第三方登录
+	OAuthActionLogin = "login"
+	// OAuthActionLink 绑定第三方账号
+	OAuthActionLink = "link"
+)
+
+// oauthState 授权过程中暂存在Redis中的状态，回调时一次性取出
+type oauthState struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"` // PKCE code_verifier
+	Nonce    string `json:"nonce"`
+	UserID   uint   `json:"user_id"` // 非0表示绑定流程
+}
+
+// oidcClient 单个提供方的客户端（discovery 结果缓存）
+type oidcClient struct {
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// oidcProvider 已配置的提供方，每个提供方单独加锁，一个提供方 discovery 缓慢不影响其他提供方
+type oidcProvider struct {
+	cfg globals.OAuthProviderConfig
+
+	mu     sync.Mutex
+	client *oidcClient
+}
+
+// OAuthLogic 第三方(OIDC)登录与账号绑定逻辑
+type OAuthLogic struct {
+	userRepo     repository.UserStore
+	identityRepo *repository.IdentityRepository
+	loginLogic   *LoginLogic
+	store        kv.Store
+	cfg          globals.OAuthConfig
+
+	// 各提供方及其 discovery 结果的缓存，构造后不再增删，读取不需要加锁
+	providers map[string]*oidcProvider
+}
+
+// NewOAuthLogic 创建并返回一个新的 OAuthLogic 实例
+func NewOAuthLogic(
+	userRepo repository.UserStore,
+	identityRepo *repository.IdentityRepository,
+	loginLogic *LoginLogic,
+	store kv.Store,
+	cfg globals.OAuthConfig,
+) *OAuthLogic {
+	providers := make(map[string]*oidcProvider, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		providers[p.Name] = &oidcProvider{cfg: p}
+	}
+	return &OAuthLogic{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		loginLogic:   loginLogic,
+		store:        store,
+		cfg:          cfg,
+		providers:    providers,
+	}
+}
+
+// Providers 返回已配置的提供方名称
+func (l *OAuthLogic) Providers() []string {
+	names := make([]string, 0, len(l.cfg.Providers))
+	for _, p := range l.cfg.Providers {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Authorize 生成授权地址，userID 非0时为绑定流程。
+// 返回的 state 须由调用方写入发起授权的浏览器（HttpOnly Cookie），回调时与查询参数中的 state 比对
+func (l *OAuthLogic) Authorize(ctx context.Context, provider string, userID uint) (*v1.OAuthAuthorizeData, error) {
+	client, err := l.getOIDCClient(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	st := oauthState{
+		Provider: provider,
+		Verifier: oauth2.GenerateVerifier(),
+		Nonce:    nonce,
+		UserID:   userID,
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.store.Set(ctx, oauthStateKey(state), string(data), l.cfg.StateExpiry); err != nil {
+		return nil, fmt.Errorf("授权状态存储失败: %w", err)
+	}
+
+	authURL := client.oauth2.AuthCodeURL(
+		state,
+		oauth2.S256ChallengeOption(st.Verifier),
+		oidc.Nonce(nonce),
+	)
+	return &v1.OAuthAuthorizeData{AuthURL: authURL, State: state}, nil
+}
+
+// Callback 处理授权回调：换取令牌、校验 ID Token，然后登录或绑定。
+// browserState 为发起授权时写入浏览器的 state，与回调的 state 不一致说明回调不是由发起授权的浏览器完成的，
+// 例如攻击者诱导受害者打开自己发起的绑定回调，会把受害者的第三方身份绑定到攻击者的账号上
+func (l *OAuthLogic) Callback(ctx context.Context, provider, code, state, browserState string) (*v1.OAuthCallbackData, error) {
+	if code == "" || state == "" {
+		return nil, apperr.ErrBadRequest.Wrap(errors.New("缺少 code 或 state 参数"))
+	}
+	if subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, apperr.ErrOAuthStateInvalid.Wrap(errors.New("state 与发起授权的浏览器不匹配"))
+	}
+	client, err := l.getOIDCClient(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. state 只能使用一次
+	raw, err := l.store.GetDel(ctx, oauthStateKey(state))
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, apperr.ErrOAuthStateInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询授权状态失败: %w", err)
+	}
+	var st oauthState
+	if err := json.Unmarshal([]byte(raw), &st); err != nil {
+		return nil, fmt.Errorf("解析授权状态失败: %w", err)
+	}
+	if st.Provider != provider {
+		return nil, apperr.ErrOAuthStateInvalid.Wrap(errors.New("授权状态与提供方不匹配"))
+	}
+
+	// 2. 使用授权码 + PKCE verifier 换取令牌
+	oauthToken, err := client.oauth2.Exchange(ctx, code, oauth2.VerifierOption(st.Verifier))
+	if err != nil {
+		return nil, apperr.ErrOAuthFailed.Wrap(fmt.Errorf("换取令牌失败: %w", err))
+	}
+	rawIDToken, ok := oauthToken.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, apperr.ErrOAuthFailed.Wrap(errors.New("提供方未返回 id_token"))
+	}
+
+	// 3. 校验 ID Token（签名、issuer、audience、过期时间）和 nonce
+	idToken, err := client.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, apperr.ErrOAuthFailed.Wrap(fmt.Errorf("id_token 校验失败: %w", err))
+	}
+	if idToken.Nonce != st.Nonce {
+		return nil, apperr.ErrOAuthFailed.Wrap(errors.New("id_token nonce 不匹配"))
+	}
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, apperr.ErrOAuthFailed.Wrap(fmt.Errorf("解析 id_token 声明失败: %w", err))
+	}
+	claims.Email = emailaddr.Normalize(claims.Email)
+
+	// 4. 绑定流程
+	if st.UserID != 0 {
+		if err := l.link(ctx, st.UserID, provider, idToken.Subject, claims.Email); err != nil {
+			return nil, err
+		}
+		return &v1.OAuthCallbackData{Action: OAuthActionLink, Provider: provider, UserID: st.UserID}, nil
+	}
+
+	// 5. 登录流程
+	user, err := l.resolveUser(ctx, provider, idToken.Subject, claims.Email, claims.EmailVerified)
+	if err != nil {
+		return nil, err
+	}
+	loginData, err := l.loginLogic.IssueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.OAuthCallbackData{
+		Action:   OAuthActionLogin,
+		Provider: provider,
+		UserID:   user.ID,
+		Login:    loginData,
+	}, nil
+}
+
+// resolveUser 根据外部身份找到本地用户：已绑定直接返回；否则按已验证邮箱关联已有用户或创建新用户
+func (l *OAuthLogic) resolveUser(ctx context.Context, provider, subject, email string, emailVerified bool) (*models.User, error) {
+	identity, err := l.identityRepo.GetBySubject(ctx, provider, subject)
+	if err != nil {
+		return nil, fmt.Errorf("查询绑定关系失败: %w", err)
+	}
+	if identity != nil {
+		return l.userRepo.GetUserByID(ctx, identity.UserID)
+	}
+
+	// 未验证的邮箱不能用来关联账号，否则可以通过第三方伪造邮箱接管他人账号
+	if email == "" || !emailVerified {
+		return nil, apperr.ErrEmailNotVerified
+	}
+
+	user, err := l.userRepo.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, apperr.ErrUserNotFound) {
+		return nil, err
+	}
+	if user == nil {
+		user = &models.User{Email: email}
+		if err := l.userRepo.CreateUser(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.identityRepo.Create(ctx, &models.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// link 将外部身份绑定到指定用户
+func (l *OAuthLogic) link(ctx context.Context, userID uint, provider, subject, email string) error {
+	identity, err := l.identityRepo.GetBySubject(ctx, provider, subject)
+	if err != nil {
+		return fmt.Errorf("查询绑定关系失败: %w", err)
+	}
+	if identity != nil {
+		if identity.UserID == userID {
+			return nil
+		}
+		return apperr.ErrIdentityTaken
+	}
+	identities, err := l.identityRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("查询绑定关系失败: %w", err)
+	}
+	for _, item := range identities {
+		if item.Provider == provider {
+			return apperr.ErrIdentityLinked
+		}
+	}
+	return l.identityRepo.Create(ctx, &models.UserIdentity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	})
+}
+
+// Unlink 解除绑定；没有密码且只剩一个第三方身份时不允许解绑，避免账号无法登录
+func (l *OAuthLogic) Unlink(ctx context.Context, userID uint, provider string) error {
+	user, err := l.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	identities, err := l.identityRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("查询绑定关系失败: %w", err)
+	}
+	if user.PassWord == "" && len(identities) <= 1 {
+		return apperr.ErrLastCredential
+	}
+	rows, err := l.identityRepo.DeleteByProvider(ctx, userID, provider)
+	if err != nil {
+		return fmt.Errorf("解绑失败: %w", err)
+	}
+	if rows == 0 {
+		return apperr.ErrIdentityNotLinked
+	}
+	return nil
+}
+
+// ListIdentities 查询用户已绑定的第三方身份
+func (l *OAuthLogic) ListIdentities(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	return l.identityRepo.ListByUserID(ctx, userID)
+}
+
+// getOIDCClient 获取提供方客户端，首次使用时做 discovery 并缓存，失败时不缓存，下次请求重试
+func (l *OAuthLogic) getOIDCClient(name string) (*oidcClient, error) {
+	p, ok := l.providers[name]
+	if !ok {
+		return nil, apperr.ErrProviderNotSupported
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	// discovery 不使用请求的 context，避免请求取消影响缓存的提供方
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	provider, err := oidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return nil, apperr.ErrUnavailable.Wrap(fmt.Errorf("获取 %s 的 OIDC 配置失败: %w", name, err))
+	}
+
+	scopes := []string{oidc.ScopeOpenID}
+	for _, scope := range p.cfg.Scopes {
+		if scope != oidc.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+	p.client = &oidcClient{
+		oauth2: &oauth2.Config{
+			ClientID:     p.cfg.ClientID,
+			ClientSecret: p.cfg.ClientSecret,
+			RedirectURL:  p.cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID}),
+	}
+	return p.client, nil
+}
+
+func oauthStateKey(state string) string {
+	return fmt.Sprintf("oauth:state:%s", state)
+}
+
+// randomString 生成 URL 安全的随机字符串
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,282 @@
+package logic_test
+
+import (
+	v1 "blueLock/backend/api/v1"
+	"blueLock/backend/internal/logic"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/repository"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const mockClientID = "bluelock-test"
+
+// mockIdentity 模拟的提供方在下一次授权时返回的用户
+type mockIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// mockOIDC 本地模拟的 OIDC 提供方：discovery、JWKS 和令牌端点，支持 PKCE（S256）
+type mockOIDC struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+}
+
+type mockGrant struct {
+	identity  mockIdentity
+	nonce     string
+	challenge string
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockOIDC{t: t, key: key, codes: map[string]mockGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                m.server.URL,
+			"authorization_endpoint":                m.server.URL + "/authorize",
+			"token_endpoint":                        m.server.URL + "/token",
+			"jwks_uri":                              m.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "test", "alg": "RS256", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// authorize 模拟用户在提供方页面同意授权，返回授权码
+func (m *mockOIDC) authorize(authURL string, identity mockIdentity) string {
+	m.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != mockClientID || q.Get("code_challenge_method") != "S256" {
+		m.t.Fatalf("授权地址参数不正确: %s", authURL)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	code := "code-" + identity.Subject + "-" + q.Get("state")[:8]
+	m.codes[code] = mockGrant{identity: identity, nonce: q.Get("nonce"), challenge: q.Get("code_challenge")}
+	return code
+}
+
+func (m *mockOIDC) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	grant, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            grant.identity.Subject,
+		"aud":            mockClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          grant.nonce,
+		"email":          grant.identity.Email,
+		"email_verified": grant.identity.EmailVerified,
+	})
+	idToken.Header["kid"] = "test"
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]any{"access_token": "at", "token_type": "Bearer", "expires_in": 60, "id_token": signed})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type oauthEnv struct {
+	*testEnv
+	idp        *mockOIDC
+	oauth      *logic.OAuthLogic
+	identities *repository.IdentityRepository
+	userRepo   *repository.LoginRepository
+}
+
+// newOAuthEnv 创建使用模拟提供方 "mock" 的 OAuthLogic，extra 为额外配置的提供方
+func newOAuthEnv(t *testing.T, extra ...globals.OAuthProviderConfig) *oauthEnv {
+	t.Helper()
+	env := &oauthEnv{testEnv: newTestEnv(t), idp: newMockOIDC(t)}
+	db := openSQLite(t)
+	env.identities = repository.NewIdentityRepository(db)
+	env.userRepo = repository.NewLoginRepository(db)
+	providers := append([]globals.OAuthProviderConfig{{
+		Name:        "mock",
+		Issuer:      env.idp.server.URL,
+		ClientID:    mockClientID,
+		RedirectURL: "http://localhost/oauth/mock/callback",
+		Scopes:      []string{"email"},
+	}}, extra...)
+	env.oauth = logic.NewOAuthLogic(env.userRepo, env.identities, env.login, env.store,
+		globals.OAuthConfig{StateExpiry: time.Minute, Providers: providers})
+	return env
+}
+
+// flow 走完一次授权：发起、用户在提供方同意、浏览器带着 browserState 回调
+func (e *oauthEnv) flow(t *testing.T, userID uint, identity mockIdentity, browserState func(state string) string) (*v1.OAuthCallbackData, error) {
+	t.Helper()
+	ctx := context.Background()
+	auth, err := e.oauth.Authorize(ctx, "mock", userID)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	code := e.idp.authorize(auth.AuthURL, identity)
+	return e.oauth.Callback(ctx, "mock", code, auth.State, browserState(auth.State))
+}
+
+func sameBrowser(state string) string { return state }
+
+func TestOAuthLoginCreatesAndReusesUser(t *testing.T) {
+	env := newOAuthEnv(t)
+	ctx := context.Background()
+	identity := mockIdentity{Subject: "sub-1", Email: "Erin@Example.com", EmailVerified: true}
+
+	if _, err := env.flow(t, 0, identity, sameBrowser); err != nil {
+		t.Fatalf("首次第三方登录: %v", err)
+	}
+	user, err := env.userRepo.GetUserByEmail(ctx, "erin@example.com")
+	if err != nil {
+		t.Fatalf("首次登录没有创建用户: %v", err)
+	}
+
+	auth, err := env.oauth.Authorize(ctx, "mock", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := env.idp.authorize(auth.AuthURL, identity)
+	data, err := env.oauth.Callback(ctx, "mock", code, auth.State, auth.State)
+	if err != nil {
+		t.Fatalf("再次第三方登录: %v", err)
+	}
+	if data.Action != logic.OAuthActionLogin || data.UserID != user.ID || data.Login == nil || data.Login.AccessToken == "" {
+		t.Fatalf("再次登录结果 = %+v，期望登录到用户 %d", data, user.ID)
+	}
+	// state 只能使用一次
+	if _, err := env.oauth.Callback(ctx, "mock", code, auth.State, auth.State); !errors.Is(err, apperr.ErrOAuthStateInvalid) {
+		t.Fatalf("重复回调错误 = %v，期望 ErrOAuthStateInvalid", err)
+	}
+}
+
+func TestOAuthRejectsUnverifiedEmail(t *testing.T) {
+	env := newOAuthEnv(t)
+	_, err := env.flow(t, 0, mockIdentity{Subject: "sub-2", Email: "frank@example.com"}, sameBrowser)
+	if !errors.Is(err, apperr.ErrEmailNotVerified) {
+		t.Fatalf("未验证邮箱登录错误 = %v，期望 ErrEmailNotVerified", err)
+	}
+}
+
+// 攻击者发起绑定流程，诱导受害者的浏览器完成回调：受害者的浏览器没有攻击者的 state Cookie，回调被拒绝
+func TestOAuthLinkBoundToBrowser(t *testing.T) {
+	env := newOAuthEnv(t)
+	ctx := context.Background()
+	attacker := register(t, env.testEnv, "mallory@example.com")
+	victim := mockIdentity{Subject: "victim-sub", Email: "victim@example.com", EmailVerified: true}
+
+	for name, browserState := range map[string]func(string) string{
+		"no cookie":    func(string) string { return "" },
+		"other cookie": func(string) string { return "some-other-state" },
+	} {
+		if _, err := env.flow(t, attacker.ID, victim, browserState); !errors.Is(err, apperr.ErrOAuthStateInvalid) {
+			t.Fatalf("%s: 错误 = %v，期望 ErrOAuthStateInvalid", name, err)
+		}
+	}
+	identity, err := env.identities.GetBySubject(ctx, "mock", victim.Subject)
+	if err != nil || identity != nil {
+		t.Fatalf("state 不匹配时仍然绑定了身份: %+v, %v", identity, err)
+	}
+
+	// 发起绑定的浏览器自己完成回调时正常绑定
+	if _, err := env.flow(t, attacker.ID, mockIdentity{Subject: "own-sub", Email: "m@example.com", EmailVerified: true}, sameBrowser); err != nil {
+		t.Fatalf("同一浏览器绑定: %v", err)
+	}
+	identity, err = env.identities.GetBySubject(ctx, "mock", "own-sub")
+	if err != nil || identity == nil || identity.UserID != attacker.ID {
+		t.Fatalf("绑定结果 = %+v, %v", identity, err)
+	}
+}
+
+// 一个提供方 discovery 卡住时不影响其他提供方
+func TestOAuthDiscoveryPerProvider(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		http.NotFound(w, r)
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	env := newOAuthEnv(t, globals.OAuthProviderConfig{Name: "slow", Issuer: slow.URL, ClientID: "x"})
+	ctx := context.Background()
+	go func() { _, _ = env.oauth.Authorize(ctx, "slow", 0) }()
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := env.oauth.Authorize(ctx, "mock", 0)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Authorize(mock): %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("一个提供方的 discovery 阻塞了其他提供方")
+	}
+
+	if _, err := env.oauth.Authorize(ctx, "unknown", 0); !errors.Is(err, apperr.ErrProviderNotSupported) {
+		t.Fatalf("未配置的提供方错误 = %v", err)
+	}
+}
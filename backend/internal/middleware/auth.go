@@ -1,36 +1,51 @@
 package middleware
 
 import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/logger"
 	"blueLock/backend/internal/pkg/token"
+	"blueLock/backend/internal/repository"
 	"github.com/gin-gonic/gin"
 	"strings"
 )
 
 // AuthMiddleware 认证中间件
-func AuthMiddleware(tokenService *token.Service) gin.HandlerFunc {
+func AuthMiddleware(tokenService *token.Service, tokenRepo repository.TokenStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 提取token
 		tokenString := extractToken(c)
 		if tokenString == "" {
-			sendAuthError(c, "未提供认证信息")
+			abortWithError(c, apperr.ErrUnauthorized)
 			return
 		}
 
 		// 解析token
 		claims, err := tokenService.ParseToken(tokenString)
 		if err != nil {
-			sendAuthError(c, "令牌无效或已过期")
+			abortWithError(c, apperr.ErrTokenInvalid.Wrap(err))
 			return
 		}
 
 		// 必须是访问令牌
 		if !token.IsAccessToken(claims) {
-			sendAuthError(c, "无效的令牌类型")
+			abortWithError(c, apperr.ErrTokenInvalid)
 			return
 		}
 
-		// 设置用户id到上下文
+		// 被强制下线之前（含同一毫秒内）签发的令牌不再有效
+		revokedAt, err := tokenRepo.GetRevokedAt(c, uint(claims.UserID))
+		if err != nil {
+			abortWithError(c, apperr.ErrUnavailable.Wrap(err))
+			return
+		}
+		if token.IsRevoked(claims, revokedAt) {
+			abortWithError(c, apperr.ErrTokenRevoked)
+			return
+		}
+
+		// 设置用户id到上下文，日志器同时带上 user_id
 		c.Set("user_id", claims.UserID)
+		c.Request = c.Request.WithContext(logger.With(c.Request.Context(), "user_id", claims.UserID))
 		c.Next()
 	}
 }
@@ -53,12 +68,8 @@ func extractToken(c *gin.Context) string {
 	return token
 }
 
-// sendAuthError 发送认证错误响应
-func sendAuthError(c *gin.Context, msg string) {
-	c.JSON(401, gin.H{
-		"code":    401,
-		"message": msg,
-		"data":    nil,
-	})
+// abortWithError 终止请求，交给 ErrorHandler 输出错误响应
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
 	c.Abort()
 }
@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"blueLock/backend/internal/middleware"
+	"blueLock/backend/internal/pkg/token"
+	"blueLock/backend/internal/repository/memory"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestAuthMiddlewareRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := token.NewService(token.Config{
+		SecretKey:          "test-secret-key-test-secret-key-0123",
+		AccessTokenExpiry:  time.Minute,
+		RefreshTokenExpiry: time.Hour,
+	})
+	tokens := memory.NewTokenRepository(nil)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(zap.NewNop().Sugar()))
+	r.GET("/me", middleware.AuthMiddleware(service, tokens), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	status := func(accessToken string) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	before, err := service.GenerateAccessToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := status(before); code != http.StatusNoContent {
+		t.Fatalf("吊销前状态码 = %d", code)
+	}
+	// 紧接着吊销：签发与吊销通常落在同一秒内，令牌仍须失效
+	if err := tokens.RevokeUserTokens(context.Background(), 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if code := status(before); code != http.StatusUnauthorized {
+		t.Fatalf("吊销后状态码 = %d，期望 401", code)
+	}
+
+	// 吊销之后重新签发的令牌有效
+	time.Sleep(2 * time.Millisecond)
+	after, err := service.GenerateAccessToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := status(after); code != http.StatusNoContent {
+		t.Fatalf("吊销后重新签发的令牌状态码 = %d，期望 204", code)
+	}
+	if code := status("not-a-token"); code != http.StatusUnauthorized {
+		t.Fatalf("无效令牌状态码 = %d", code)
+	}
+}
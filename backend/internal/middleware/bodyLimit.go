@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// BodyLimit 限制请求体大小。Content-Length 已超过时直接返回 413；否则在读取超过 limit 时出错，
+// 由错误处理中间件转换为 413。路由上可以再套一层更小的限制，但不能放宽外层的限制
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			abortWithError(c, apperr.ErrBodyTooLarge)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/challenge"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 人机校验相关的请求头和响应头
+const (
+	HeaderChallengeID       = "X-Challenge-Id"
+	HeaderChallengeSolution = "X-Challenge-Solution"
+	HeaderChallengeTypes    = "X-Challenge-Types" // 需要校验时返回可用的校验方式，逗号分隔
+)
+
+// Challenge 人机校验闸门，route 为 challenge.routes 中的路由名。按路由策略判断本次请求是否需要校验，
+// 需要时从请求头读取题目 ID 和解答，缺失时返回 428（challenge_required），错误时返回 428（challenge_failed）
+func Challenge(gate *challenge.Gate, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		required, err := gate.Required(c, route, c.ClientIP())
+		if err != nil {
+			abortWithError(c, apperr.ErrUnavailable.Wrap(err))
+			return
+		}
+		if !required {
+			c.Next()
+			return
+		}
+		id := c.GetHeader(HeaderChallengeID)
+		solution := c.GetHeader(HeaderChallengeSolution)
+		if id == "" || solution == "" {
+			c.Header(HeaderChallengeTypes, strings.Join(gate.Types(), ","))
+			abortWithError(c, apperr.ErrChallengeRequired)
+			return
+		}
+		if err := gate.Verify(c, id, solution); err != nil {
+			c.Header(HeaderChallengeTypes, strings.Join(gate.Types(), ","))
+			abortWithError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"blueLock/backend/internal/pkg/clientinfo"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfo 把请求方 IP 和 User-Agent 放入 context，logic 层通过 clientinfo.FromContext 读取
+func ClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := clientinfo.NewContext(c.Request.Context(), clientinfo.Info{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
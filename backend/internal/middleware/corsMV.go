@@ -3,19 +3,38 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"sync/atomic"
 )
 
+// CorsPolicy 跨域白名单，可以在运行中替换（配置热更新）
+type CorsPolicy struct {
+	allowedOrigins atomic.Pointer[map[string]bool]
+}
+
+// NewCorsPolicy 创建跨域白名单
+func NewCorsPolicy(origins []string) *CorsPolicy {
+	p := &CorsPolicy{}
+	p.SetAllowedOrigins(origins)
+	return p
+}
+
+// SetAllowedOrigins 替换允许跨域访问的前端地址
+func (p *CorsPolicy) SetAllowedOrigins(origins []string) {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+	p.allowedOrigins.Store(&allowed)
+}
+
 // CorsMiddleware 跨域中间件
-func CorsMiddleware() gin.HandlerFunc {
+func CorsMiddleware(policy *CorsPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		method := c.Request.Method
 		origin := c.Request.Header.Get("Origin")
 
 		// 允许前端的域名列表
-		allowedOrigins := map[string]bool{
-			"http://127.0.0.1:7000": true,
-			"http://localhost:7000": true,
-		}
+		allowedOrigins := *policy.allowedOrigins.Load()
 
 		// 判断 origin 是否在允许列表内
 		if allowedOrigins[origin] {
@@ -25,11 +44,16 @@ func CorsMiddleware() gin.HandlerFunc {
 				"Access-Control-Allow-Methods",
 				"POST, GET, OPTIONS, PUT, DELETE, UPDATE, PATCH",
 			)
+			// 允许携带的请求头，人机校验的解答通过自定义请求头提交
+			c.Header(
+				"Access-Control-Allow-Headers",
+				"Authorization, Content-Type, Accept-Language, "+HeaderChallengeID+", "+HeaderChallengeSolution,
+			)
 			// 设置可以暴露出来的响应头
 			c.Header(
 				"Access-Control-Expose-Headers",
 				"Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, "+
-					"Cache-Control, Content-Language, Content-Type, X-Csrf-Token",
+					"Cache-Control, Content-Language, Content-Type, X-Csrf-Token, "+HeaderChallengeTypes,
 			)
 			// 允许跨域请求携带 Cookie
 			c.Header("Access-Control-Allow-Credentials", "true")
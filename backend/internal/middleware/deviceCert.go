@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceCertMiddleware 设备网关认证：要求 TLS 握手时提供了经客户端 CA 校验的证书，
+// 证书 Subject 的 CN 即设备序列号，对应的设备必须已绑定。设备写入上下文的 device 中
+func DeviceCertMiddleware(deviceRepo *repository.DeviceRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 只认 TLS 层校验过的证书链；TLS 在反向代理终止、未配置 client_ca_file 或客户端没带证书时为空
+		state := c.Request.TLS
+		if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+			abortWithError(c, apperr.ErrClientCert)
+			return
+		}
+		leaf := state.VerifiedChains[0][0]
+		serial := strings.ToUpper(strings.TrimSpace(leaf.Subject.CommonName))
+		if serial == "" {
+			abortWithError(c, apperr.ErrClientCert.Wrap(errors.New("证书 Subject 中没有 CN")))
+			return
+		}
+
+		device, err := deviceRepo.GetBySerial(c, serial)
+		if err != nil {
+			abortWithError(c, fmt.Errorf("查询设备失败: %w", err))
+			return
+		}
+		if device == nil {
+			abortWithError(c, apperr.ErrDeviceUnknown.Wrap(fmt.Errorf("serial=%s", serial)))
+			return
+		}
+
+		c.Set("device", device)
+		c.Request = c.Request.WithContext(logger.With(c.Request.Context(), "device_serial", device.Serial))
+		c.Next()
+	}
+}
@@ -0,0 +1,149 @@
+package middleware_test
+
+import (
+	"blueLock/backend/internal/middleware"
+	"blueLock/backend/internal/migrations"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/database"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/migrate"
+	"blueLock/backend/internal/repository"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm/logger"
+)
+
+// testCA 测试用的客户端 CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test device CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// issue 签发 CN 为 serial 的客户端证书
+func (ca *testCA) issue(t *testing.T, serial string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: serial},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestDeviceCertMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.Open(globals.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"},
+		logger.Default.LogMode(logger.Silent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.New(db, migrations.All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	devices := repository.NewDeviceRepository(db)
+	if err := devices.Create(context.Background(), &models.Device{UserID: 1, Serial: "BL-0001"}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(zap.NewNop().Sugar()))
+	r.GET("/gateway/whoami", middleware.DeviceCertMiddleware(devices), func(c *gin.Context) {
+		value, _ := c.Get("device")
+		c.String(http.StatusOK, value.(*models.Device).Serial)
+	})
+
+	ca := newTestCA(t)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	srv := httptest.NewUnstartedServer(r)
+	srv.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: pool}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	get := func(certs ...tls.Certificate) (int, string) {
+		t.Helper()
+		client := srv.Client()
+		transport := client.Transport.(*http.Transport).Clone()
+		transport.TLSClientConfig.Certificates = certs
+		client.Transport = transport
+		resp, err := client.Get(srv.URL + "/gateway/whoami")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body [64]byte
+		n, _ := resp.Body.Read(body[:])
+		return resp.StatusCode, string(body[:n])
+	}
+
+	if code, _ := get(); code != http.StatusUnauthorized {
+		t.Fatalf("不带证书: %d，期望 401", code)
+	}
+	if code, _ := get(ca.issue(t, "BL-9999")); code != http.StatusForbidden {
+		t.Fatalf("未绑定设备的证书: %d，期望 403", code)
+	}
+	if code, body := get(ca.issue(t, "bl-0001")); code != http.StatusOK || body != "BL-0001" {
+		t.Fatalf("已绑定设备的证书: %d %q", code, body)
+	}
+
+	// 不经过 TLS 的请求（例如在反向代理终止 TLS）一律拒绝
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/gateway/whoami", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("明文请求: %d，期望 401", w.Code)
+	}
+}
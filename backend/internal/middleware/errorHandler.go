@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/pkg/validate"
+	"blueLock/backend/internal/response"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+// ErrorHandler 统一错误处理中间件：处理器通过 c.Error 上报错误，这里转换为 HTTP 状态码、业务状态码和提示文案
+func ErrorHandler(log *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		// 没有错误，或处理器已经自行写了响应
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		// 请求体超过 BodyLimit 时，处理器通常把读取错误包装成参数错误，这里统一改为 413
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = apperr.ErrBodyTooLarge.Wrap(err)
+		}
+		// 参数绑定的校验错误转换为带字段错误的 ErrValidation
+		if fields, ok := validate.Fields(err); ok {
+			err = apperr.ErrValidation.Wrap(err).WithFields(fields...)
+		}
+		appErr := apperr.From(err)
+		if appErr.Status >= http.StatusInternalServerError {
+			logger.FromContext(c.Request.Context(), log).Errorf("请求处理失败 %s %s: %v", c.Request.Method, c.FullPath(), err)
+		}
+		locale := i18n.FromGin(c)
+		message, ok := i18n.Lookup(locale, appErr.MessageID())
+		if !ok {
+			message = appErr.Message
+		}
+		c.JSON(appErr.Status, response.ErrorResponse{
+			Code:    appErr.Code,
+			Message: message,
+			Error:   appErr.Key,
+			Fields:  fieldErrors(locale, appErr.Fields()),
+		})
+	}
+}
+
+// fieldErrors 翻译字段错误，文案中的 {param} 替换为规则参数，没有对应文案的规则使用通用提示
+func fieldErrors(locale string, fields []apperr.FieldError) []response.FieldError {
+	if len(fields) == 0 {
+		return nil
+	}
+	out := make([]response.FieldError, 0, len(fields))
+	for _, f := range fields {
+		message, ok := i18n.Lookup(locale, "validation."+f.Rule)
+		if !ok {
+			message = i18n.T(locale, "validation.invalid")
+		}
+		out = append(out, response.FieldError{
+			Field:   f.Field,
+			Rule:    f.Rule,
+			Param:   f.Param,
+			Message: strings.ReplaceAll(message, "{param}", f.Param),
+		})
+	}
+	return out
+}
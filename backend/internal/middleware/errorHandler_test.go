@@ -0,0 +1,95 @@
+package middleware_test
+
+import (
+	"blueLock/backend/internal/middleware"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(i18n.Middleware(), middleware.ErrorHandler(zap.NewNop().Sugar()))
+	r.GET("/credentials", func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("登录: %w", apperr.ErrInvalidCredentials))
+	})
+	r.GET("/internal", func(c *gin.Context) {
+		_ = c.Error(errors.New("dial tcp: connection refused"))
+	})
+	r.POST("/bind", func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(err)
+		}
+	})
+	r.POST("/body", middleware.BodyLimit(8), func(c *gin.Context) {
+		var req map[string]any
+		if err := c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(apperr.ErrBadRequest.Wrap(err))
+		}
+	})
+	r.GET("/written", func(c *gin.Context) {
+		c.String(http.StatusAccepted, "ok")
+		_ = c.Error(apperr.ErrInternal)
+	})
+
+	do := func(method, path, lang, body string) (int, response.ErrorResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if lang != "" {
+			req.Header.Set("Accept-Language", lang)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp response.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: 解析响应失败: %v %s", path, err, w.Body.String())
+		}
+		return w.Code, resp
+	}
+
+	code, resp := do(http.MethodGet, "/credentials", "", "")
+	if code != http.StatusUnauthorized || resp.Code != apperr.ErrInvalidCredentials.Code ||
+		resp.Error != "invalid_credentials" || resp.Message != "邮箱或密码错误" {
+		t.Fatalf("领域错误: %d %+v", code, resp)
+	}
+	if _, resp = do(http.MethodGet, "/credentials", "en", ""); resp.Message != "Incorrect email or password" {
+		t.Fatalf("英文文案: %q", resp.Message)
+	}
+
+	// 未知错误的细节不能返回给客户端
+	code, resp = do(http.MethodGet, "/internal", "", "")
+	if code != http.StatusInternalServerError || resp.Error != "internal_error" || strings.Contains(resp.Message, "dial") {
+		t.Fatalf("未知错误: %d %+v", code, resp)
+	}
+
+	code, resp = do(http.MethodPost, "/bind", "", `{}`)
+	if code != http.StatusBadRequest || resp.Error != "validation_failed" || len(resp.Fields) != 1 ||
+		resp.Fields[0].Rule != "required" || resp.Fields[0].Message == "" {
+		t.Fatalf("校验错误: %d %+v", code, resp)
+	}
+
+	code, resp = do(http.MethodPost, "/body", "", `{"a":"0123456789"}`)
+	if code != http.StatusRequestEntityTooLarge || resp.Error != "body_too_large" {
+		t.Fatalf("请求体过大: %d %+v", code, resp)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/written", nil))
+	if w.Code != http.StatusAccepted || w.Body.String() != "ok" {
+		t.Fatalf("处理器已写响应时不应覆盖: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/repository"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 权限校验中间件，必须放在 AuthMiddleware 之后；要求用户拥有全部 perms
+func RequirePermission(roleRepo repository.PermissionStore, perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			abortWithError(c, apperr.ErrUnauthorized)
+			return
+		}
+
+		granted, err := roleRepo.GetPermissionsByUserID(c, uint(userID.(uint64)))
+		if err != nil {
+			abortWithError(c, fmt.Errorf("查询用户权限失败: %w", err))
+			return
+		}
+		owned := make(map[string]bool, len(granted))
+		for _, p := range granted {
+			owned[p] = true
+		}
+		for _, p := range perms {
+			if !owned[p] {
+				abortWithError(c, apperr.ErrForbidden)
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"blueLock/backend/internal/middleware"
+	"blueLock/backend/internal/repository/memory"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// failingPermissions 查询权限总是失败
+type failingPermissions struct{}
+
+func (failingPermissions) GetPermissionsByUserID(context.Context, uint) ([]string, error) {
+	return nil, errors.New("db down")
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	roles := memory.NewRoleRepository()
+	if err := roles.EnsureRole(ctx, "admin", []string{"user:read", "user:write"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := roles.EnsureRole(ctx, "viewer", []string{"user:read"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := roles.AssignRole(ctx, 1, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := roles.AssignRole(ctx, 2, "viewer"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(zap.NewNop().Sugar()))
+	// 代替 AuthMiddleware：请求头带了用户 id 时写入上下文
+	r.Use(func(c *gin.Context) {
+		switch c.GetHeader("X-User") {
+		case "1":
+			c.Set("user_id", uint64(1))
+		case "2":
+			c.Set("user_id", uint64(2))
+		}
+	})
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r.GET("/users", middleware.RequirePermission(roles, "user:read"), ok)
+	r.DELETE("/users", middleware.RequirePermission(roles, "user:read", "user:write"), ok)
+	r.GET("/broken", middleware.RequirePermission(failingPermissions{}, "user:read"), ok)
+
+	cases := []struct {
+		method, path, user string
+		want               int
+	}{
+		{http.MethodGet, "/users", "", http.StatusUnauthorized},
+		{http.MethodGet, "/users", "1", http.StatusNoContent},
+		{http.MethodGet, "/users", "2", http.StatusNoContent},
+		{http.MethodDelete, "/users", "1", http.StatusNoContent},
+		{http.MethodDelete, "/users", "2", http.StatusForbidden},
+		{http.MethodGet, "/broken", "1", http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.user != "" {
+			req.Header.Set("X-User", tc.user)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s %s（用户 %q）状态码 = %d，期望 %d", tc.method, tc.path, tc.user, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/pkg/requestid"
+	"blueLock/backend/internal/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// RequestLogger 分配请求 ID（合法时沿用请求头中的 X-Request-ID），把带请求 ID、路由、客户端 IP 和链路 ID 的子日志器放入 context，
+// 请求结束后写一行访问日志。需放在 Tracing 之后，才能取到本次请求的 trace_id
+func RequestLogger(log *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		c.Header(requestid.Header, id)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := requestid.NewContext(c.Request.Context(), id)
+		reqLog := tracing.Logger(ctx, log).With(
+			"request_id", id,
+			"route", route,
+			"client_ip", c.ClientIP(),
+		)
+		c.Request = c.Request.WithContext(logger.NewContext(ctx, reqLog))
+
+		c.Next()
+
+		// 认证中间件可能已经在 context 中换成了带 user_id 的日志器
+		accessLog := logger.FromContext(c.Request.Context(), reqLog).With(
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"bytes", c.Writer.Size(),
+			"user_agent", c.Request.UserAgent(),
+		)
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			accessLog.Error("access")
+		} else {
+			accessLog.Info("access")
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package middleware_test
+
+import (
+	"blueLock/backend/internal/middleware"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/pkg/requestid"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.DebugLevel)
+	r := gin.New()
+	r.Use(middleware.RequestLogger(zap.New(core).Sugar()))
+	r.GET("/ping/:id", func(c *gin.Context) {
+		// 处理器取到的日志器和请求 ID 与响应头一致
+		logger.FromContext(c.Request.Context(), zap.NewNop().Sugar()).Info("handler")
+		c.String(http.StatusOK, requestid.FromContext(c.Request.Context()))
+	})
+
+	get := func(id string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/ping/1", nil)
+		if id != "" {
+			req.Header.Set(requestid.Header, id)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 合法的请求 ID 原样沿用
+	w := get("gateway-42")
+	if got := w.Header().Get(requestid.Header); got != "gateway-42" || w.Body.String() != "gateway-42" {
+		t.Fatalf("沿用请求 ID: 响应头 %q，处理器 %q", got, w.Body.String())
+	}
+	entries := logs.TakeAll()
+	if len(entries) != 2 || entries[0].Message != "handler" || entries[1].Message != "access" {
+		t.Fatalf("日志条数或顺序不正确: %+v", entries)
+	}
+	for _, e := range entries {
+		fields := e.ContextMap()
+		if fields["request_id"] != "gateway-42" || fields["route"] != "/ping/:id" {
+			t.Fatalf("%s 日志缺少请求字段: %v", e.Message, fields)
+		}
+	}
+	if status := entries[1].ContextMap()["status"]; status != int64(http.StatusOK) {
+		t.Fatalf("访问日志的 status = %v", status)
+	}
+
+	// 不合法的请求 ID 被替换，避免日志注入
+	w = get("bad id\nfake=1")
+	if got := w.Header().Get(requestid.Header); got == "bad id\nfake=1" || !requestid.Valid(got) || w.Body.String() != got {
+		t.Fatalf("不合法的请求 ID 没有被替换: %q", got)
+	}
+	logs.TakeAll()
+
+	// 没有请求 ID 时生成
+	if got := get("").Header().Get(requestid.Header); !requestid.Valid(got) {
+		t.Fatalf("没有生成请求 ID: %q", got)
+	}
+}
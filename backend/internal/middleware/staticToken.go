@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaticToken 要求请求头 Authorization: Bearer <token> 与配置的固定令牌一致，用于指标等给内部系统调用的接口
+func StaticToken(token string) gin.HandlerFunc {
+	want := []byte(token)
+	return func(c *gin.Context) {
+		got := extractToken(c)
+		if got == "" || subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			abortWithError(c, apperr.ErrUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
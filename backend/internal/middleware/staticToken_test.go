@@ -0,0 +1,39 @@
+package middleware_test
+
+import (
+	"blueLock/backend/internal/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestStaticToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(zap.NewNop().Sugar()))
+	r.GET("/metrics", middleware.StaticToken("metrics-token-0123456789"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	cases := map[string]int{
+		"":                                http.StatusUnauthorized,
+		"Bearer wrong":                    http.StatusUnauthorized,
+		"Basic metrics-token-0123456789":  http.StatusUnauthorized,
+		"Bearer metrics-token-0123456789": http.StatusNoContent,
+		"bearer metrics-token-0123456789": http.StatusNoContent,
+	}
+	for header, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("Authorization %q: %d，期望 %d", header, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"blueLock/backend/internal/pkg/migrate"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// baseline 初始表结构。此前的版本在启动时 AutoMigrate，已有的库执行该迁移只会补齐缺失的列和索引
+var baseline = migrate.Migration{
+	Version: 2026101901,
+	Name:    "baseline",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(baselineModels()...)
+	},
+	Down: func(tx *gorm.DB) error {
+		// 多对多关联表没有对应的模型，按表名删除
+		tables := []any{joinTable(tx, "role_permissions"), joinTable(tx, "user_roles")}
+		return tx.Migrator().DropTable(append(tables, baselineModels()...)...)
+	},
+}
+
+// baselineModels 基线时的表结构快照，之后修改 models 不影响该迁移。
+// 类型在函数内定义，类型名与 models 中一致，表名和索引名按同样的命名策略生成
+func baselineModels() []any {
+	type Permission struct {
+		gorm.Model
+		Name string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	}
+	type Role struct {
+		gorm.Model
+		Name        string       `gorm:"type:varchar(64);not null;uniqueIndex"`
+		Description string       `gorm:"size:255"`
+		Permissions []Permission `gorm:"many2many:role_permissions"`
+	}
+	type User struct {
+		gorm.Model
+		Email    string `gorm:"type:varchar(255);not null;uniqueIndex"`
+		PassWord string `gorm:"size:100"`
+		Disabled bool   `gorm:"not null;default:false"`
+		Locale   string `gorm:"type:varchar(16)"`
+		Roles    []Role `gorm:"many2many:user_roles"`
+	}
+	type UserIdentity struct {
+		gorm.Model
+		UserID   uint   `gorm:"not null;index"`
+		Provider string `gorm:"type:varchar(64);not null;uniqueIndex:idx_provider_subject"`
+		Subject  string `gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_subject"`
+		Email    string `gorm:"type:varchar(255)"`
+	}
+	type Device struct {
+		gorm.Model
+		UserID     uint   `gorm:"not null;index"`
+		Serial     string `gorm:"type:varchar(64);not null;uniqueIndex"`
+		Name       string `gorm:"size:64"`
+		LastSeenAt *time.Time
+	}
+	type AuditLog struct {
+		ID         uint      `gorm:"primarykey"`
+		CreatedAt  time.Time `gorm:"index"`
+		ActorID    uint      `gorm:"index"`
+		Action     string    `gorm:"type:varchar(64);not null;index"`
+		TargetType string    `gorm:"type:varchar(32)"`
+		TargetID   string    `gorm:"type:varchar(64);index"`
+		IP         string    `gorm:"type:varchar(64)"`
+		UserAgent  string    `gorm:"type:varchar(255)"`
+		RequestID  string    `gorm:"type:varchar(64)"`
+		Result     string    `gorm:"type:varchar(16);not null"`
+		Details    string    `gorm:"type:text"`
+		PrevHash   string    `gorm:"type:char(64);not null"`
+		Hash       string    `gorm:"type:char(64);not null"`
+	}
+	return []any{&User{}, &UserIdentity{}, &Role{}, &Permission{}, &Device{}, &AuditLog{}}
+}
+
+// joinTable 按命名策略（表前缀）生成关联表名
+func joinTable(tx *gorm.DB, name string) string {
+	return tx.NamingStrategy.JoinTableName(name)
+}
+
+// usersTable 按命名策略（表前缀）生成用户表名
+func usersTable(tx *gorm.DB) string {
+	return tx.NamingStrategy.TableName("User")
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"blueLock/backend/internal/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+// backfillUserLocale 语言偏好上线前注册的用户 locale 为空，补为默认语言。
+// 默认语言写死为当时的 zh-CN，之后修改 i18n.DefaultLocale 不影响该迁移
+var backfillUserLocale = migrate.Migration{
+	Version: 2026101902,
+	Name:    "backfill_user_locale",
+	Up: func(tx *gorm.DB) error {
+		return tx.Table(usersTable(tx)).
+			Where("locale = '' OR locale IS NULL").
+			Update("locale", "zh-CN").
+			Error
+	},
+	// 回填的数据无法区分是否为用户主动设置，回滚不做处理
+	Down: func(tx *gorm.DB) error {
+		return nil
+	},
+}
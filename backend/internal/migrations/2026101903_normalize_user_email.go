@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"blueLock/backend/internal/pkg/migrate"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// normalizeUserEmail 邮箱规范化上线前注册的账号可能带大写字母，统一转为小写。
+// 规范化后重复的邮箱（如 A@x.com 和 a@x.com 各注册了一次）需要人工合并，存在时迁移失败并列出这些邮箱
+var normalizeUserEmail = migrate.Migration{
+	Version: 2026101903,
+	Name:    "normalize_user_email",
+	Up: func(tx *gorm.DB) error {
+		var duplicates []string
+		err := tx.Table(usersTable(tx)).
+			Select("LOWER(TRIM(email))").
+			Group("LOWER(TRIM(email))").
+			Having("COUNT(*) > 1").
+			Pluck("LOWER(TRIM(email))", &duplicates).
+			Error
+		if err != nil {
+			return err
+		}
+		if len(duplicates) > 0 {
+			return fmt.Errorf("以下邮箱规范化后重复，请先人工合并账号: %s", strings.Join(duplicates, ", "))
+		}
+		return tx.Table(usersTable(tx)).
+			Where("email <> LOWER(TRIM(email))").
+			Update("email", gorm.Expr("LOWER(TRIM(email))")).
+			Error
+	},
+	// 原始大小写无法恢复，回滚不做处理
+	Down: func(tx *gorm.DB) error {
+		return nil
+	},
+}
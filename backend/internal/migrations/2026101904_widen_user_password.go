@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"blueLock/backend/internal/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+// widenUserPassword argon2id 的 PHC 字符串在参数较大时超过 100 个字符，密码哈希列放宽到 255
+var widenUserPassword = migrate.Migration{
+	Version: 2026101904,
+	Name:    "widen_user_password",
+	Up: func(tx *gorm.DB) error {
+		// 当时的用户表结构快照，类型名与 models.User 一致，表名和索引名按同样的命名策略生成
+		type User struct {
+			gorm.Model
+			Email    string `gorm:"type:varchar(255);not null;uniqueIndex"`
+			PassWord string `gorm:"size:255"`
+			Disabled bool   `gorm:"not null;default:false"`
+			Locale   string `gorm:"type:varchar(16)"`
+		}
+		m := tx.Migrator()
+		if err := m.AlterColumn(&User{}, "PassWord"); err != nil {
+			return err
+		}
+		// SQLite 修改列类型时会重建表，原有索引随之丢失，这里补回
+		for _, field := range []string{"Email", "DeletedAt"} {
+			if m.HasIndex(&User{}, field) {
+				continue
+			}
+			if err := m.CreateIndex(&User{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// 缩回 100 可能截断已有的 argon2id 哈希，回滚不做处理
+	Down: func(tx *gorm.DB) error {
+		return nil
+	},
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"blueLock/backend/internal/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+// addUserNotifyOptOut 用户表增加安全通知的退订设置
+var addUserNotifyOptOut = migrate.Migration{
+	Version: 2026101905,
+	Name:    "add_user_notify_opt_out",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if m.HasColumn(notifyOptOutUser(), "NotifyOptOut") {
+			return nil
+		}
+		return m.AddColumn(notifyOptOutUser(), "NotifyOptOut")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(notifyOptOutUser(), "NotifyOptOut")
+	},
+}
+
+// notifyOptOutUser 该迁移新增的列。类型名与 models.User 一致，按同样的命名策略对应用户表
+func notifyOptOutUser() any {
+	type User struct {
+		NotifyOptOut string `gorm:"type:varchar(255);not null;default:''"`
+	}
+	return &User{}
+}
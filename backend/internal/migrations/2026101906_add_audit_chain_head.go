@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"blueLock/backend/internal/pkg/migrate"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// addAuditChainHead 新增审计日志链头表，并按已有日志初始化链头
+var addAuditChainHead = migrate.Migration{
+	Version: 2026101906,
+	Name:    "add_audit_chain_head",
+	Up: func(tx *gorm.DB) error {
+		head := auditChainHead()
+		if err := tx.Migrator().CreateTable(head); err != nil {
+			return err
+		}
+		var last struct {
+			ID   uint
+			Hash string
+		}
+		err := tx.Table(auditLogTable(tx)).Select("id", "hash").Order("id DESC").Limit(1).Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Table(auditLogTable(tx)).Count(&count).Error; err != nil {
+			return err
+		}
+		return tx.Model(head).Create(map[string]any{
+			"id":         1,
+			"hash":       last.Hash,
+			"last_id":    last.ID,
+			"count":      count,
+			"updated_at": tx.NowFunc(),
+		}).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(auditChainHead())
+	},
+}
+
+// auditChainHead 迁移时的链头表结构。类型名与 models.AuditChainHead 一致，按同样的命名策略对应表名
+func auditChainHead() any {
+	type AuditChainHead struct {
+		ID        uint   `gorm:"primarykey;autoIncrement:false"`
+		Hash      string `gorm:"type:char(64);not null"`
+		LastID    uint   `gorm:"not null"`
+		Count     int64  `gorm:"not null"`
+		UpdatedAt time.Time
+	}
+	return &AuditChainHead{}
+}
+
+// auditLogTable 按命名策略（表前缀）生成审计日志表名
+func auditLogTable(tx *gorm.DB) string {
+	return tx.NamingStrategy.TableName("AuditLog")
+}
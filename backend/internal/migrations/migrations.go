@@ -0,0 +1,17 @@
+// Package migrations 数据库迁移列表。新增迁移时添加一个文件并在 All 中注册，版本号使用 yyyymmddNN 格式，已发布的迁移不能修改。
+// 迁移中不要引用 models 中的结构体，在迁移内定义当时的表结构，否则之后修改模型会改变旧迁移的行为。
+package migrations
+
+import "blueLock/backend/internal/pkg/migrate"
+
+// All 返回全部迁移
+func All() []migrate.Migration {
+	return []migrate.Migration{
+		baseline,
+		backfillUserLocale,
+		normalizeUserEmail,
+		widenUserPassword,
+		addUserNotifyOptOut,
+		addAuditChainHead,
+	}
+}
@@ -0,0 +1,75 @@
+package migrations_test
+
+import (
+	"blueLock/backend/internal/migrations"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/database"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/migrate"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// TestMigrationsMatchModels 执行全部迁移后的表结构包含当前模型的所有列和索引，
+// 模型新增字段却忘了写迁移时失败；随后全部回滚
+func TestMigrationsMatchModels(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.Open(globals.DatabaseConfig{Driver: database.DriverSQLite, Path: filepath.Join(t.TempDir(), "m.db")},
+		logger.Default.LogMode(logger.Silent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	m, err := migrate.New(db, migrations.All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	all := []any{&models.User{}, &models.UserIdentity{}, &models.Role{}, &models.Permission{}, &models.Device{}, &models.AuditLog{}, &models.AuditChainHead{}}
+	for _, model := range all {
+		stmt := db.Model(model).Statement
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("%s 缺少列 %s", stmt.Schema.Table, field.DBName)
+			}
+		}
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			if !db.Migrator().HasIndex(model, idx.Name) {
+				t.Errorf("%s 缺少索引 %s", stmt.Schema.Table, idx.Name)
+			}
+		}
+		if rel := stmt.Schema.Relationships.Many2Many; len(rel) > 0 {
+			for _, r := range rel {
+				if r.Type == schema.Many2Many && !db.Migrator().HasTable(r.JoinTable.Table) {
+					t.Errorf("缺少关联表 %s", r.JoinTable.Table)
+				}
+			}
+		}
+	}
+
+	if _, err := m.Down(ctx, len(migrations.All())); err != nil {
+		t.Fatal(err)
+	}
+	for _, model := range all {
+		if db.Migrator().HasTable(model) {
+			t.Errorf("回滚后仍存在表 %T", model)
+		}
+	}
+}
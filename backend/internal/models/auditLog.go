@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// AuditLog 审计日志表，只追加不修改；Hash = sha256(PrevHash + 本条内容)，形成哈希链用于发现篡改
+type AuditLog struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	ActorID    uint      `gorm:"index" json:"actor_id"` // 操作人，0 表示匿名（如登录失败）
+	Action     string    `gorm:"type:varchar(64);not null;index" json:"action"`
+	TargetType string    `gorm:"type:varchar(32)" json:"target_type"`
+	TargetID   string    `gorm:"type:varchar(64);index" json:"target_id"`
+	IP         string    `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent  string    `gorm:"type:varchar(255)" json:"user_agent"`
+	RequestID  string    `gorm:"type:varchar(64)" json:"request_id"`
+	Result     string    `gorm:"type:varchar(16);not null" json:"result"`
+	Details    string    `gorm:"type:text" json:"details"` // JSON
+	PrevHash   string    `gorm:"type:char(64);not null" json:"prev_hash"`
+	Hash       string    `gorm:"type:char(64);not null" json:"hash"`
+}
+
+// AuditChainHead 审计日志哈希链的链头，只有一行（ID = 1）。追加时锁住这一行串行化写入，
+// 并记录最后一条日志的哈希、ID 和总条数，用于发现尾部被删除
+type AuditChainHead struct {
+	ID        uint      `gorm:"primarykey;autoIncrement:false" json:"id"`
+	Hash      string    `gorm:"type:char(64);not null" json:"hash"`
+	LastID    uint      `gorm:"not null" json:"last_id"`
+	Count     int64     `gorm:"not null" json:"count"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Device 用户绑定的蓝牙保险箱设备
+type Device struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Serial     string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"serial"`
+	Name       string     `gorm:"size:64" json:"name"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+}
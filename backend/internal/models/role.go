@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// Role 角色表
+type Role struct {
+	gorm.Model
+	Name        string       `gorm:"type:varchar(64);not null;uniqueIndex" json:"name"`
+	Description string       `gorm:"size:255" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions,omitempty"`
+}
+
+// Permission 权限表，Name 形如 "user:read"
+type Permission struct {
+	gorm.Model
+	Name string `gorm:"type:varchar(64);not null;uniqueIndex" json:"name"`
+}
@@ -6,5 +6,10 @@ import "gorm.io/gorm"
 type User struct {
 	gorm.Model
 	Email    string `gorm:"type:varchar(255);not null;uniqueIndex" json:"email"`
-	PassWord string `json:"password"    gorm:"size:100"`
+	PassWord string `json:"password"    gorm:"size:255"` // 密码哈希（PHC 或 bcrypt 格式），通过第三方登录创建的账号为空
+	Disabled bool   `gorm:"not null;default:false" json:"disabled"`
+	Locale   string `gorm:"type:varchar(16)" json:"locale"` // 语言偏好，如 zh-CN、en
+	// NotifyOptOut 用户关闭的非关键安全通知类型，逗号分隔，如 new_login
+	NotifyOptOut string `gorm:"type:varchar(255);not null;default:''" json:"-"`
+	Roles        []Role `gorm:"many2many:user_roles" json:"roles,omitempty"`
 }
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// UserIdentity 第三方身份与本地用户的绑定关系
+type UserIdentity struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	Provider string `gorm:"type:varchar(64);not null;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject  string `gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_subject" json:"subject"`
+	Email    string `gorm:"type:varchar(255)" json:"email"`
+}
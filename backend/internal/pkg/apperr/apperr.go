@@ -0,0 +1,105 @@
+// Package apperr 定义带稳定错误码的领域错误，由错误处理中间件统一转换为HTTP响应
+package apperr
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error 领域错误
+type Error struct {
+	Code    int    // 业务状态码，见 globals.StatusXxx
+	Status  int    // HTTP 状态码
+	Key     string // 稳定的错误标识，客户端和多语言文案都以它为准
+	Message string // 默认提示文案
+	cause   error
+	fields  []FieldError
+}
+
+// FieldError 字段级的校验错误，随错误响应一起返回，客户端可以据此在对应输入框旁提示
+type FieldError struct {
+	Field string // 请求中的字段名，如 email、new_password
+	Rule  string // 未通过的规则，如 required、email_addr、password_min_length
+	Param string // 规则参数，如最小长度，没有时为空
+}
+
+// registry 所有预定义错误的 Key，用于校验多语言文案是否齐全
+var registry []string
+
+// New 定义一个领域错误
+func New(code, status int, key, message string) *Error {
+	registry = append(registry, key)
+	return &Error{Code: code, Status: status, Key: key, Message: message}
+}
+
+// Keys 返回所有预定义错误的 Key
+func Keys() []string {
+	return append([]string(nil), registry...)
+}
+
+// MessageID 错误在语言包中的消息ID
+func (e *Error) MessageID() string {
+	return "error." + e.Key
+}
+
+// Error 实现 error 接口，包含字段错误和底层原因，便于日志排查
+func (e *Error) Error() string {
+	msg := e.Message
+	if len(e.fields) > 0 {
+		parts := make([]string, 0, len(e.fields))
+		for _, f := range e.fields {
+			part := f.Field + ": " + f.Rule
+			if f.Param != "" {
+				part += "=" + f.Param
+			}
+			parts = append(parts, part)
+		}
+		msg += " [" + strings.Join(parts, ", ") + "]"
+	}
+	if e.cause != nil {
+		return msg + ": " + e.cause.Error()
+	}
+	return msg
+}
+
+// Unwrap 返回底层原因
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 同一个 Key 视为同一种错误，Wrap 之后仍可用 errors.Is 判断
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return e.Key == t.Key
+}
+
+// Wrap 返回携带底层原因的副本，不修改预定义的错误
+func (e *Error) Wrap(cause error) *Error {
+	cp := *e
+	cp.cause = cause
+	return &cp
+}
+
+// WithFields 返回携带字段错误的副本，不修改预定义的错误
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	cp := *e
+	cp.fields = append(append([]FieldError(nil), e.fields...), fields...)
+	return &cp
+}
+
+// Fields 返回字段错误
+func (e *Error) Fields() []FieldError {
+	return e.fields
+}
+
+// From 将任意错误转换为领域错误，无法识别的一律视为内部错误
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return ErrInternal.Wrap(err)
+}
@@ -0,0 +1,67 @@
+package apperr_test
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestWrapKeepsIdentity(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := fmt.Errorf("登录失败: %w", apperr.ErrInvalidCredentials.Wrap(cause))
+
+	if !errors.Is(err, apperr.ErrInvalidCredentials) {
+		t.Fatal("Wrap 之后 errors.Is 判断失败")
+	}
+	if errors.Is(err, apperr.ErrInternal) {
+		t.Fatal("不同 Key 的错误被视为相同")
+	}
+	if !errors.Is(err, cause) {
+		t.Fatal("Wrap 之后丢失了底层原因")
+	}
+	if got := apperr.From(err); got.Key != "invalid_credentials" || got.Code != apperr.ErrInvalidCredentials.Code {
+		t.Fatalf("From = %+v", got)
+	}
+	// 预定义的错误不被修改
+	if errors.Unwrap(apperr.ErrInvalidCredentials) != nil {
+		t.Fatal("Wrap 修改了预定义的错误")
+	}
+}
+
+func TestFromUnknown(t *testing.T) {
+	err := apperr.From(errors.New("boom"))
+	if err.Key != apperr.ErrInternal.Key || err.Status != 500 {
+		t.Fatalf("未知错误应当视为内部错误: %+v", err)
+	}
+	if err.Error() != "服务器内部错误: boom" {
+		t.Fatalf("Error() = %q", err.Error())
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	a := apperr.ErrValidation.WithFields(apperr.FieldError{Field: "email", Rule: "required"})
+	b := a.WithFields(apperr.FieldError{Field: "password", Rule: "password_min_length", Param: "8"})
+	if len(apperr.ErrValidation.Fields()) != 0 || len(a.Fields()) != 1 || len(b.Fields()) != 2 {
+		t.Fatalf("WithFields 修改了原错误: %d %d %d",
+			len(apperr.ErrValidation.Fields()), len(a.Fields()), len(b.Fields()))
+	}
+	if got := b.Error(); got != "请求参数校验失败 [email: required, password: password_min_length=8]" {
+		t.Fatalf("Error() = %q", got)
+	}
+}
+
+func TestKeysUnique(t *testing.T) {
+	keys := apperr.Keys()
+	if !slices.Contains(keys, "internal_error") {
+		t.Fatal("Keys 缺少预定义错误")
+	}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if seen[key] {
+			t.Errorf("错误 Key %q 重复定义", key)
+		}
+		seen[key] = true
+	}
+}
@@ -0,0 +1,64 @@
+package apperr
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"net/http"
+)
+
+// 通用错误
+var (
+	ErrBadRequest   = New(globals.StatusBadRequest, http.StatusBadRequest, "bad_request", "请求参数错误")
+	ErrValidation   = New(globals.StatusValidation, http.StatusBadRequest, "validation_failed", "请求参数校验失败")
+	ErrUnauthorized = New(globals.StatusUnauthorized, http.StatusUnauthorized, "unauthorized", "未提供认证信息")
+	ErrForbidden    = New(globals.StatusForbidden, http.StatusForbidden, "forbidden", "没有权限执行该操作")
+	ErrNotFound     = New(globals.StatusNotFound, http.StatusNotFound, "not_found", "资源不存在")
+	ErrInternal     = New(globals.StatusInternalServerError, http.StatusInternalServerError, "internal_error", "服务器内部错误")
+	ErrUnavailable  = New(globals.StatusServiceUnavailable, http.StatusServiceUnavailable, "service_unavailable", "服务暂不可用，请稍后再试")
+	ErrBodyTooLarge = New(globals.StatusBodyTooLarge, http.StatusRequestEntityTooLarge, "body_too_large", "请求体过大")
+)
+
+// 登录、注册相关错误
+var (
+	ErrInvalidCredentials = New(globals.StatusInvalidCredentials, http.StatusUnauthorized, "invalid_credentials", "邮箱或密码错误")
+	ErrCredentialRequired = New(globals.StatusCredentialNone, http.StatusBadRequest, "credential_required", "密码或验证码必须提供其一")
+	ErrCodeInvalid        = New(globals.StatusCodeInvalid, http.StatusBadRequest, "code_invalid", "验证码错误")
+	ErrCodeExpired        = New(globals.StatusCodeExpired, http.StatusBadRequest, "code_expired", "验证码已过期或未发送")
+	ErrWeakPassword       = New(globals.StatusWeakPassword, http.StatusBadRequest, "weak_password", "密码不符合安全要求")
+	ErrWrongPassword      = New(globals.StatusWrongPassword, http.StatusBadRequest, "wrong_password", "旧密码错误")
+	ErrEmailTaken         = New(globals.StatusEmailTaken, http.StatusConflict, "email_taken", "该邮箱已被注册")
+	ErrUserNotFound       = New(globals.StatusUserNotFound, http.StatusNotFound, "user_not_found", "用户不存在")
+	ErrAccountDisabled    = New(globals.StatusAccountDisabled, http.StatusForbidden, "account_disabled", "账号已被禁用")
+	ErrTokenInvalid       = New(globals.StatusTokenInvalid, http.StatusUnauthorized, "token_invalid", "令牌无效或已过期")
+	ErrTokenRevoked       = New(globals.StatusTokenRevoked, http.StatusUnauthorized, "token_revoked", "令牌已失效，请重新登录")
+	ErrMailSendFailed     = New(globals.StatusMailSendFailed, http.StatusBadGateway, "mail_send_failed", "邮件发送失败，请稍后重试")
+	ErrEmailRevertInvalid = New(globals.StatusEmailRevert, http.StatusBadRequest, "email_revert_invalid", "撤销链接无效或已过期")
+	ErrMagicLinkInvalid   = New(globals.StatusMagicLink, http.StatusBadRequest, "magic_link_invalid", "登录链接无效、已使用或已过期")
+)
+
+// 第三方登录相关错误
+var (
+	ErrProviderNotSupported = New(globals.StatusProviderNotFound, http.StatusNotFound, "provider_not_supported", "不支持的登录方式")
+	ErrOAuthStateInvalid    = New(globals.StatusOAuthState, http.StatusBadRequest, "oauth_state_invalid", "授权状态无效或已过期")
+	ErrOAuthFailed          = New(globals.StatusOAuthFailed, http.StatusUnauthorized, "oauth_failed", "第三方登录失败")
+	ErrEmailNotVerified     = New(globals.StatusEmailNotVerified, http.StatusForbidden, "email_not_verified", "第三方账号邮箱未验证，无法登录")
+	ErrIdentityTaken        = New(globals.StatusIdentityTaken, http.StatusConflict, "identity_taken", "该第三方账号已绑定其他用户")
+	ErrIdentityLinked       = New(globals.StatusIdentityLinked, http.StatusConflict, "identity_already_linked", "已绑定同类第三方账号，请先解绑")
+	ErrIdentityNotLinked    = New(globals.StatusIdentityNotFound, http.StatusNotFound, "identity_not_linked", "未绑定该第三方账号")
+	ErrLastCredential       = New(globals.StatusLastCredential, http.StatusConflict, "last_credential", "解绑后将无法登录，请先设置密码")
+)
+
+// 设备相关错误
+var (
+	ErrDeviceTaken    = New(globals.StatusDeviceTaken, http.StatusConflict, "device_taken", "该设备已被其他用户绑定")
+	ErrDeviceNotFound = New(globals.StatusDeviceNotFound, http.StatusNotFound, "device_not_found", "设备不存在")
+	ErrClientCert     = New(globals.StatusClientCert, http.StatusUnauthorized, "client_cert_required", "需要有效的设备证书")
+	ErrDeviceUnknown  = New(globals.StatusDeviceUnknown, http.StatusForbidden, "device_unknown", "证书对应的设备未绑定")
+)
+
+// 人机校验相关错误
+var (
+	ErrChallengeRequired = New(globals.StatusChallengeRequired, http.StatusPreconditionRequired, "challenge_required", "请先完成人机校验")
+	ErrChallengeFailed   = New(globals.StatusChallengeFailed, http.StatusPreconditionRequired, "challenge_failed", "人机校验未通过或已过期，请重新获取")
+	ErrChallengeTooMany  = New(globals.StatusTooManyRequests, http.StatusTooManyRequests, "challenge_too_many", "获取人机校验题目过于频繁，请稍后再试")
+	ErrChallengeType     = New(globals.StatusChallengeType, http.StatusNotFound, "challenge_type_not_supported", "不支持的人机校验方式")
+)
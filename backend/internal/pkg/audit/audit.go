@@ -0,0 +1,237 @@
+// Package audit 安全相关操作的审计记录，通过带缓冲的异步写入器落库，不阻塞请求
+package audit
+
+import (
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/requestid"
+	"blueLock/backend/internal/repository"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 审计动作
+const (
+	ActionLogin          = "auth.login"
+	ActionRegister       = "auth.register"
+	ActionLogout         = "auth.logout"
+	ActionTokenRefresh   = "auth.token_refresh"
+	ActionOAuthLogin     = "auth.oauth_login"
+	ActionMagicLinkLogin = "auth.magic_link_login"
+	ActionMagicApprove   = "auth.magic_link_approve"
+	ActionOAuthLink      = "auth.oauth_link"
+	ActionOAuthUnlink    = "auth.oauth_unlink"
+	ActionPasswordChange = "user.password_change"
+	ActionEmailChange    = "user.email_change"
+	ActionEmailRevert    = "user.email_revert"
+	ActionDeviceBind     = "device.bind"
+	ActionDeviceUnbind   = "device.unbind"
+	ActionUserDisable    = "admin.user_disable"
+	ActionUserEnable     = "admin.user_enable"
+	ActionForceLogout    = "admin.force_logout"
+	ActionLogLevelChange = "admin.log_level_change"
+)
+
+// 操作结果
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// 操作对象类型
+const (
+	TargetUser   = "user"
+	TargetDevice = "device"
+)
+
+// Entry 一条待记录的审计事件
+type Entry struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   string
+	IP         string
+	UserAgent  string
+	RequestID  string
+	Result     string
+	Details    map[string]any
+}
+
+// Config 异步写入器配置
+type Config struct {
+	BufferSize    int           // 队列长度，写满后丢弃并记录错误日志
+	BatchSize     int           // 单次落库的最大条数
+	FlushInterval time.Duration // 最长落库间隔
+}
+
+// Writer 带缓冲的异步审计写入器
+type Writer struct {
+	repo   *repository.AuditRepository
+	log    *zap.SugaredLogger
+	cfg    Config
+	queue  chan models.AuditLog
+	done   chan struct{}
+	closed sync.Once
+}
+
+var std *Writer
+
+// Init 创建并启动全局写入器
+func Init(repo *repository.AuditRepository, log *zap.SugaredLogger, cfg Config) {
+	std = NewWriter(repo, log, cfg)
+}
+
+// Close 关闭全局写入器并把队列中剩余的日志写完
+func Close() {
+	if std != nil {
+		std.Close()
+	}
+}
+
+// Record 通过全局写入器记录审计事件，未初始化时忽略
+func Record(e Entry) {
+	if std != nil {
+		std.Record(e)
+	}
+}
+
+// NewWriter 创建写入器并启动后台落库协程
+func NewWriter(repo *repository.AuditRepository, log *zap.SugaredLogger, cfg Config) *Writer {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1024
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	w := &Writer{
+		repo:  repo,
+		log:   log,
+		cfg:   cfg,
+		queue: make(chan models.AuditLog, cfg.BufferSize),
+		done:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Record 将审计事件放入队列，不等待落库
+func (w *Writer) Record(e Entry) {
+	details := "{}"
+	if len(e.Details) > 0 {
+		if b, err := json.Marshal(e.Details); err == nil {
+			details = string(b)
+		}
+	}
+	entry := models.AuditLog{
+		// 哈希中使用毫秒时间戳，这里先截断，保证落库后重新计算结果一致
+		CreatedAt:  time.Now().Truncate(time.Millisecond),
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		IP:         e.IP,
+		UserAgent:  truncate(e.UserAgent, 255),
+		RequestID:  e.RequestID,
+		Result:     e.Result,
+		Details:    details,
+	}
+	select {
+	case w.queue <- entry:
+	default:
+		w.log.Errorf("审计队列已满，丢弃审计日志 action=%s actor=%d target=%s", e.Action, e.ActorID, e.TargetID)
+	}
+}
+
+// Close 停止接收并等待队列中的日志写完
+func (w *Writer) Close() {
+	w.closed.Do(func() {
+		close(w.queue)
+		<-w.done
+	})
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.AuditLog, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case entry, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= w.cfg.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (w *Writer) flush(batch []models.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// Append 会回写哈希字段，传入副本避免和复用的 batch 互相影响
+	logs := append([]models.AuditLog(nil), batch...)
+	if err := w.repo.Append(ctx, logs); err != nil {
+		w.log.Errorf("审计日志写入失败，丢失 %d 条: %v", len(logs), err)
+	}
+}
+
+// FromGin 从请求中提取IP、UA和请求ID，返回预填好的审计事件
+func FromGin(c *gin.Context, action string) Entry {
+	e := Entry{
+		Action:    action,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: requestid.FromContext(c.Request.Context()),
+		Result:    ResultSuccess,
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(uint64); ok {
+			e.ActorID = uint(id)
+		}
+	}
+	return e
+}
+
+// Reason 失败原因只记录错误的 Key，不记录错误文本，避免把内部错误细节或用户输入写进审计日志
+func Reason(err error) string {
+	return apperr.From(err).Key
+}
+
+// FormatID 把数据库ID转换为审计对象ID
+func FormatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// truncate 截断到不超过 n 个字节，并退回到字符边界，避免把多字节字符截成半个写入数据库
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
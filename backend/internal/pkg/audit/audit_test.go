@@ -0,0 +1,163 @@
+package audit_test
+
+import (
+	"blueLock/backend/internal/migrations"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/audit"
+	"blueLock/backend/internal/pkg/database"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/migrate"
+	"blueLock/backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openRepo 打开执行过全部迁移的内存 SQLite 数据库
+func openRepo(t *testing.T) (*gorm.DB, *repository.AuditRepository) {
+	t.Helper()
+	db, err := database.Open(globals.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"},
+		logger.Default.LogMode(logger.Silent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	m, err := migrate.New(db, migrations.All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db, repository.NewAuditRepository(db)
+}
+
+func count(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(&models.AuditLog{}).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// TestWriterBatches 按批落库，Close 时写完队列中剩余的日志，写入的日志组成完整的哈希链
+func TestWriterBatches(t *testing.T) {
+	db, repo := openRepo(t)
+	w := audit.NewWriter(repo, zap.NewNop().Sugar(), audit.Config{BatchSize: 3, FlushInterval: time.Hour})
+	for i := 0; i < 7; i++ {
+		w.Record(audit.Entry{
+			ActorID:  1,
+			Action:   audit.ActionLogin,
+			TargetID: audit.FormatID(uint(i)),
+			Result:   audit.ResultSuccess,
+			Details:  map[string]any{"n": i},
+		})
+	}
+	w.Close()
+	w.Close()
+
+	if n := count(t, db); n != 7 {
+		t.Fatalf("落库 %d 条，期望 7 条", n)
+	}
+	checked, brokenID, truncated, err := repo.VerifyChain(context.Background())
+	if err != nil || checked != 7 || brokenID != 0 || truncated {
+		t.Fatalf("VerifyChain = (%d, %d, %v, %v)", checked, brokenID, truncated, err)
+	}
+
+	var last models.AuditLog
+	if err := db.Order("id DESC").First(&last).Error; err != nil {
+		t.Fatal(err)
+	}
+	if last.TargetID != "6" || last.Details != `{"n":6}` {
+		t.Fatalf("最后一条日志为 target=%s details=%s", last.TargetID, last.Details)
+	}
+
+	// 删除末尾的日志：剩余部分的哈希链完整，由链头发现被截断
+	if err := db.Where("id = ?", last.ID).Delete(&models.AuditLog{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	checked, brokenID, truncated, err = repo.VerifyChain(context.Background())
+	if err != nil || checked != 6 || brokenID != 0 || !truncated {
+		t.Fatalf("删除末尾后 VerifyChain = (%d, %d, %v, %v)", checked, brokenID, truncated, err)
+	}
+}
+
+// TestWriterFlushInterval 未攒满一批时按 FlushInterval 落库
+func TestWriterFlushInterval(t *testing.T) {
+	db, repo := openRepo(t)
+	w := audit.NewWriter(repo, zap.NewNop().Sugar(), audit.Config{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer w.Close()
+
+	w.Record(audit.Entry{Action: audit.ActionLogout, Result: audit.ResultSuccess})
+	deadline := time.Now().Add(2 * time.Second)
+	for count(t, db) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("超过 FlushInterval 后仍未落库")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestWriterTruncatesUserAgent 过长的 User-Agent 截断到 255 字节以内，且不截断多字节字符
+func TestWriterTruncatesUserAgent(t *testing.T) {
+	db, repo := openRepo(t)
+	w := audit.NewWriter(repo, zap.NewNop().Sugar(), audit.Config{})
+	ua := "a" + strings.Repeat("浏览器", 100)
+	w.Record(audit.Entry{Action: audit.ActionLogin, UserAgent: ua, Result: audit.ResultSuccess})
+	w.Record(audit.Entry{Action: audit.ActionLogin, UserAgent: "curl/8.0", Result: audit.ResultSuccess})
+	w.Close()
+
+	var logs []models.AuditLog
+	if err := db.Order("id").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := logs[0].UserAgent
+	// 1 + 3*84 = 253 字节，再加一个 3 字节的字符会超过 255
+	if len(got) != 253 || !utf8.ValidString(got) || !strings.HasPrefix(ua, got) {
+		t.Fatalf("截断后的 User-Agent 长度 %d，有效 UTF-8: %v", len(got), utf8.ValidString(got))
+	}
+	if logs[1].UserAgent != "curl/8.0" {
+		t.Fatalf("未超长的 User-Agent 被修改为 %q", logs[1].UserAgent)
+	}
+}
+
+func TestFromGin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/login", nil)
+	c.Request.RemoteAddr = "203.0.113.7:5000"
+	c.Request.Header.Set("User-Agent", "test-agent")
+	c.Set("user_id", uint64(42))
+
+	e := audit.FromGin(c, audit.ActionLogin)
+	if e.Action != audit.ActionLogin || e.ActorID != 42 || e.IP != "203.0.113.7" ||
+		e.UserAgent != "test-agent" || e.Result != audit.ResultSuccess {
+		t.Fatalf("FromGin = %+v", e)
+	}
+}
+
+// TestReason 只记录错误的 Key，内部错误的文本不进入审计日志
+func TestReason(t *testing.T) {
+	if got := audit.Reason(fmt.Errorf("登录失败: %w", apperr.ErrForbidden)); got != apperr.ErrForbidden.Key {
+		t.Fatalf("Reason = %q，期望 %q", got, apperr.ErrForbidden.Key)
+	}
+	if got := audit.Reason(errors.New("dial tcp 10.0.0.1:3306: connection refused")); got != apperr.ErrInternal.Key {
+		t.Fatalf("Reason = %q，期望 %q", got, apperr.ErrInternal.Key)
+	}
+}
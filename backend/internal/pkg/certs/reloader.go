@@ -0,0 +1,162 @@
+// Package certs 从文件加载 TLS 证书和客户端 CA，文件变化时自动重新加载，续期证书无需重启服务
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// 客户端证书策略
+const (
+	ClientAuthNone          = "none"
+	ClientAuthVerifyIfGiven = "verify_if_given"
+	ClientAuthRequire       = "require"
+)
+
+// Reloader 持有当前的证书和客户端 CA，监听文件变化后替换；新文件加载失败时继续使用旧的
+type Reloader struct {
+	certFile, keyFile, clientCAFile string
+	log                             *zap.SugaredLogger
+
+	cert     atomic.Pointer[tls.Certificate]
+	clientCA atomic.Pointer[x509.CertPool]
+
+	watcher *fsnotify.Watcher
+	closed  sync.Once
+}
+
+// NewReloader 加载证书并开始监听文件变化，clientCAFile 为空表示不校验客户端证书
+func NewReloader(certFile, keyFile, clientCAFile string, log *zap.SugaredLogger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile, log: log}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("监听证书文件失败: %w", err)
+	}
+	// 监听所在目录而不是文件本身：k8s Secret、certbot 等通过替换符号链接或重命名来更新文件
+	dirs := map[string]bool{}
+	for _, f := range []string{certFile, keyFile, clientCAFile} {
+		if f != "" {
+			dirs[filepath.Dir(f)] = true
+		}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("监听证书目录 %s 失败: %w", dir, err)
+		}
+	}
+	r.watcher = watcher
+	go r.watch()
+	return r, nil
+}
+
+// TLSConfig 返回使用当前证书的 tls.Config，每次握手都会取最新加载的证书和 CA
+func (r *Reloader) TLSConfig(minVersion, clientAuth string) *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	if minVersion == "1.3" {
+		base.MinVersion = tls.VersionTLS13
+	}
+	switch clientAuth {
+	case ClientAuthVerifyIfGiven:
+		base.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.Certificates = []tls.Certificate{*r.cert.Load()}
+		cfg.ClientCAs = r.clientCA.Load()
+		return cfg, nil
+	}
+	return base
+}
+
+// Close 停止监听文件
+func (r *Reloader) Close() error {
+	var err error
+	r.closed.Do(func() {
+		if r.watcher != nil {
+			err = r.watcher.Close()
+		}
+	})
+	return err
+}
+
+func (r *Reloader) watch() {
+	for {
+		select {
+		case ev, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if ev.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				// 更新过程中可能只写了一半（证书已换、私钥还没换），等下一次事件
+				r.log.Warnf("重新加载 TLS 证书失败，继续使用旧证书: %v", err)
+				continue
+			}
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			r.log.Errorf("监听证书文件出错: %v", err)
+		}
+	}
+}
+
+// reload 读取证书、私钥和客户端 CA，全部成功后才替换
+func (r *Reloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("加载证书失败: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端 CA 失败: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("客户端 CA 文件中没有有效的证书")
+		}
+	}
+
+	old := r.cert.Load()
+	r.cert.Store(&cert)
+	r.clientCA.Store(pool)
+	// 一次更新通常触发多个文件事件，证书没变时不重复记录
+	if old != nil && sameCert(old, &cert) {
+		return nil
+	}
+	if cert.Leaf != nil {
+		r.log.Infof("已加载 TLS 证书 subject=%s 过期时间=%s", cert.Leaf.Subject, cert.Leaf.NotAfter.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func sameCert(a, b *tls.Certificate) bool {
+	if len(a.Certificate) == 0 || len(b.Certificate) == 0 {
+		return false
+	}
+	return string(a.Certificate[0]) == string(b.Certificate[0])
+}
@@ -0,0 +1,276 @@
+package challenge
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"math/big"
+	mrand "math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// TypeCaptcha 滑块验证码
+const TypeCaptcha = "captcha"
+
+// 图片尺寸（像素）
+const (
+	captchaWidth     = 360
+	captchaHeight    = 150
+	pieceSize        = 44 // 拼图块外接正方形的边长
+	defaultTolerance = 2
+	decoys           = 2 // 干扰缺口的数量
+)
+
+// 缺口 x 坐标的范围 [minHoleX, maxHoleX)：缺口不与拼图块的初始位置重叠，且完整落在图片内。
+// 共 268 个取值，默认误差下随机提交的通过率约 1.9%。每道题只能提交一次
+const (
+	minHoleX = pieceSize + 4
+	maxHoleX = captchaWidth - pieceSize
+)
+
+// CaptchaData 发给客户端的滑块验证码。客户端把拼图块放在 (0, piece_y)，
+// 用户水平拖动到缺口处，提交拼图块左边缘的 x 坐标
+type CaptchaData struct {
+	Background string `json:"background"` // 带缺口的背景图，JPEG data URL
+	Piece      string `json:"piece"`      // 拼图块，PNG data URL，缺口外透明
+	PieceY     int    `json:"piece_y"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+}
+
+// SliderCaptcha 自托管的滑块验证码，图片在服务端随机生成，不依赖第三方服务
+type SliderCaptcha struct {
+	tolerance int
+}
+
+// NewSliderCaptcha 创建滑块验证码，tolerance 为允许的误差像素，<= 0 时使用默认值 2
+func NewSliderCaptcha(tolerance int) *SliderCaptcha {
+	if tolerance <= 0 {
+		tolerance = defaultTolerance
+	}
+	return &SliderCaptcha{tolerance: tolerance}
+}
+
+// Type 实现 Provider
+func (s *SliderCaptcha) Type() string { return TypeCaptcha }
+
+// Generate 实现 Provider，答案是缺口的 x 坐标。
+// 背景图上除了真正的缺口，还有形状经过翻转的干扰缺口，用户按拼图块的形状找到对应的缺口
+func (s *SliderCaptcha) Generate() (any, string, error) {
+	x, err := randInt(minHoleX, maxHoleX)
+	if err != nil {
+		return nil, "", err
+	}
+	y, err := randInt(4, captchaHeight-pieceSize-4)
+	if err != nil {
+		return nil, "", err
+	}
+	bg := drawBackground()
+	piece := image.NewRGBA(image.Rect(0, 0, pieceSize, pieceSize))
+	for py := 0; py < pieceSize; py++ {
+		for px := 0; px < pieceSize; px++ {
+			if !inPiece(px, py) {
+				continue
+			}
+			// 轮廓用原图颜色提亮形成柔和的高光，不使用固定颜色，避免直接按颜色定位
+			c := bg.RGBAAt(x+px, y+py)
+			if onEdge(inPiece, px, py) {
+				c = shade(c, 1.25)
+			}
+			piece.SetRGBA(px, py, c)
+		}
+	}
+
+	hole := image.NewRGBA(bg.Bounds())
+	copy(hole.Pix, bg.Pix)
+	placed := []image.Point{{x, y}}
+	drawHole(hole, inPiece, x, y)
+	// 干扰缺口使用拼图块翻转后的形状，与其他缺口互不重叠
+	for _, j := range mrand.Perm(len(decoyShapes))[:decoys] {
+		for try := 0; try < 100; try++ {
+			p := image.Pt(minHoleX+mrand.IntN(maxHoleX-minHoleX), 4+mrand.IntN(captchaHeight-pieceSize-8))
+			if overlaps(p, placed) {
+				continue
+			}
+			placed = append(placed, p)
+			drawHole(hole, decoyShapes[j], p.X, p.Y)
+			break
+		}
+	}
+
+	bgURL, err := jpegDataURL(hole)
+	if err != nil {
+		return nil, "", err
+	}
+	pieceURL, err := pngDataURL(piece)
+	if err != nil {
+		return nil, "", err
+	}
+	data := CaptchaData{Background: bgURL, Piece: pieceURL, PieceY: y, Width: captchaWidth, Height: captchaHeight}
+	return data, strconv.Itoa(x), nil
+}
+
+// drawHole 在 (x, y) 处画出形状为 shape 的缺口。缺口用该区域的平均颜色加深后叠加新的噪点填充，
+// 不由拼图块的像素变换而来，拿拼图块在背景图上做模板匹配找不到缺口，真假缺口的画法也完全相同
+func drawHole(img *image.RGBA, shape func(x, y int) bool, x, y int) {
+	var sum [3]int
+	n := 0
+	for py := 0; py < pieceSize; py++ {
+		for px := 0; px < pieceSize; px++ {
+			if shape(px, py) {
+				c := img.RGBAAt(x+px, y+py)
+				sum[0], sum[1], sum[2] = sum[0]+int(c.R), sum[1]+int(c.G), sum[2]+int(c.B)
+				n++
+			}
+		}
+	}
+	avg := color.RGBA{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n), 255}
+	for py := 0; py < pieceSize; py++ {
+		for px := 0; px < pieceSize; px++ {
+			if !shape(px, py) {
+				continue
+			}
+			f := 0.5
+			if onEdge(shape, px, py) {
+				f = 0.3
+			}
+			img.SetRGBA(x+px, y+py, noise(shade(avg, f), 12))
+		}
+	}
+}
+
+// overlaps 判断以 p 为左上角的缺口是否与已有的缺口重叠
+func overlaps(p image.Point, placed []image.Point) bool {
+	r := image.Rect(p.X, p.Y, p.X+pieceSize, p.Y+pieceSize)
+	for _, q := range placed {
+		if r.Overlaps(image.Rect(q.X, q.Y, q.X+pieceSize, q.Y+pieceSize)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check 实现 Provider
+func (s *SliderCaptcha) Check(answer, solution string) bool {
+	want, err := strconv.Atoi(answer)
+	if err != nil {
+		return false
+	}
+	got, err := strconv.Atoi(strings.TrimSpace(solution))
+	if err != nil {
+		return false
+	}
+	diff := got - want
+	return diff >= -s.tolerance && diff <= s.tolerance
+}
+
+// drawBackground 随机渐变加上色块和噪点，让缺口位置难以通过简单的边缘检测找到
+func drawBackground() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, captchaWidth, captchaHeight))
+	c1 := randomColor()
+	c2 := randomColor()
+	for y := 0; y < captchaHeight; y++ {
+		for x := 0; x < captchaWidth; x++ {
+			t := float64(x+y) / float64(captchaWidth+captchaHeight)
+			img.SetRGBA(x, y, color.RGBA{lerp(c1.R, c2.R, t), lerp(c1.G, c2.G, t), lerp(c1.B, c2.B, t), 255})
+		}
+	}
+	for i := 0; i < 12; i++ {
+		c := randomColor()
+		cx, cy := mrand.IntN(captchaWidth), mrand.IntN(captchaHeight)
+		r := 10 + mrand.IntN(35)
+		for y := max(cy-r, 0); y < min(cy+r, captchaHeight); y++ {
+			for x := max(cx-r, 0); x < min(cx+r, captchaWidth); x++ {
+				if (x-cx)*(x-cx)+(y-cy)*(y-cy) > r*r {
+					continue
+				}
+				o := img.RGBAAt(x, y)
+				img.SetRGBA(x, y, color.RGBA{lerp(o.R, c.R, 0.5), lerp(o.G, c.G, 0.5), lerp(o.B, c.B, 0.5), 255})
+			}
+		}
+	}
+	for i := range img.Pix {
+		if i%4 == 3 {
+			continue
+		}
+		v := int(img.Pix[i]) + mrand.IntN(31) - 15
+		img.Pix[i] = uint8(min(max(v, 0), 255))
+	}
+	return img
+}
+
+// inPiece 拼图块形状：正方形加上方和右侧两个半圆凸起
+func inPiece(x, y int) bool {
+	const side, top, r = 36, 8, 7
+	if x < side && y >= top && y < pieceSize {
+		return true
+	}
+	return within(x, y, side/2, top, r) || within(x, y, side, top+side/2, r)
+}
+
+// decoyShapes 干扰缺口的形状：拼图块左右翻转、上下翻转和旋转 180 度，凸起的位置都与拼图块不同
+var decoyShapes = []func(x, y int) bool{
+	func(x, y int) bool { return inPiece(pieceSize-1-x, y) },
+	func(x, y int) bool { return inPiece(x, pieceSize-1-y) },
+	func(x, y int) bool { return inPiece(pieceSize-1-x, pieceSize-1-y) },
+}
+
+func onEdge(shape func(x, y int) bool, x, y int) bool {
+	return !shape(x-1, y) || !shape(x+1, y) || !shape(x, y-1) || !shape(x, y+1)
+}
+
+func within(x, y, cx, cy, r int) bool {
+	return math.Hypot(float64(x-cx), float64(y-cy)) <= float64(r)
+}
+
+// shade 按比例调整颜色亮度，f < 1 变暗，f > 1 变亮
+func shade(c color.RGBA, f float64) color.RGBA {
+	scale := func(v uint8) uint8 { return uint8(min(float64(v)*f, 255)) }
+	return color.RGBA{scale(c.R), scale(c.G), scale(c.B), 255}
+}
+
+// noise 给颜色的每个通道加上 [-d, d] 内的随机偏移
+func noise(c color.RGBA, d int) color.RGBA {
+	v := func(x uint8) uint8 { return uint8(min(max(int(x)+mrand.IntN(2*d+1)-d, 0), 255)) }
+	return color.RGBA{v(c.R), v(c.G), v(c.B), 255}
+}
+
+func randomColor() color.RGBA {
+	return color.RGBA{uint8(40 + mrand.IntN(180)), uint8(40 + mrand.IntN(180)), uint8(40 + mrand.IntN(180)), 255}
+}
+
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
+
+// randInt 返回 [lo, hi) 内的随机数，缺口位置使用加密安全的随机源
+func randInt(lo, hi int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(hi-lo)))
+	if err != nil {
+		return 0, err
+	}
+	return lo + int(n.Int64()), nil
+}
+
+func pngDataURL(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// jpegDataURL 背景图有噪点，PNG 压缩效果差，使用 JPEG
+func jpegDataURL(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}); err != nil {
+		return "", err
+	}
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
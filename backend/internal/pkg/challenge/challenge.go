@@ -0,0 +1,229 @@
+// Package challenge 人机校验：发送邮件等接口在请求频率超过阈值后要求先完成校验。
+// 校验方式可插拔，内置自托管的滑块验证码（captcha）和哈希现金式的工作量证明（pow）
+package challenge
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/kv"
+	"blueLock/backend/internal/pkg/metrics"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 路由的触发模式
+const (
+	ModeOff      = "off"      // 不校验
+	ModeAdaptive = "adaptive" // 请求频率超过阈值后校验
+	ModeAlways   = "always"   // 每次请求都校验
+)
+
+// 未配置时使用的默认值
+const (
+	defaultTTL         = 2 * time.Minute
+	defaultWindow      = 10 * time.Minute
+	defaultIPLimit     = 3
+	defaultGlobalLimit = 100
+	// 获取题目的接口本身不需要校验，按 IP 限流并限制同时有效的题目数，避免刷题占满存储
+	defaultIssueIPLimit   = 20
+	defaultMaxOutstanding = 10000
+	issueWindow           = time.Minute
+)
+
+// Provider 一种校验方式
+type Provider interface {
+	// Type 校验方式名称，也是题目 ID 的前缀
+	Type() string
+	// Generate 生成题目，返回发给客户端的数据和服务端保存的答案
+	Generate() (data any, answer string, err error)
+	// Check 校验客户端提交的解答
+	Check(answer, solution string) bool
+}
+
+// Challenge 发给客户端的题目
+type Challenge struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	ExpiresIn int    `json:"expires_in"` // 有效期（秒）
+	Data      any    `json:"data"`
+}
+
+// Gate 人机校验闸门：按路由策略判断请求是否需要校验，签发题目并校验解答。
+// 题目答案保存在键值存储中，只能提交一次，无论对错都会作废
+type Gate struct {
+	store     kv.Store
+	cfg       globals.ChallengeConfig
+	providers map[string]Provider
+}
+
+// NewGate 创建人机校验闸门，并注册配置中启用的内置校验方式（未配置时启用全部）
+func NewGate(store kv.Store, cfg globals.ChallengeConfig) *Gate {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.IssueIPLimit <= 0 {
+		cfg.IssueIPLimit = defaultIssueIPLimit
+	}
+	if cfg.MaxOutstanding <= 0 {
+		cfg.MaxOutstanding = defaultMaxOutstanding
+	}
+	g := &Gate{store: store, cfg: cfg, providers: map[string]Provider{}}
+	names := cfg.Providers
+	if len(names) == 0 {
+		names = []string{TypeCaptcha, TypePow}
+	}
+	for _, name := range names {
+		switch name {
+		case TypeCaptcha:
+			g.Register(NewSliderCaptcha(cfg.CaptchaTolerance))
+		case TypePow:
+			g.Register(NewPow(cfg.PowDifficulty))
+		}
+	}
+	return g
+}
+
+// Register 注册校验方式，同名的会被替换
+func (g *Gate) Register(p Provider) {
+	g.providers[p.Type()] = p
+}
+
+// Types 返回已注册的校验方式
+func (g *Gate) Types() []string {
+	types := make([]string, 0, len(g.providers))
+	for t := range g.providers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Issue 为 ip 生成一道 typ 类型的题目，不支持的类型返回 ErrChallengeType。
+// 同一 IP 每分钟超过 challenge.issue_ip_limit 道，或一个有效期内签发超过 challenge.max_outstanding 道时返回 ErrChallengeTooMany
+func (g *Gate) Issue(ctx context.Context, typ, ip string) (*Challenge, error) {
+	p, ok := g.providers[typ]
+	if !ok {
+		return nil, apperr.ErrChallengeType
+	}
+	perIP, err := g.store.Incr(ctx, issueKey(ip), issueWindow)
+	if err != nil {
+		return nil, err
+	}
+	if perIP > g.cfg.IssueIPLimit {
+		metrics.Challenges.WithLabelValues(typ, "rejected").Inc()
+		return nil, apperr.ErrChallengeTooMany
+	}
+	// 被单 IP 限流拒绝的请求不计入总数，单个 IP 无法占满全局额度。
+	// 计数窗口与题目有效期相同，同时有效的题目最多约为该值的两倍
+	total, err := g.store.Incr(ctx, issueKey(""), g.cfg.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if total > g.cfg.MaxOutstanding {
+		metrics.Challenges.WithLabelValues(typ, "rejected").Inc()
+		return nil, apperr.ErrChallengeTooMany
+	}
+	data, answer, err := p.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("生成人机校验题目失败: %w", err)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	id := typ + "." + base64.RawURLEncoding.EncodeToString(b)
+	if err := g.store.Set(ctx, answerKey(id), answer, g.cfg.TTL); err != nil {
+		return nil, fmt.Errorf("保存人机校验题目失败: %w", err)
+	}
+	metrics.Challenges.WithLabelValues(typ, "issued").Inc()
+	return &Challenge{ID: id, Type: typ, ExpiresIn: int(g.cfg.TTL.Seconds()), Data: data}, nil
+}
+
+// Verify 校验解答，题目不存在、已过期、已使用或解答错误时返回 ErrChallengeFailed
+func (g *Gate) Verify(ctx context.Context, id, solution string) error {
+	typ, _, _ := strings.Cut(id, ".")
+	p, ok := g.providers[typ]
+	if !ok {
+		metrics.Challenges.WithLabelValues("unknown", "failed").Inc()
+		return apperr.ErrChallengeFailed
+	}
+	answer, err := g.store.GetDel(ctx, answerKey(id))
+	if errors.Is(err, kv.ErrNotFound) {
+		metrics.Challenges.WithLabelValues(typ, "failed").Inc()
+		return apperr.ErrChallengeFailed
+	}
+	if err != nil {
+		return fmt.Errorf("读取人机校验题目失败: %w", err)
+	}
+	if !p.Check(answer, solution) {
+		metrics.Challenges.WithLabelValues(typ, "failed").Inc()
+		return apperr.ErrChallengeFailed
+	}
+	metrics.Challenges.WithLabelValues(typ, "passed").Inc()
+	return nil
+}
+
+// Required 记录一次对 route 的请求，并按该路由的策略判断是否需要校验。
+// adaptive 模式下分别统计同一 IP 和全部请求在窗口内的次数，任一超过阈值即需要校验
+func (g *Gate) Required(ctx context.Context, route, ip string) (bool, error) {
+	rc := g.route(route)
+	switch rc.Mode {
+	case ModeOff:
+		return false, nil
+	case ModeAlways:
+		return true, nil
+	}
+	perIP, err := g.store.Incr(ctx, rateKey(route, ip), rc.Window)
+	if err != nil {
+		return false, err
+	}
+	total, err := g.store.Incr(ctx, rateKey(route, ""), rc.Window)
+	if err != nil {
+		return false, err
+	}
+	return perIP > rc.IPLimit || total > rc.GlobalLimit, nil
+}
+
+// route 返回路由的策略，补全默认值
+func (g *Gate) route(route string) globals.ChallengeRouteConfig {
+	rc := g.cfg.Routes[route]
+	if rc.Mode == "" {
+		rc.Mode = ModeAdaptive
+	}
+	if rc.Window <= 0 {
+		rc.Window = defaultWindow
+	}
+	if rc.IPLimit <= 0 {
+		rc.IPLimit = defaultIPLimit
+	}
+	if rc.GlobalLimit <= 0 {
+		rc.GlobalLimit = defaultGlobalLimit
+	}
+	return rc
+}
+
+func answerKey(id string) string {
+	return "challenge:" + id
+}
+
+// issueKey ip 为空时是全部题目的计数
+func issueKey(ip string) string {
+	if ip == "" {
+		return "challenge_issue"
+	}
+	return "challenge_issue:" + ip
+}
+
+// rateKey ip 为空时是该路由的全局计数
+func rateKey(route, ip string) string {
+	if ip == "" {
+		return fmt.Sprintf("challenge_rate:%s", route)
+	}
+	return fmt.Sprintf("challenge_rate:%s:%s", route, ip)
+}
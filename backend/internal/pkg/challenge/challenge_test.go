@@ -0,0 +1,295 @@
+package challenge_test
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/challenge"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/kv"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newGate(t *testing.T, cfg globals.ChallengeConfig) *challenge.Gate {
+	t.Helper()
+	store := kv.NewMemoryStore(0)
+	t.Cleanup(func() { _ = store.Close() })
+	if cfg.PowDifficulty == 0 {
+		cfg.PowDifficulty = 8
+	}
+	return challenge.NewGate(store, cfg)
+}
+
+// solvePow 暴力求解工作量证明
+func solvePow(t *testing.T, data challenge.PowData) string {
+	t.Helper()
+	for i := 0; i < 1<<24; i++ {
+		s := strconv.Itoa(i)
+		sum := sha256.Sum256([]byte(data.Prefix + ":" + s))
+		n := 0
+		for _, c := range sum {
+			n += bits.LeadingZeros8(c)
+			if c != 0 {
+				break
+			}
+		}
+		if n >= data.Difficulty {
+			return s
+		}
+	}
+	t.Fatal("没有找到工作量证明的解")
+	return ""
+}
+
+func TestSliderCaptcha(t *testing.T) {
+	c := challenge.NewSliderCaptcha(0)
+	for i := 0; i < 20; i++ {
+		raw, answer, err := c.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, err := strconv.Atoi(answer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := raw.(challenge.CaptchaData)
+		// 缺口不与拼图块的初始位置 (0, piece_y) 重叠，且完整落在图片内
+		if x < 44 || x+44 > data.Width {
+			t.Fatalf("缺口位置 %d 超出范围", x)
+		}
+
+		if !c.Check(answer, strconv.Itoa(x+2)) || !c.Check(answer, strconv.Itoa(x-2)) || !c.Check(answer, " "+answer+" ") {
+			t.Fatal("误差范围内的解答被拒绝")
+		}
+		if c.Check(answer, strconv.Itoa(x+3)) || c.Check(answer, strconv.Itoa(x-3)) {
+			t.Fatal("超出默认误差 2 像素的解答被接受")
+		}
+	}
+	if c.Check("100", "abc") || c.Check("100", "") {
+		t.Fatal("非数字的解答被接受")
+	}
+}
+
+func TestSliderCaptchaImages(t *testing.T) {
+	raw, _, err := challenge.NewSliderCaptcha(0).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := raw.(challenge.CaptchaData)
+	if !strings.HasPrefix(data.Background, "data:image/jpeg;base64,") {
+		t.Fatalf("背景图格式不正确: %.30s", data.Background)
+	}
+	b64, ok := strings.CutPrefix(data.Piece, "data:image/png;base64,")
+	if !ok {
+		t.Fatalf("拼图块格式不正确: %.30s", data.Piece)
+	}
+	b, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 轮廓不能是固定的纯白色
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a != 0 && r == 0xffff && g == 0xffff && b == 0xffff {
+				t.Fatalf("拼图块 (%d,%d) 为纯白色", x, y)
+			}
+		}
+	}
+}
+
+// decodeDataURL 解码验证码中的图片
+func decodeDataURL(t *testing.T, url string) image.Image {
+	t.Helper()
+	_, b64, ok := strings.Cut(url, ";base64,")
+	if !ok {
+		t.Fatalf("图片格式不正确: %.30s", url)
+	}
+	b, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func luma(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+}
+
+// TestSliderCaptchaHoleNotTemplate 缺口不由拼图块的像素变换而来，
+// 拼图块与答案处缺口的像素几乎不相关，无法用模板匹配定位
+func TestSliderCaptchaHoleNotTemplate(t *testing.T) {
+	c := challenge.NewSliderCaptcha(0)
+	for i := 0; i < 10; i++ {
+		raw, answer, err := c.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, _ := strconv.Atoi(answer)
+		data := raw.(challenge.CaptchaData)
+		bg := decodeDataURL(t, data.Background)
+		piece := decodeDataURL(t, data.Piece)
+
+		// 只比较缺口内部，轮廓有意做了提亮和加深
+		var xs, ys []float64
+		opaque := func(px, py int) bool {
+			_, _, _, a := piece.At(px, py).RGBA()
+			return a != 0
+		}
+		for py := 1; py < 43; py++ {
+			for px := 1; px < 43; px++ {
+				if opaque(px, py) && opaque(px-1, py) && opaque(px+1, py) && opaque(px, py-1) && opaque(px, py+1) {
+					xs = append(xs, luma(piece.At(px, py)))
+					ys = append(ys, luma(bg.At(x+px, data.PieceY+py)))
+				}
+			}
+		}
+		if r := correlation(xs, ys); r > 0.5 {
+			t.Fatalf("拼图块与缺口的相关系数为 %.2f，缺口可以通过模板匹配找到", r)
+		}
+	}
+}
+
+// correlation 皮尔逊相关系数
+func correlation(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i] / n
+		my += ys[i] / n
+	}
+	var sxy, sxx, syy float64
+	for i := range xs {
+		sxy += (xs[i] - mx) * (ys[i] - my)
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		syy += (ys[i] - my) * (ys[i] - my)
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+func TestPow(t *testing.T) {
+	p := challenge.NewPow(8)
+	raw, answer, err := p.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := raw.(challenge.PowData)
+	solution := solvePow(t, data)
+	if !p.Check(answer, solution) {
+		t.Fatal("正确的解被拒绝")
+	}
+	if p.Check(answer, "") || p.Check(answer, strings.Repeat("0", 33)) {
+		t.Fatal("空的或过长的解被接受")
+	}
+	// 难度保存在答案中，之后提高难度不影响已签发的题目
+	if !challenge.NewPow(24).Check(answer, solution) {
+		t.Fatal("已签发题目的难度被修改")
+	}
+}
+
+func TestGateVerifyOnce(t *testing.T) {
+	ctx := context.Background()
+	gate := newGate(t, globals.ChallengeConfig{})
+
+	c, err := gate.Issue(ctx, challenge.TypePow, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution := solvePow(t, c.Data.(challenge.PowData))
+	if err := gate.Verify(ctx, c.ID, solution); err != nil {
+		t.Fatalf("正确的解被拒绝: %v", err)
+	}
+	if err := gate.Verify(ctx, c.ID, solution); !errors.Is(err, apperr.ErrChallengeFailed) {
+		t.Fatalf("题目被重复使用: %v", err)
+	}
+
+	// 解答错误同样作废题目
+	c, err = gate.Issue(ctx, challenge.TypePow, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution = solvePow(t, c.Data.(challenge.PowData))
+	if err := gate.Verify(ctx, c.ID, "wrong"); !errors.Is(err, apperr.ErrChallengeFailed) {
+		t.Fatalf("错误的解被接受: %v", err)
+	}
+	if err := gate.Verify(ctx, c.ID, solution); !errors.Is(err, apperr.ErrChallengeFailed) {
+		t.Fatalf("答错后题目仍可使用: %v", err)
+	}
+
+	if _, err := gate.Issue(ctx, "unknown", "192.0.2.1"); !errors.Is(err, apperr.ErrChallengeType) {
+		t.Fatalf("不支持的类型: %v", err)
+	}
+	if err := gate.Verify(ctx, "unknown.abc", "x"); !errors.Is(err, apperr.ErrChallengeFailed) {
+		t.Fatalf("不存在的题目: %v", err)
+	}
+}
+
+func TestGateIssueLimits(t *testing.T) {
+	ctx := context.Background()
+	gate := newGate(t, globals.ChallengeConfig{IssueIPLimit: 2, MaxOutstanding: 3})
+
+	for i := 0; i < 2; i++ {
+		if _, err := gate.Issue(ctx, challenge.TypePow, "192.0.2.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := gate.Issue(ctx, challenge.TypePow, "192.0.2.1"); !errors.Is(err, apperr.ErrChallengeTooMany) {
+		t.Fatalf("超过单 IP 限制: %v", err)
+	}
+	// 被单 IP 限流拒绝的请求不占用全局额度
+	if _, err := gate.Issue(ctx, challenge.TypePow, "192.0.2.2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gate.Issue(ctx, challenge.TypePow, "192.0.2.3"); !errors.Is(err, apperr.ErrChallengeTooMany) {
+		t.Fatalf("超过同时有效的题目数: %v", err)
+	}
+}
+
+func TestGateRequired(t *testing.T) {
+	ctx := context.Background()
+	gate := newGate(t, globals.ChallengeConfig{Routes: map[string]globals.ChallengeRouteConfig{
+		"off":      {Mode: challenge.ModeOff},
+		"always":   {Mode: challenge.ModeAlways},
+		"adaptive": {IPLimit: 2, GlobalLimit: 3, Window: time.Minute},
+	}})
+
+	check := func(route, ip string, want bool) {
+		t.Helper()
+		got, err := gate.Required(ctx, route, ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("Required(%s, %s) = %v，期望 %v", route, ip, got, want)
+		}
+	}
+	check("off", "192.0.2.1", false)
+	check("always", "192.0.2.1", true)
+
+	check("adaptive", "192.0.2.1", false)
+	check("adaptive", "192.0.2.1", false)
+	check("adaptive", "192.0.2.1", true) // 同一 IP 超过 2 次
+	check("adaptive", "192.0.2.2", true) // 全部请求超过 3 次
+}
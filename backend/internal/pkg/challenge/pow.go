@@ -0,0 +1,82 @@
+package challenge
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// TypePow 工作量证明
+const TypePow = "pow"
+
+const (
+	defaultPowDifficulty = 20
+	maxPowSolutionLength = 32
+)
+
+// PowData 发给客户端的工作量证明题目
+type PowData struct {
+	Algorithm  string `json:"algorithm"`  // 固定为 sha256
+	Prefix     string `json:"prefix"`     // 随机前缀
+	Difficulty int    `json:"difficulty"` // 要求的前导零比特数
+}
+
+// Pow 哈希现金式的工作量证明：客户端找到一个字符串 solution，使
+// SHA-256(prefix + ":" + solution) 的前 difficulty 个比特都为 0。
+// 难度每加 1 平均计算量翻倍，20 约需一百万次哈希，手机上通常在一秒左右，不需要用户操作
+type Pow struct {
+	difficulty int
+}
+
+// NewPow 创建工作量证明，difficulty <= 0 时使用默认难度 20
+func NewPow(difficulty int) *Pow {
+	if difficulty <= 0 {
+		difficulty = defaultPowDifficulty
+	}
+	return &Pow{difficulty: difficulty}
+}
+
+// Type 实现 Provider
+func (p *Pow) Type() string { return TypePow }
+
+// Generate 实现 Provider，答案保存为 "<难度>:<前缀>"，之后修改难度不影响已签发的题目
+func (p *Pow) Generate() (any, string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	prefix := base64.RawURLEncoding.EncodeToString(b)
+	data := PowData{Algorithm: "sha256", Prefix: prefix, Difficulty: p.difficulty}
+	return data, strconv.Itoa(p.difficulty) + ":" + prefix, nil
+}
+
+// Check 实现 Provider
+func (p *Pow) Check(answer, solution string) bool {
+	if solution == "" || len(solution) > maxPowSolutionLength {
+		return false
+	}
+	d, prefix, ok := strings.Cut(answer, ":")
+	if !ok {
+		return false
+	}
+	difficulty, err := strconv.Atoi(d)
+	if err != nil {
+		return false
+	}
+	sum := sha256.Sum256([]byte(prefix + ":" + solution))
+	return leadingZeroBits(sum[:]) >= difficulty
+}
+
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if c != 0 {
+			return n + bits.LeadingZeros8(c)
+		}
+		n += 8
+	}
+	return n
+}
@@ -0,0 +1,82 @@
+// Package clientinfo 请求方的 IP 和 User-Agent，由中间件放入 context，供 logic 层在安全通知、登录设备识别中使用
+package clientinfo
+
+import (
+	"context"
+	"strings"
+)
+
+// Info 请求方信息
+type Info struct {
+	IP        string
+	UserAgent string
+}
+
+type ctxKey struct{}
+
+// NewContext 返回携带请求方信息的 context
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, ctxKey{}, info)
+}
+
+// FromContext 取出请求方信息，不在请求中时返回零值
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(ctxKey{}).(Info)
+	return info
+}
+
+// Device 粗略的设备描述，如 "Chrome · Windows"、"App · Android"，无法识别时返回空字符串。
+// 只区分浏览器（或客户端类型）和操作系统，不含版本号，同时用作识别新登录设备的指纹
+func (i Info) Device() string {
+	ua := i.UserAgent
+	parts := make([]string, 0, 2)
+	if client := clientName(ua); client != "" {
+		parts = append(parts, client)
+	}
+	if os := osName(ua); os != "" {
+		parts = append(parts, os)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// clientName 浏览器或客户端类型，判断顺序有讲究：Edge、Opera 的 UA 同时包含 Chrome，Chrome 的 UA 同时包含 Safari
+func clientName(ua string) string {
+	switch {
+	case ua == "":
+		return ""
+	case strings.Contains(ua, "Edg/"), strings.Contains(ua, "EdgA/"), strings.Contains(ua, "EdgiOS/"):
+		return "Edge"
+	case strings.Contains(ua, "OPR/"), strings.Contains(ua, "Opera"):
+		return "Opera"
+	case strings.Contains(ua, "Firefox/"), strings.Contains(ua, "FxiOS/"):
+		return "Firefox"
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"):
+		return "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		return "Safari"
+	// 移动端 App 常见的 HTTP 库
+	case strings.HasPrefix(ua, "okhttp/"), strings.HasPrefix(ua, "Dart/"), strings.Contains(ua, "CFNetwork/"):
+		return "App"
+	case strings.HasPrefix(ua, "curl/"):
+		return "curl"
+	}
+	return ""
+}
+
+func osName(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"), strings.Contains(ua, "CFNetwork/"):
+		return "iOS"
+	case strings.Contains(ua, "Android"), strings.HasPrefix(ua, "okhttp/"):
+		return "Android"
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		return "macOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+// Package database 根据配置选择驱动并打开数据库连接，支持 mysql、postgres 和 sqlite
+package database
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// 支持的驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// 连接池默认值，与原先写死在 DBInit 中的参数一致
+const (
+	defaultMaxOpenConns    = 100
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = time.Hour
+)
+
+// Open 按配置打开数据库、配置连接池并 Ping 一次确认可用
+func Open(cfg globals.DatabaseConfig, gormLogger logger.Interface) (*gorm.DB, error) {
+	dialector, err := Dialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	gormCfg := &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+		// 将唯一索引冲突等驱动错误转换为 gorm.ErrDuplicatedKey 等通用错误
+		TranslateError: true,
+		NamingStrategy: schema.NamingStrategy{
+			TablePrefix: "b_",
+		},
+	}
+	if gormLogger != nil {
+		gormCfg.Logger = gormLogger
+	}
+	db, err := gorm.Open(dialector, gormCfg)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库底层实例失败: %w", err)
+	}
+	maxOpen := cfg.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenConns
+	}
+	maxIdle := cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	lifetime := cfg.ConnMaxLifetime
+	if lifetime <= 0 {
+		lifetime = defaultConnMaxLifetime
+	}
+	if driverName(cfg) == DriverSQLite && isMemory(cfg.Path) {
+		// 内存库的每个连接都是一个独立的数据库，只能使用单连接
+		maxOpen, maxIdle, lifetime = 1, 1, 0
+	}
+	sqlDB.SetMaxOpenConns(maxOpen)
+	sqlDB.SetMaxIdleConns(maxIdle)
+	sqlDB.SetConnMaxLifetime(lifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("数据库连通性测试失败(Ping不通): %w", err)
+	}
+	return db, nil
+}
+
+// Dialector 根据驱动名构造 gorm 方言
+func Dialector(cfg globals.DatabaseConfig) (gorm.Dialector, error) {
+	switch driverName(cfg) {
+	case DriverMySQL:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User,     // 数据库用户名
+			cfg.Password, // 数据库密码
+			cfg.Host,     // 数据库主机名
+			cfg.Port,     // 数据库端口号
+			cfg.Name,     // 数据库名字
+		)
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		sslMode := cfg.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, sslMode)
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		path := cfg.Path
+		if path == "" || isMemory(path) {
+			return sqlite.Open("file::memory:"), nil
+		}
+		// 文件库在并发写入时等待锁而不是立即报 database is locked
+		return sqlite.Open("file:" + path + "?_pragma=busy_timeout(5000)"), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	}
+}
+
+func driverName(cfg globals.DatabaseConfig) string {
+	if cfg.Driver == "" {
+		return DriverMySQL
+	}
+	return strings.ToLower(cfg.Driver)
+}
+
+func isMemory(path string) bool {
+	return path == "" || path == ":memory:" || strings.HasPrefix(path, "file::memory:")
+}
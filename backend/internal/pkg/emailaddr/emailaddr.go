@@ -0,0 +1,46 @@
+// Package emailaddr 邮箱地址的规范化和格式校验。用户邮箱在存储和查询前都要先规范化，保证 A@x.com 和 a@x.com 是同一个账号
+package emailaddr
+
+import (
+	"net/mail"
+	"strings"
+)
+
+const (
+	MaxLength      = 254 // 邮箱地址的最大长度（RFC 5321 路径长度限制减去尖括号）
+	MaxLocalLength = 64  // @ 前本地部分的最大长度
+)
+
+// Normalize 规范化邮箱：去掉首尾空白并转为小写。
+// 不做 Gmail 去点号、去 +tag 之类的服务商相关处理，这些地址在其他服务商可能是不同的邮箱
+func Normalize(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Valid 判断规范化后的邮箱是否为合法的单个地址：不带显示名、长度不超限、域名至少包含两段
+func Valid(email string) bool {
+	email = Normalize(email)
+	if email == "" || len(email) > MaxLength {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	// ParseAddress 接受 "Name <a@b.com>" 这类写法，要求解析结果与输入完全一致
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return false
+	}
+	at := strings.LastIndexByte(email, '@')
+	local, domain := email[:at], email[at+1:]
+	if len(local) > MaxLocalLength {
+		return false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+	}
+	return true
+}
@@ -1,9 +1,55 @@
 package globals
 
 // 自定义状态码：StatusOK = 2000，区别于 http.StatusOK = 200
+// 前三位与HTTP状态码一致，最后一位区分具体原因，客户端应以此分支处理而不是解析提示文案
 const (
-	StatusOK                  = 2000 // 成功
-	StatusBadRequest          = 4000 //请求语法错误或无效参数
+	StatusOK = 2000 // 成功
+
+	StatusBadRequest     = 4000 //请求语法错误或无效参数
+	StatusCodeInvalid    = 4001 // 验证码错误
+	StatusCodeExpired    = 4002 // 验证码已过期或未发送
+	StatusWeakPassword   = 4003 // 密码不符合要求
+	StatusOAuthState     = 4004 // 第三方授权状态无效或已过期
+	StatusCredentialNone = 4005 // 密码或验证码必须提供其一
+	StatusWrongPassword  = 4006 // 旧密码错误
+	StatusValidation     = 4007 // 字段校验未通过，响应中带 fields
+	StatusEmailRevert    = 4008 // 撤销邮箱修改的链接无效或已过期
+	StatusMagicLink      = 4009 // 登录链接无效、已使用或已过期
+
+	StatusUnauthorized       = 4010 // 未授权，token过期
+	StatusInvalidCredentials = 4011 // 邮箱或密码错误
+	StatusTokenInvalid       = 4012 // 令牌无效、已过期或已吊销
+	StatusOAuthFailed        = 4013 // 第三方登录失败
+	StatusTokenRevoked       = 4014 // 令牌已被吊销（强制下线、修改密码）
+	StatusClientCert         = 4015 // 设备网关未提供经过校验的客户端证书
+
+	StatusForbidden        = 4030 // 已登录但没有权限
+	StatusAccountDisabled  = 4031 // 账号已被禁用
+	StatusEmailNotVerified = 4032 // 第三方账号邮箱未验证
+	StatusDeviceUnknown    = 4033 // 客户端证书对应的设备未绑定
+
+	StatusNotFound         = 4040 // 资源不存在
+	StatusUserNotFound     = 4041 // 用户不存在
+	StatusDeviceNotFound   = 4042 // 设备不存在
+	StatusProviderNotFound = 4043 // 不支持的第三方登录方式
+	StatusIdentityNotFound = 4044 // 未绑定该第三方账号
+	StatusChallengeType    = 4045 // 不支持的人机校验方式
+
+	StatusConflict       = 4090 // 资源冲突
+	StatusEmailTaken     = 4091 // 邮箱已被注册
+	StatusDeviceTaken    = 4092 // 设备已被其他用户绑定
+	StatusIdentityTaken  = 4093 // 第三方账号已绑定其他用户
+	StatusLastCredential = 4094 // 解绑后账号将无法登录
+	StatusIdentityLinked = 4095 // 已绑定同类第三方账号
+
+	StatusBodyTooLarge = 4130 // 请求体过大
+
+	StatusChallengeRequired = 4280 // 需要先完成人机校验
+	StatusChallengeFailed   = 4281 // 人机校验未通过或题目已过期
+
+	StatusTooManyRequests = 4290 // 请求过于频繁
+
 	StatusInternalServerError = 5000 // 服务器内部错误
-	StatusUnauthorized        = 4010 // 未授权，token过期
+	StatusMailSendFailed      = 5020 // 邮件发送失败
+	StatusServiceUnavailable  = 5030 // 依赖服务暂不可用
 )
@@ -2,39 +2,123 @@ package globals
 
 import "time"
 
-// DatabaseConfig mysql配置
+// DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	User     string `mapstructure:"user"`
+	Driver   string `mapstructure:"driver" validate:"omitempty,oneof=mysql postgres sqlite"` // mysql（默认）、postgres、sqlite
+	Host     string `mapstructure:"host" validate:"required_unless=Driver sqlite"`
+	Port     int    `mapstructure:"port" validate:"required_unless=Driver sqlite,omitempty,min=1,max=65535"`
+	User     string `mapstructure:"user" validate:"required_unless=Driver sqlite"`
 	Password string `mapstructure:"password"`
-	Name     string `mapstructure:"name"`
+	Name     string `mapstructure:"name" validate:"required_unless=Driver sqlite"`
+	SSLMode  string `mapstructure:"ssl_mode"` // postgres 的 sslmode，默认 disable
+	Path     string `mapstructure:"path"`     // sqlite 数据库文件路径，":memory:" 表示内存库
+
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`     // 最大打开连接数
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`     // 最大空闲连接数
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`  // 连接的最大可复用时间
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"` // 连接的最大空闲时间
 }
 
 // LogConfig 日志配置
 type LogConfig struct {
-	LogPath string `mapstructure:"logPath"`
-	AppName string `mapstructure:"appName"`
+	Level      string `mapstructure:"level" validate:"omitempty,oneof=debug info warn error"`      // 控制台日志级别，默认 info，支持热更新
+	FileLevel  string `mapstructure:"file_level" validate:"omitempty,oneof=debug info warn error"` // 文件日志级别，默认 debug，支持热更新
+	LogPath    string `mapstructure:"logPath" validate:"required"`
+	AppName    string `mapstructure:"appName" validate:"required"`
+	MaxSize    int    `mapstructure:"max_size" validate:"gte=0"`    // 单个文件的最大大小（MB），超过后轮转，默认 100
+	MaxAge     int    `mapstructure:"max_age" validate:"gte=0"`     // 旧文件保留天数，默认 30
+	MaxBackups int    `mapstructure:"max_backups" validate:"gte=0"` // 最多保留的旧文件个数，0 为不限制（仍受 max_age 约束）
+	Compress   bool   `mapstructure:"compress"`                     // 是否 gzip 压缩旧文件
+}
+
+// CorsConfig 跨域配置，支持热更新
+type CorsConfig struct {
+	AllowedOrigins []string `mapstructure:"allowed_origins" validate:"dive,url"` // 允许跨域访问的前端地址
 }
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	SecretKey          string        `mapstructure:"secret_key"`
-	AccessTokenExpiry  time.Duration `mapstructure:"access_token_expiry"`
-	RefreshTokenExpiry time.Duration `mapstructure:"refresh_token_expiry"`
+	SecretKey          string        `mapstructure:"secret_key" validate:"required,min=32"`
+	AccessTokenExpiry  time.Duration `mapstructure:"access_token_expiry" validate:"required,gt=0"`
+	RefreshTokenExpiry time.Duration `mapstructure:"refresh_token_expiry" validate:"required,gtfield=AccessTokenExpiry"`
 }
 
 // App 配置
 type App struct {
 	Host   string `mapstructure:"host"`
-	Port   int    `mapstructure:"port"`
+	Port   int    `mapstructure:"port" validate:"required,min=1,max=65535"`
 	Domain string `mapstructure:"domain"`
+	// TrustedProxies 可信的反向代理（IP 或 CIDR），只有来自这些地址的请求才采用 X-Forwarded-For 中的客户端 IP；
+	// 默认为空，不信任任何代理，客户端 IP 取连接的对端地址
+	TrustedProxies []string `mapstructure:"trusted_proxies" validate:"dive,cidr|ip"`
+	// HealthTimeout 就绪检查中单个组件的超时时间，默认 2s
+	HealthTimeout time.Duration `mapstructure:"health_timeout" validate:"gte=0"`
+	// DrainDelay 收到退出信号后，就绪检查先失败并等待这段时间再关闭服务，留给负载均衡摘除流量
+	DrainDelay time.Duration `mapstructure:"drain_delay" validate:"gte=0"`
+	// Server HTTP 服务的超时和大小限制
+	Server ServerConfig `mapstructure:"server"`
+	// TLS HTTPS 配置
+	TLS TLSConfig `mapstructure:"tls"`
+	// SecurityHeaders 安全响应头
+	SecurityHeaders SecurityHeadersConfig `mapstructure:"security_headers"`
+	// Metrics Prometheus 指标的暴露方式，都不配置时不提供 /metrics
+	Metrics MetricsConfig `mapstructure:"metrics"`
+}
+
+// MetricsConfig Prometheus 指标的暴露方式：内部监听地址和对外端口上的令牌认证可以同时启用
+type MetricsConfig struct {
+	// Addr 单独监听的内部地址（如 127.0.0.1:9091），只提供 /metrics，不要暴露到公网
+	Addr string `mapstructure:"addr" validate:"omitempty,hostname_port"`
+	// Token 非空时对外端口也提供 /metrics，要求 Authorization: Bearer <token>；只能通过环境变量或密钥文件提供
+	Token string `mapstructure:"token" validate:"omitempty,min=16"`
+}
+
+// ServerConfig HTTP 服务的超时和大小限制，未配置（为 0）时使用括号中的默认值
+type ServerConfig struct {
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" validate:"gte=0"` // 读取请求头的超时（5s）
+	ReadTimeout       time.Duration `mapstructure:"read_timeout" validate:"gte=0"`        // 读取整个请求的超时（30s）
+	WriteTimeout      time.Duration `mapstructure:"write_timeout" validate:"gte=0"`       // 写响应的超时（30s）
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout" validate:"gte=0"`        // keep-alive 连接的空闲超时（120s）
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes" validate:"gte=0"`    // 请求头最大字节数（64KB）
+	MaxBodyBytes      int64         `mapstructure:"max_body_bytes" validate:"gte=0"`      // 请求体最大字节数（1MB），个别路由有更小的限制
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout" validate:"gte=0"`    // 平滑关闭的总超时（15s）
+}
+
+// TLSConfig HTTPS 配置，证书文件修改后自动重新加载，无需重启
+type TLSConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file" validate:"required_if=Enabled true,omitempty,file"` // PEM 证书（含中间证书）
+	KeyFile  string `mapstructure:"key_file" validate:"required_if=Enabled true,omitempty,file"`  // PEM 私钥
+	// MinVersion 最低 TLS 版本，默认 1.2
+	MinVersion string `mapstructure:"min_version" validate:"omitempty,oneof=1.2 1.3"`
+	// ClientCAFile 校验客户端证书（mTLS）的 CA，设备网关使用
+	ClientCAFile string `mapstructure:"client_ca_file" validate:"required_if=ClientAuth verify_if_given,required_if=ClientAuth require,omitempty,file"`
+	// ClientAuth 客户端证书策略：none（默认）、verify_if_given（提供了就校验）、require（必须提供并校验）
+	ClientAuth string `mapstructure:"client_auth" validate:"omitempty,oneof=none verify_if_given require"`
+	// RedirectPort 大于 0 时在该端口监听 HTTP，并把请求重定向到 HTTPS
+	RedirectPort int `mapstructure:"redirect_port" validate:"gte=0,max=65535"`
+}
+
+// SecurityHeadersConfig 安全响应头配置
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge HTTPS 请求返回 Strict-Transport-Security 的 max-age，0 为不返回
+	HSTSMaxAge time.Duration `mapstructure:"hsts_max_age" validate:"gte=0"`
+	// HSTSIncludeSubdomains HSTS 是否包含子域名
+	HSTSIncludeSubdomains bool `mapstructure:"hsts_include_subdomains"`
+	// CSP Content-Security-Policy，为空时使用只适合纯 JSON 接口的严格策略
+	CSP string `mapstructure:"csp"`
 }
 
 // RedisConfig redis配置
 type RedisConfig struct {
-	Host         string        `mapstructure:"host"`
-	Port         int           `mapstructure:"port"`
+	Mode             string   `mapstructure:"mode" validate:"omitempty,oneof=single sentinel cluster"` // single（默认）、sentinel、cluster
+	Host             string   `mapstructure:"host"`                                                    // single 模式且 addrs 为空时使用
+	Port             int      `mapstructure:"port"`                                                    // single 模式且 addrs 为空时使用
+	Addrs            []string `mapstructure:"addrs"`                                                   // sentinel 节点或 cluster 种子节点地址
+	MasterName       string   `mapstructure:"master_name" validate:"required_if=Mode sentinel"`        // sentinel 模式的主节点名称
+	SentinelPassword string   `mapstructure:"sentinel_password"`                                       // sentinel 节点的密码
+	KeyPrefix        string   `mapstructure:"key_prefix"`                                              // 所有键的前缀，多个环境共用一个集群时用来隔离
+
 	Password     string        `mapstructure:"password"`
 	DB           int           `mapstructure:"db"`
 	PoolSize     int           `mapstructure:"pool_size"`      // Redis 连接池中的最大连接数
@@ -46,11 +130,132 @@ type RedisConfig struct {
 	MaxRetries   int           `mapstructure:"max_retries"`    // Redis 最大重试次数
 }
 
+// CacheConfig 键值存储配置
+type CacheConfig struct {
+	Driver          string        `mapstructure:"driver" validate:"omitempty,oneof=redis memory"` // redis（默认）或 memory，memory 只适合单机部署
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`                               // memory 模式下过期键的清理间隔
+}
+
+// MailConfig 发信 SMTP 配置
+type MailConfig struct {
+	SMTPHost  string `mapstructure:"smtp_host" validate:"required"`                 // SMTP服务器地址
+	SMTPPort  int    `mapstructure:"smtp_port" validate:"required,min=1,max=65535"` // SMTP端口（STARTTLS）
+	FromEmail string `mapstructure:"from_email" validate:"required,email"`          // 发件人邮箱
+	Password  string `mapstructure:"password"`                                      // 授权码
+}
+
+// OAuthProviderConfig 第三方OIDC身份提供方配置
+type OAuthProviderConfig struct {
+	Name         string   `mapstructure:"name" validate:"required"`             // 提供方名称，用于路由 /oauth/:provider
+	Issuer       string   `mapstructure:"issuer" validate:"required,url"`       // OIDC Issuer，用于 discovery
+	ClientID     string   `mapstructure:"client_id" validate:"required"`        // 客户端ID
+	ClientSecret string   `mapstructure:"client_secret"`                        // 客户端密钥
+	RedirectURL  string   `mapstructure:"redirect_url" validate:"required,url"` // 授权回调地址
+	Scopes       []string `mapstructure:"scopes"`                               // 额外申请的 scope，openid 会自动加入
+}
+
+// OAuthConfig 第三方登录配置
+type OAuthConfig struct {
+	StateExpiry time.Duration         `mapstructure:"state_expiry"` // 授权 state 的有效期
+	Providers   []OAuthProviderConfig `mapstructure:"providers" validate:"dive"`
+}
+
+// AdminConfig 初始管理员配置，仅在系统中还没有管理员时生效
+type AdminConfig struct {
+	Email    string `mapstructure:"email" validate:"omitempty,email"`
+	Password string `mapstructure:"password"`
+}
+
+// PasswordConfig 密码策略，只在设置新密码（注册、修改、重置）时校验，不影响已有密码登录
+type PasswordConfig struct {
+	MinLength     int  `mapstructure:"min_length" validate:"gte=0"`                       // 最小长度（按字符计），默认 8
+	MaxLength     int  `mapstructure:"max_length" validate:"omitempty,gtfield=MinLength"` // 最大长度（按字符计），默认 128
+	RequireLower  bool `mapstructure:"require_lower"`                                     // 必须包含小写字母
+	RequireUpper  bool `mapstructure:"require_upper"`                                     // 必须包含大写字母
+	RequireDigit  bool `mapstructure:"require_digit"`                                     // 必须包含数字
+	RequireSymbol bool `mapstructure:"require_symbol"`                                    // 必须包含符号
+	AllowBreached bool `mapstructure:"allow_breached"`                                    // 允许使用内置常见弱密码列表中的密码，默认拒绝
+
+	Hash PasswordHashConfig `mapstructure:"hash"`
+}
+
+// PasswordHashConfig 密码哈希配置。修改算法或参数后，旧哈希在用户下次密码登录成功时按新配置重新计算
+type PasswordHashConfig struct {
+	Algorithm   string        `mapstructure:"algorithm" validate:"omitempty,oneof=argon2id bcrypt"` // argon2id（默认）或 bcrypt，两种格式的已有哈希都能校验
+	Memory      uint32        `mapstructure:"memory"`                                               // argon2id 内存，单位 KiB，默认 65536（64MiB）
+	Iterations  uint32        `mapstructure:"iterations"`                                           // argon2id 迭代次数，默认 3
+	Parallelism uint8         `mapstructure:"parallelism"`                                          // argon2id 并行度，默认 2
+	BcryptCost  int           `mapstructure:"bcrypt_cost" validate:"omitempty,min=4,max=31"`        // bcrypt 计算成本，默认 10
+	Concurrency int           `mapstructure:"concurrency" validate:"gte=0"`                         // 同时进行的哈希计算数，默认 CPU 核数
+	MaxWait     time.Duration `mapstructure:"max_wait"`                                             // 等待计算名额的上限，超时返回 503，默认 3s
+}
+
+// NotifyConfig 账号安全通知邮件配置
+type NotifyConfig struct {
+	EmailRevertURL string        `mapstructure:"email_revert_url" validate:"omitempty,url"` // 撤销邮箱修改的页面地址，链接为 <地址>?token=xxx，默认 https://<app.domain>/account/email-revert
+	EmailRevertTTL time.Duration `mapstructure:"email_revert_ttl"`                          // 撤销链接有效期，默认 7 天
+	KnownDeviceTTL time.Duration `mapstructure:"known_device_ttl"`                          // 登录设备多久不用后再次登录视为新设备，默认 180 天
+	SendTimeout    time.Duration `mapstructure:"send_timeout"`                              // 单封通知的发送超时，默认 30s
+}
+
+// MagicLinkConfig 邮件链接登录配置
+type MagicLinkConfig struct {
+	URL     string        `mapstructure:"url" validate:"omitempty,url"` // 链接打开的页面，链接为 <地址>?token=xxx，默认 https://<app.domain>/login/magic
+	TTL     time.Duration `mapstructure:"ttl" validate:"gte=0"`         // 链接有效期，默认 10m
+	MaxWait time.Duration `mapstructure:"max_wait" validate:"gte=0"`    // 轮询接口最长等待时间，默认 25s，应小于 app.server.write_timeout
+}
+
+// ChallengeConfig 人机校验配置，保护发送邮件等可被滥用的接口
+type ChallengeConfig struct {
+	Providers        []string                        `mapstructure:"providers" validate:"dive,oneof=captcha pow"`        // 启用的校验方式，默认 captcha 和 pow
+	TTL              time.Duration                   `mapstructure:"ttl" validate:"gte=0"`                               // 题目有效期，默认 2m
+	PowDifficulty    int                             `mapstructure:"pow_difficulty" validate:"omitempty,min=8,max=32"`   // 工作量证明要求的前导零比特数，默认 20
+	CaptchaTolerance int                             `mapstructure:"captcha_tolerance" validate:"omitempty,min=1,max=5"` // 滑块允许的误差（像素），默认 2
+	IssueIPLimit     int64                           `mapstructure:"issue_ip_limit" validate:"gte=0"`                    // 同一 IP 每分钟最多获取的题目数，默认 20
+	MaxOutstanding   int64                           `mapstructure:"max_outstanding" validate:"gte=0"`                   // 一个有效期内最多签发的题目数，限制存储占用，默认 10000
+	Routes           map[string]ChallengeRouteConfig `mapstructure:"routes" validate:"dive"`                             // 各受保护路由的触发策略，键为路由名
+}
+
+// ChallengeRouteConfig 单个受保护路由的触发策略，未配置的路由使用括号中的默认值
+type ChallengeRouteConfig struct {
+	Mode        string        `mapstructure:"mode" validate:"omitempty,oneof=off adaptive always"` // off、adaptive（默认）或 always
+	Window      time.Duration `mapstructure:"window" validate:"gte=0"`                             // 计数窗口（10m）
+	IPLimit     int64         `mapstructure:"ip_limit" validate:"gte=0"`                           // 同一 IP 在窗口内超过该次数后要求校验（3）
+	GlobalLimit int64         `mapstructure:"global_limit" validate:"gte=0"`                       // 全部请求在窗口内超过该次数后要求校验（100）
+}
+
+// AuditConfig 审计日志异步写入配置
+type AuditConfig struct {
+	BufferSize    int           `mapstructure:"buffer_size"`    // 队列长度
+	BatchSize     int           `mapstructure:"batch_size"`     // 单次落库的最大条数
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 最长落库间隔
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Exporter    string  `mapstructure:"exporter" validate:"omitempty,oneof=none stdout otlp"` // none（默认）、stdout、otlp
+	Endpoint    string  `mapstructure:"endpoint"`                                             // OTLP/HTTP 地址，如 otel-collector:4318，为空时读取 OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure    bool    `mapstructure:"insecure"`                                             // OTLP 使用 http 而不是 https
+	SampleRatio float64 `mapstructure:"sample_ratio" validate:"gte=0,lte=1"`                  // 采样比例，0 视为 1
+	ServiceName string  `mapstructure:"service_name"`                                         // 默认 bluelock-backend
+}
+
 // Config 总配置
 type Config struct {
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Log      LogConfig      `mapstructure:"log"`
-	App      App            `mapstructure:"app"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Cache     CacheConfig     `mapstructure:"cache"`
+	Log       LogConfig       `mapstructure:"log"`
+	Cors      CorsConfig      `mapstructure:"cors"`
+	App       App             `mapstructure:"app"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Mail      MailConfig      `mapstructure:"mail"`
+	OAuth     OAuthConfig     `mapstructure:"oauth"`
+	Admin     AdminConfig     `mapstructure:"admin"`
+	Password  PasswordConfig  `mapstructure:"password"`
+	Notify    NotifyConfig    `mapstructure:"notify"`
+	MagicLink MagicLinkConfig `mapstructure:"magic_link"`
+	Challenge ChallengeConfig `mapstructure:"challenge"`
+	Audit     AuditConfig     `mapstructure:"audit"`
+	Tracing   TracingConfig   `mapstructure:"tracing"`
 }
@@ -1,12 +1,17 @@
 package globals
 
 import (
+	"blueLock/backend/internal/pkg/kv"
+	"io"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// 以下全局变量由 init 包在启动时赋值。业务代码应通过 app.App 注入依赖，
+// 这些变量只保留给初始化流程和尚未迁移的调用方使用
 var (
 	// AppConfig 项目总配置
 	AppConfig Config
@@ -14,8 +19,11 @@ var (
 	// Env 环境配置文件
 	Env string
 
-	// RDB redis链接
-	RDB *redis.Client
+	// RDB redis链接，cache.driver 为 memory 时为空
+	RDB redis.UniversalClient
+
+	// KV 键值存储（Redis 或进程内）
+	KV kv.Store
 
 	// DB mysql连接
 	DB *gorm.DB
@@ -23,6 +31,15 @@ var (
 	// Log 日志记录
 	Log *zap.SugaredLogger
 
+	// LogFile 日志文件写入器，关闭时停止按天轮转
+	LogFile io.Closer
+
+	// LogLevel 控制台日志级别，修改后立即生效
+	LogLevel zap.AtomicLevel
+
+	// FileLogLevel 文件日志级别，修改后立即生效
+	FileLogLevel zap.AtomicLevel
+
 	// Router 总路由
 	Router *gin.Engine
 )
@@ -0,0 +1,156 @@
+// Package health 存活和就绪检查：就绪检查并发探测各依赖组件，记录状态和耗时
+package health
+
+import (
+	"blueLock/backend/internal/pkg/version"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded" // 非关键组件不可用，不影响就绪
+	StatusDraining = "draining" // 正在平滑关闭
+)
+
+// DefaultTimeout 单个组件检查的默认超时时间
+const DefaultTimeout = 2 * time.Second
+
+// Component 一个被检查的依赖组件
+type Component struct {
+	Name string
+	// Critical 为 true 时组件不可用会导致就绪检查失败，否则只标记为 degraded
+	Critical bool
+	// Detail 附加信息，例如键值存储的后端名称
+	Detail string
+	Check  func(ctx context.Context) error
+	// CacheFor 大于 0 时检查结果缓存这么久，适用于探测代价较高的组件（如每次都要建立 SMTP 会话的邮件服务）。
+	// 同一组件同时只有一个探测在执行，其他请求等待并复用结果
+	CacheFor time.Duration
+}
+
+// ComponentStatus 单个组件的检查结果
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	Cached    bool    `json:"cached,omitempty"` // 结果来自缓存，latency_ms 为当时的耗时
+}
+
+// Report 就绪检查结果
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+	Build      version.Info               `json:"build"`
+}
+
+// Checker 汇总各组件的检查
+type Checker struct {
+	components []Component
+	cache      []cachedStatus // 与 components 一一对应
+	timeout    time.Duration
+	draining   atomic.Bool
+}
+
+// cachedStatus 组件最近一次的检查结果
+type cachedStatus struct {
+	mu        sync.Mutex
+	status    ComponentStatus
+	checkedAt time.Time
+}
+
+// NewChecker 创建检查器，timeout 为单个组件的超时时间，<=0 时使用 DefaultTimeout
+func NewChecker(timeout time.Duration, components ...Component) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{components: components, cache: make([]cachedStatus, len(components)), timeout: timeout}
+}
+
+// SetDraining 标记服务正在关闭，此后就绪检查一律失败，让负载均衡先摘除流量
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Liveness 存活检查，进程能处理请求即视为存活
+func (c *Checker) Liveness() Report {
+	return Report{Status: StatusUp, Build: version.Get()}
+}
+
+// Readiness 并发检查所有组件，ok 表示是否可以接收流量
+func (c *Checker) Readiness(ctx context.Context) (Report, bool) {
+	if c.draining.Load() {
+		return Report{Status: StatusDraining, Build: version.Get()}, false
+	}
+
+	results := make([]ComponentStatus, len(c.components))
+	var wg sync.WaitGroup
+	for i, comp := range c.components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.cachedCheck(ctx, i, comp)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Components: make(map[string]ComponentStatus, len(results)), Build: version.Get()}
+	ok := true
+	for i, comp := range c.components {
+		res := results[i]
+		report.Components[comp.Name] = res
+		if res.Status == StatusUp {
+			continue
+		}
+		if comp.Critical {
+			ok = false
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report, ok
+}
+
+// cachedCheck 组件设置了 CacheFor 时优先返回未过期的缓存结果
+func (c *Checker) cachedCheck(ctx context.Context, i int, comp Component) ComponentStatus {
+	if comp.CacheFor <= 0 {
+		return c.check(ctx, comp)
+	}
+	entry := &c.cache[i]
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if !entry.checkedAt.IsZero() && time.Since(entry.checkedAt) < comp.CacheFor {
+		res := entry.status
+		res.Cached = true
+		return res
+	}
+	entry.status = c.check(ctx, comp)
+	entry.checkedAt = time.Now()
+	return entry.status
+}
+
+func (c *Checker) check(ctx context.Context, comp Component) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := comp.Check(ctx)
+	res := ComponentStatus{
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Detail:    comp.Detail,
+	}
+	if err != nil {
+		res.Status = StatusDown
+		if !comp.Critical {
+			res.Status = StatusDegraded
+		}
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,75 @@
+package health_test
+
+import (
+	"blueLock/backend/internal/pkg/health"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadinessStatus(t *testing.T) {
+	var dbErr, mailErr error
+	c := health.NewChecker(time.Second,
+		health.Component{Name: "database", Critical: true, Check: func(context.Context) error { return dbErr }},
+		health.Component{Name: "mail", Check: func(context.Context) error { return mailErr }},
+	)
+	ctx := context.Background()
+
+	if report, ok := c.Readiness(ctx); !ok || report.Status != health.StatusUp {
+		t.Fatalf("全部正常: %v %+v", ok, report)
+	}
+	mailErr = errors.New("smtp down")
+	if report, ok := c.Readiness(ctx); !ok || report.Status != health.StatusDegraded {
+		t.Fatalf("非关键组件不可用: %v %+v", ok, report)
+	}
+	dbErr = errors.New("db down")
+	if report, ok := c.Readiness(ctx); ok || report.Status != health.StatusDown {
+		t.Fatalf("关键组件不可用: %v %+v", ok, report)
+	}
+	dbErr, mailErr = nil, nil
+	c.SetDraining()
+	if report, ok := c.Readiness(ctx); ok || report.Status != health.StatusDraining {
+		t.Fatalf("关闭中: %v %+v", ok, report)
+	}
+}
+
+func TestReadinessCache(t *testing.T) {
+	var calls atomic.Int32
+	c := health.NewChecker(time.Second, health.Component{
+		Name:     "mail",
+		CacheFor: 100 * time.Millisecond,
+		Check: func(context.Context) error {
+			calls.Add(1)
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+	})
+	ctx := context.Background()
+
+	// 并发的检查只探测一次
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Readiness(ctx)
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("探测了 %d 次，期望 1 次", n)
+	}
+	report, _ := c.Readiness(ctx)
+	if !report.Components["mail"].Cached {
+		t.Fatal("缓存期内的结果应当标记为 cached")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	report, _ = c.Readiness(ctx)
+	if n := calls.Load(); n != 2 || report.Components["mail"].Cached {
+		t.Fatalf("缓存过期后应当重新探测，探测次数 %d", n)
+	}
+}
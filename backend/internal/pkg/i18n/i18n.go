@@ -0,0 +1,202 @@
+// Package i18n 多语言文案：按消息ID从 locales/<locale>.json 取文案，邮件模板放在 templates/<locale>/ 下
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale 默认语言，协商失败或文案缺失时使用
+const DefaultLocale = "zh-CN"
+
+// ctxKey 请求中显式指定的语言在 gin 上下文中的 key
+const ctxKey = "locale"
+
+//go:embed locales/*.json templates
+var files embed.FS
+
+var (
+	bundles   = map[string]map[string]string{}  // locale -> 消息ID -> 文案
+	templates = map[string]*template.Template{} // locale -> 邮件模板
+	supported []string
+	matcher   language.Matcher
+
+	// userLocale 查询已登录用户保存的语言偏好，由初始化代码注入，避免本包依赖数据层
+	userLocale func(ctx context.Context, userID uint) string
+)
+
+func init() {
+	entries, err := fs.Glob(files, "locales/*.json")
+	if err != nil {
+		panic(err)
+	}
+	tags := make([]language.Tag, 0, len(entries))
+	// 默认语言放在第一位，匹配失败时 matcher 返回它
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.HasSuffix(entries[i], DefaultLocale+".json")
+	})
+	for _, entry := range entries {
+		locale := strings.TrimSuffix(path.Base(entry), ".json")
+		data, err := files.ReadFile(entry)
+		if err != nil {
+			panic(err)
+		}
+		bundle := map[string]string{}
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			panic(fmt.Sprintf("解析语言包 %s 失败: %v", entry, err))
+		}
+		bundles[locale] = bundle
+		supported = append(supported, locale)
+		tags = append(tags, language.MustParse(locale))
+
+		tpl, err := template.ParseFS(files, "templates/"+locale+"/*.html")
+		if err != nil {
+			panic(fmt.Sprintf("解析邮件模板 %s 失败: %v", locale, err))
+		}
+		templates[locale] = tpl
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// Supported 返回支持的语言列表，默认语言在第一位
+func Supported() []string {
+	return append([]string(nil), supported...)
+}
+
+// IsSupported 判断语言是否受支持
+func IsSupported(locale string) bool {
+	_, ok := bundles[locale]
+	return ok
+}
+
+// Negotiate 根据 Accept-Language 协商语言，无法匹配时返回默认语言
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, idx, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return supported[idx]
+}
+
+// T 翻译消息ID，当前语言缺失时回退到默认语言，仍缺失时返回消息ID本身
+func T(locale, key string) string {
+	if msg, ok := bundles[locale][key]; ok {
+		return msg
+	}
+	if msg, ok := bundles[DefaultLocale][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// Lookup 翻译消息ID，缺失时返回 false，调用方可以使用自己的兜底文案
+func Lookup(locale, key string) (string, bool) {
+	if msg, ok := bundles[locale][key]; ok {
+		return msg, true
+	}
+	msg, ok := bundles[DefaultLocale][key]
+	return msg, ok
+}
+
+// Render 渲染邮件模板
+func Render(locale, name string, data any) (string, error) {
+	tpl, ok := templates[locale]
+	if !ok || tpl.Lookup(name+".html") == nil {
+		tpl = templates[DefaultLocale]
+	}
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, name+".html", data); err != nil {
+		return "", fmt.Errorf("渲染邮件模板 %s 失败: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// SetUserLocaleResolver 注入查询用户语言偏好的函数
+func SetUserLocaleResolver(fn func(ctx context.Context, userID uint) string) {
+	userLocale = fn
+}
+
+// Middleware 请求携带 Accept-Language 时协商语言并写入上下文
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if header := c.GetHeader("Accept-Language"); header != "" {
+			c.Set(ctxKey, Negotiate(header))
+		}
+		c.Next()
+	}
+}
+
+// Requested 返回请求通过 Accept-Language 显式指定的语言，未指定时返回空字符串
+func Requested(c *gin.Context) string {
+	return c.GetString(ctxKey)
+}
+
+// FromGin 确定本次响应使用的语言：Accept-Language > 已登录用户保存的偏好 > 默认语言
+func FromGin(c *gin.Context) string {
+	if locale := Requested(c); locale != "" {
+		return locale
+	}
+	if userLocale != nil {
+		if userID, ok := c.Get("user_id"); ok {
+			if id, ok := userID.(uint64); ok {
+				if locale := userLocale(c, uint(id)); IsSupported(locale) {
+					// 缓存到上下文，同一请求内不再重复查询
+					c.Set(ctxKey, locale)
+					return locale
+				}
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// Validate 校验所有语言包的消息ID、邮件模板一致，且包含 required 中的全部消息ID
+func Validate(required ...string) error {
+	keys := map[string]bool{}
+	for _, key := range required {
+		keys[key] = true
+	}
+	tplNames := map[string]bool{}
+	for locale, bundle := range bundles {
+		for key := range bundle {
+			keys[key] = true
+		}
+		for _, tpl := range templates[locale].Templates() {
+			tplNames[tpl.Name()] = true
+		}
+	}
+
+	var missing []string
+	for _, locale := range supported {
+		for key := range keys {
+			if _, ok := bundles[locale][key]; !ok {
+				missing = append(missing, locale+": "+key)
+			}
+		}
+		for name := range tplNames {
+			if templates[locale].Lookup(name) == nil {
+				missing = append(missing, locale+": templates/"+name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("语言包缺少以下文案: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
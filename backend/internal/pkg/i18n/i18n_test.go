@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"strings"
+	"testing"
+)
+
+// TestBundlesConsistent 所有语言包的消息ID和邮件模板一致，且每个错误码都有文案
+func TestBundlesConsistent(t *testing.T) {
+	required := []string{"validation.invalid"}
+	for _, key := range apperr.Keys() {
+		required = append(required, "error."+key)
+	}
+	if err := Validate(required...); err != nil {
+		t.Fatal(err)
+	}
+	if supported[0] != DefaultLocale {
+		t.Fatalf("默认语言应当排在第一位，实际为 %v", supported)
+	}
+}
+
+func TestValidateReportsMissing(t *testing.T) {
+	if err := Validate("no.such.key"); err == nil || !strings.Contains(err.Error(), "no.such.key") {
+		t.Fatalf("缺少的消息ID没有被报告: %v", err)
+	}
+
+	// 临时删掉英文包中的一条文案
+	const key = "validation.invalid"
+	msg := bundles["en"][key]
+	delete(bundles["en"], key)
+	t.Cleanup(func() { bundles["en"][key] = msg })
+	if err := Validate(); err == nil || !strings.Contains(err.Error(), "en: "+key) {
+		t.Fatalf("en 缺少的文案没有被报告: %v", err)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                       DefaultLocale,
+		"en-US,en;q=0.9":         "en",
+		"zh-TW":                  "zh-CN",
+		"fr-FR":                  DefaultLocale,
+		"fr;q=0.9, en;q=0.8":     "en",
+		"not a language header!": DefaultLocale,
+	}
+	for header, want := range cases {
+		if got := Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %s，期望 %s", header, got, want)
+		}
+	}
+}
+
+func TestTranslateFallback(t *testing.T) {
+	if got := T("en", "no.such.key"); got != "no.such.key" {
+		t.Fatalf("缺失的消息ID应当原样返回，实际为 %q", got)
+	}
+	if _, ok := Lookup("en", "no.such.key"); ok {
+		t.Fatal("Lookup 不应找到缺失的消息ID")
+	}
+	if T("fr", "validation.invalid") != T(DefaultLocale, "validation.invalid") {
+		t.Fatal("不支持的语言应当回退到默认语言")
+	}
+}
@@ -0,0 +1,49 @@
+// Package kv 带过期时间的键值存储抽象。验证码、刷新令牌、OAuth state 以及限流计数都通过它读写，
+// 多实例部署使用 Redis，单机和开发环境可以使用进程内存储，不再强依赖 Redis。
+package kv
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 存储后端名称
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+// ErrNotFound 键不存在或已过期
+var ErrNotFound = errors.New("kv: key not found")
+
+// ErrNotInteger Incr 的键保存的不是十进制整数
+var ErrNotInteger = errors.New("kv: value is not an integer")
+
+// Store 键值存储。ttl <= 0 表示永不过期
+type Store interface {
+	// Get 读取值，不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (string, error)
+	// Set 写入值，覆盖旧值和旧的过期时间
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// GetDel 原子地读取并删除，不存在时返回 ErrNotFound
+	GetDel(ctx context.Context, key string) (string, error)
+	// Del 删除键，键不存在不算错误
+	Del(ctx context.Context, keys ...string) error
+	// Incr 原子加一并返回新值；键不存在时从 0 开始，并设置 ttl（已存在的键不会刷新过期时间）
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// CompareAndDelete 值等于 expected 时删除并返回 true，否则不做修改并返回 false
+	CompareAndDelete(ctx context.Context, key, expected string) (bool, error)
+	// Ping 检查存储是否可用
+	Ping(ctx context.Context) error
+	// Backend 返回后端名称，用于日志和健康检查
+	Backend() string
+	// Close 释放连接
+	Close() error
+}
+
+// HashTag 把 s 包装为 Redis 集群的哈希标签。同一实体的多个键使用相同的标签，
+// 保证它们落在同一个槽上，可以在一个事务或脚本中一起操作
+func HashTag(s string) string {
+	return "{" + s + "}"
+}
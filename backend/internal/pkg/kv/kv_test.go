@@ -0,0 +1,179 @@
+package kv_test
+
+import (
+	"blueLock/backend/internal/pkg/kv"
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// 同一组用例分别跑在进程内实现和 Redis（miniredis）上，保证两者行为一致
+func TestStore(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		storeSuite(t, func(t *testing.T) kv.Store { return kv.NewMemoryStore(0) })
+	})
+	t.Run("redis", func(t *testing.T) {
+		storeSuite(t, func(t *testing.T) kv.Store {
+			s, _ := newRedisStore(t)
+			return s
+		})
+	})
+}
+
+// Incr 不刷新已有键的过期时间，过期后从 1 重新计数
+func TestMemoryIncrKeepsTTL(t *testing.T) {
+	ctx := context.Background()
+	s := kv.NewMemoryStore(0)
+	t.Cleanup(func() { _ = s.Close() })
+	if err := s.Set(ctx, "counter", "1", 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.Incr(ctx, "counter", time.Hour); err != nil || n != 2 {
+		t.Fatalf("Incr = %d, %v", n, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n, err := s.Incr(ctx, "counter", time.Hour); err != nil || n != 1 {
+		t.Fatalf("过期后 Incr = %d, %v, 期望 1", n, err)
+	}
+}
+
+// Close 停止清理协程，可以重复调用
+func TestMemoryCloseStopsJanitor(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		s := kv.NewMemoryStore(time.Millisecond)
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before+5 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Close 后清理协程没有退出: %d -> %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// 键前缀只影响 Redis 中实际的键名，不影响行为
+func TestRedisKeyPrefix(t *testing.T) {
+	s, mr := newRedisStore(t)
+	if err := s.Set(context.Background(), "k", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists("test:k") || mr.Exists("k") {
+		t.Fatalf("键没有加上前缀: %v", mr.Keys())
+	}
+}
+
+func newRedisStore(t *testing.T) (kv.Store, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return kv.NewRedisStore(rdb, "test:"), mr
+}
+
+// storeSuite kv.Store 的一致性用例
+func storeSuite(t *testing.T, newStore func(t *testing.T) kv.Store) {
+	ctx := context.Background()
+
+	t.Run("GetSetDel", func(t *testing.T) {
+		s := newStore(t)
+		if _, err := s.Get(ctx, "k"); !errors.Is(err, kv.ErrNotFound) {
+			t.Fatalf("不存在的键 Get 错误 = %v", err)
+		}
+		if err := s.Set(ctx, "k", "v1", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Set(ctx, "k", "v2", 0); err != nil {
+			t.Fatal(err)
+		}
+		if v, err := s.Get(ctx, "k"); err != nil || v != "v2" {
+			t.Fatalf("Get = %q, %v", v, err)
+		}
+		if err := s.Del(ctx, "k", "missing"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Get(ctx, "k"); !errors.Is(err, kv.ErrNotFound) {
+			t.Fatalf("删除后 Get 错误 = %v", err)
+		}
+	})
+
+	t.Run("GetDel", func(t *testing.T) {
+		s := newStore(t)
+		if err := s.Set(ctx, "k", "v", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if v, err := s.GetDel(ctx, "k"); err != nil || v != "v" {
+			t.Fatalf("GetDel = %q, %v", v, err)
+		}
+		if _, err := s.GetDel(ctx, "k"); !errors.Is(err, kv.ErrNotFound) {
+			t.Fatalf("第二次 GetDel 错误 = %v", err)
+		}
+	})
+
+	t.Run("Incr", func(t *testing.T) {
+		s := newStore(t)
+		for want := int64(1); want <= 3; want++ {
+			n, err := s.Incr(ctx, "counter", time.Minute)
+			if err != nil || n != want {
+				t.Fatalf("Incr = %d, %v, 期望 %d", n, err, want)
+			}
+		}
+		if v, err := s.Get(ctx, "counter"); err != nil || v != "3" {
+			t.Fatalf("计数 Get = %q, %v", v, err)
+		}
+
+		// Set 写入的整数字符串可以继续加一
+		if err := s.Set(ctx, "counter", "41", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := s.Incr(ctx, "counter", time.Minute); err != nil || n != 42 {
+			t.Fatalf("Set 之后 Incr = %d, %v, 期望 42", n, err)
+		}
+		if err := s.Set(ctx, "text", "abc", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Incr(ctx, "text", time.Minute); !errors.Is(err, kv.ErrNotInteger) {
+			t.Fatalf("非整数 Incr 错误 = %v", err)
+		}
+	})
+
+	t.Run("CompareAndDelete", func(t *testing.T) {
+		s := newStore(t)
+		if ok, err := s.CompareAndDelete(ctx, "k", "v"); err != nil || ok {
+			t.Fatalf("不存在的键 CompareAndDelete = %v, %v", ok, err)
+		}
+		if err := s.Set(ctx, "k", "v", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := s.CompareAndDelete(ctx, "k", "other"); err != nil || ok {
+			t.Fatalf("值不同 CompareAndDelete = %v, %v", ok, err)
+		}
+		if ok, err := s.CompareAndDelete(ctx, "k", "v"); err != nil || !ok {
+			t.Fatalf("值相同 CompareAndDelete = %v, %v", ok, err)
+		}
+		if _, err := s.Get(ctx, "k"); !errors.Is(err, kv.ErrNotFound) {
+			t.Fatalf("CompareAndDelete 后 Get 错误 = %v", err)
+		}
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		s := newStore(t)
+		if err := s.Ping(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if s.Backend() == "" {
+			t.Fatal("Backend 为空")
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// MemoryStore 进程内实现，数据不跨实例共享、重启即丢失，只适合单机部署和开发环境
+type MemoryStore struct {
+	// go-cache 自身并发安全，这里的锁用于保证 GetDel、Incr、CompareAndDelete 等组合操作的原子性
+	mu     sync.Mutex
+	cache  *cache.Cache
+	stop   chan struct{}
+	closed sync.Once
+}
+
+// NewMemoryStore 创建进程内存储，cleanupInterval 为过期键的清理间隔
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Minute
+	}
+	// go-cache 自带的清理协程只能等 GC 回收时停止，这里自己清理，Close 时退出
+	s := &MemoryStore{cache: cache.New(cache.NoExpiration, 0), stop: make(chan struct{})}
+	go s.janitor(cleanupInterval)
+	return s
+}
+
+// Get 读取值
+func (s *MemoryStore) Get(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(key)
+}
+
+// Set 写入值
+func (s *MemoryStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache.Set(key, value, expiration(ttl))
+	return nil
+}
+
+// GetDel 读取并删除
+func (s *MemoryStore) GetDel(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, err := s.get(key)
+	if err != nil {
+		return "", err
+	}
+	s.cache.Delete(key)
+	return value, nil
+}
+
+// Del 删除键
+func (s *MemoryStore) Del(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		s.cache.Delete(key)
+	}
+	return nil
+}
+
+// Incr 加一，键不存在时以 ttl 创建。Set 写入的十进制整数字符串也可以加一，与 Redis 行为一致
+func (s *MemoryStore) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, expiresAt, found := s.cache.GetWithExpiration(key)
+	if !found {
+		s.cache.Set(key, int64(1), expiration(ttl))
+		return 1, nil
+	}
+	var n int64
+	switch v := v.(type) {
+	case int64:
+		n = v
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, ErrNotInteger
+		}
+		n = parsed
+	default:
+		return 0, ErrNotInteger
+	}
+	n++
+	// 保留原有的过期时间
+	remaining := cache.NoExpiration
+	if !expiresAt.IsZero() {
+		if remaining = time.Until(expiresAt); remaining <= 0 {
+			remaining = time.Nanosecond
+		}
+	}
+	s.cache.Set(key, n, remaining)
+	return n, nil
+}
+
+// CompareAndDelete 值相等时删除
+func (s *MemoryStore) CompareAndDelete(_ context.Context, key, expected string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, err := s.get(key)
+	if err == ErrNotFound || value != expected {
+		return false, nil
+	}
+	s.cache.Delete(key)
+	return true, nil
+}
+
+// Ping 进程内存储总是可用
+func (s *MemoryStore) Ping(context.Context) error {
+	return nil
+}
+
+// Backend 返回 "memory"
+func (s *MemoryStore) Backend() string {
+	return BackendMemory
+}
+
+// Close 停止清理协程并清空数据，可以重复调用
+func (s *MemoryStore) Close() error {
+	s.closed.Do(func() { close(s.stop) })
+	s.cache.Flush()
+	return nil
+}
+
+// janitor 定期删除过期的键，直到 Close
+func (s *MemoryStore) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.cache.DeleteExpired()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// get 读取字符串值，Incr 写入的计数按十进制返回，与 Redis 行为一致
+func (s *MemoryStore) get(key string) (string, error) {
+	v, found := s.cache.Get(key)
+	if !found {
+		return "", ErrNotFound
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	default:
+		return "", ErrNotFound
+	}
+}
+
+// expiration 转换为 go-cache 的过期时间，ttl <= 0 表示永不过期
+func expiration(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return cache.NoExpiration
+	}
+	return ttl
+}
@@ -0,0 +1,121 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// incrScript 加一，并在键刚创建时设置过期时间
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n`)
+
+// compareAndDeleteScript 值相等时删除
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisStore 基于 Redis 的实现，支持单节点、哨兵和集群客户端
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore 使用已创建好的 Redis 客户端，prefix 会加在每个键前面
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Client 返回底层客户端，供需要 Redis 特有命令的场景使用（注意自行加前缀）
+func (s *RedisStore) Client() redis.UniversalClient {
+	return s.client
+}
+
+// Unprefixed 返回使用同一客户端但不加前缀的存储，用于访问配置前缀之前写入的键
+func (s *RedisStore) Unprefixed() Store {
+	return NewRedisStore(s.client, "")
+}
+
+func (s *RedisStore) key(key string) string {
+	return s.prefix + key
+}
+
+// Get 读取值
+func (s *RedisStore) Get(ctx context.Context, key string) (string, error) {
+	return redisResult(s.client.Get(ctx, s.key(key)).Result())
+}
+
+// Set 写入值
+func (s *RedisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return s.client.Set(ctx, s.key(key), value, ttl).Err()
+}
+
+// GetDel 读取并删除（GETDEL 命令，需 Redis 6.2+）
+func (s *RedisStore) GetDel(ctx context.Context, key string) (string, error) {
+	return redisResult(s.client.GetDel(ctx, s.key(key)).Result())
+}
+
+// Del 删除键，键可以分布在集群的不同槽上
+func (s *RedisStore) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	// 集群模式下一条 DEL 的多个键必须在同一个槽，逐个删除并用 pipeline 合并往返，由客户端按槽分发
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, s.key(key))
+		}
+		return nil
+	})
+	return err
+}
+
+// Incr 通过脚本原子地加一并设置过期时间
+func (s *RedisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	n, err := incrScript.Run(ctx, s.client, []string{s.key(key)}, ttl.Milliseconds()).Int64()
+	if err != nil && strings.Contains(err.Error(), "not an integer") {
+		return 0, ErrNotInteger
+	}
+	return n, err
+}
+
+// CompareAndDelete 通过脚本原子地比较并删除
+func (s *RedisStore) CompareAndDelete(ctx context.Context, key, expected string) (bool, error) {
+	n, err := compareAndDeleteScript.Run(ctx, s.client, []string{s.key(key)}, expected).Int64()
+	return n == 1, err
+}
+
+// Ping 检查连接
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// Backend 返回 "redis"
+func (s *RedisStore) Backend() string {
+	return BackendRedis
+}
+
+// Close 关闭客户端
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// redisResult 把 redis.Nil 转换为 ErrNotFound
+func redisResult(value string, err error) (string, error) {
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return value, err
+}
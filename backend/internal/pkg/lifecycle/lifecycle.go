@@ -0,0 +1,113 @@
+// Package lifecycle 管理后台组件的关闭顺序：收到退出信号后按阶段依次停止，同一阶段内后注册的先停止
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Phase 关闭阶段，数值小的先执行
+type Phase int
+
+const (
+	// PhaseServer 停止接收新请求并等待处理中的请求完成
+	PhaseServer Phase = iota
+	// PhaseWorkers 停止后台任务：配置和证书监听、审计写入器、链路导出器等
+	PhaseWorkers
+	// PhasePools 关闭数据库、Redis 连接池
+	PhasePools
+	// PhaseLogs 刷新日志缓冲并关闭日志文件，最后执行，前面各阶段的日志都能落盘。
+	// 此阶段不再通过日志记录进度，否则写入会重新打开刚关闭的文件；失败输出到标准错误
+	PhaseLogs
+)
+
+var phaseNames = map[Phase]string{
+	PhaseServer:  "server",
+	PhaseWorkers: "workers",
+	PhasePools:   "pools",
+	PhaseLogs:    "logs",
+}
+
+// StopFunc 停止组件，ctx 到期后应尽快返回
+type StopFunc func(ctx context.Context) error
+
+type component struct {
+	name  string
+	phase Phase
+	stop  StopFunc
+}
+
+// Manager 记录需要在退出时停止的组件
+type Manager struct {
+	log        *zap.SugaredLogger
+	mu         sync.Mutex
+	components []component
+	once       sync.Once
+}
+
+// New 创建生命周期管理器
+func New(log *zap.SugaredLogger) *Manager {
+	return &Manager{log: log}
+}
+
+// Register 注册一个需要在退出时停止的组件
+func (m *Manager) Register(phase Phase, name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component{name: name, phase: phase, stop: stop})
+}
+
+// WaitForSignal 阻塞直到收到 SIGINT、SIGTERM，或 errc 中出现错误（例如监听端口失败），返回该错误
+func WaitForSignal(errc <-chan error) error {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	select {
+	case <-quit:
+		return nil
+	case err := <-errc:
+		return err
+	}
+}
+
+// Shutdown 按阶段停止所有组件，timeout 为总超时。某个组件出错或超时只记录日志，不影响后续组件；
+// 只执行一次，重复调用直接返回
+func (m *Manager) Shutdown(timeout time.Duration) {
+	m.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		m.mu.Lock()
+		components := append([]component(nil), m.components...)
+		m.mu.Unlock()
+
+		for phase := PhaseServer; phase <= PhaseLogs; phase++ {
+			for i := len(components) - 1; i >= 0; i-- {
+				c := components[i]
+				if c.phase != phase {
+					continue
+				}
+				start := time.Now()
+				err := c.stop(ctx)
+				if phase == PhaseLogs {
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "关闭 %s/%s 失败: %v\n", phaseNames[phase], c.name, err)
+					}
+					continue
+				}
+				if err != nil {
+					m.log.Errorf("关闭 %s/%s 失败: %v", phaseNames[phase], c.name, err)
+					continue
+				}
+				m.log.Infof("已关闭 %s/%s，耗时 %v", phaseNames[phase], c.name, time.Since(start).Round(time.Millisecond))
+			}
+		}
+	})
+}
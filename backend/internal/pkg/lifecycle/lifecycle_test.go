@@ -0,0 +1,50 @@
+package lifecycle_test
+
+import (
+	"blueLock/backend/internal/pkg/lifecycle"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	lc := lifecycle.New(zap.New(core).Sugar())
+
+	var order []string
+	stop := func(name string, err error) lifecycle.StopFunc {
+		return func(context.Context) error {
+			order = append(order, name)
+			return err
+		}
+	}
+	// 注册顺序与阶段顺序无关，同一阶段内后注册的先停止
+	lc.Register(lifecycle.PhaseLogs, "log-file", stop("log-file", nil))
+	lc.Register(lifecycle.PhaseLogs, "log", stop("log", errors.New("sync failed")))
+	lc.Register(lifecycle.PhasePools, "database", stop("database", nil))
+	lc.Register(lifecycle.PhaseWorkers, "audit", stop("audit", nil))
+	lc.Register(lifecycle.PhaseServer, "http", stop("http", nil))
+
+	lc.Shutdown(time.Second)
+	lc.Shutdown(time.Second)
+
+	want := []string{"http", "audit", "database", "log", "log-file"}
+	if !slices.Equal(order, want) {
+		t.Fatalf("关闭顺序为 %v，期望 %v", order, want)
+	}
+
+	// 日志阶段关闭的是日志本身，不再写入日志，最后一条是关闭连接池
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("记录了 %d 条日志，期望 3 条", len(entries))
+	}
+	if last := entries[len(entries)-1].Message; !strings.HasPrefix(last, "已关闭 pools/database") {
+		t.Fatalf("最后一条日志为 %q", last)
+	}
+}
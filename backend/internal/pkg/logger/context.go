@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"blueLock/backend/internal/pkg/tracing"
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type ctxKey struct{}
+
+// NewContext 返回携带日志器的 context，请求中间件用它放入带请求 ID 等字段的子日志器
+func NewContext(ctx context.Context, log *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, log)
+}
+
+// FromContext 取出 context 中的日志器；不在请求中（后台任务、命令行）时使用 fallback，并补上链路 ID
+func FromContext(ctx context.Context, fallback *zap.SugaredLogger) *zap.SugaredLogger {
+	if log, ok := ctx.Value(ctxKey{}).(*zap.SugaredLogger); ok {
+		return log
+	}
+	return tracing.Logger(ctx, fallback)
+}
+
+// With 给 context 中的日志器追加字段，context 中没有日志器时原样返回
+func With(ctx context.Context, args ...any) context.Context {
+	log, ok := ctx.Value(ctxKey{}).(*zap.SugaredLogger)
+	if !ok {
+		return ctx
+	}
+	return NewContext(ctx, log.With(args...))
+}
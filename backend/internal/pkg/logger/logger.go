@@ -1,15 +1,41 @@
 package logger
 
 import (
+	"blueLock/backend/internal/pkg/globals"
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// 日志文件轮转的默认值
+const (
+	defaultMaxSizeMB  = 100
+	defaultMaxAgeDays = 30
+)
+
+// Levels 各输出的日志级别，运行时可修改
+type Levels struct {
+	Console zap.AtomicLevel
+	File    zap.AtomicLevel
+}
+
+// Get 按输出名称（console、file）取日志级别
+func (l Levels) Get(output string) (zap.AtomicLevel, bool) {
+	switch output {
+	case "console":
+		return l.Console, true
+	case "file":
+		return l.File, true
+	}
+	return zap.AtomicLevel{}, false
+}
+
 // GetEncoder 获取编码器
 func GetEncoder() zapcore.Encoder {
 	encoderConfig := zap.NewProductionEncoderConfig()
@@ -18,15 +44,84 @@ func GetEncoder() zapcore.Encoder {
 	return zapcore.NewJSONEncoder(encoderConfig)
 }
 
-// GetLogWriter 获取日志写入器
-func GetLogWriter(logPath, appName string) zapcore.WriteSyncer {
+// FileWriter 日志文件写入器，Close 停止按天轮转并关闭文件
+type FileWriter struct {
+	*lumberjack.Logger
+	stop   chan struct{}
+	done   chan struct{}
+	closed sync.Once
+}
+
+// Sync 实现 zapcore.WriteSyncer，lumberjack 不缓冲，直接写入文件
+func (w *FileWriter) Sync() error {
+	return nil
+}
+
+// Close 停止按天轮转并关闭当前文件，可以重复调用；之后仍有日志写入时会重新打开文件
+func (w *FileWriter) Close() error {
+	var err error
+	w.closed.Do(func() {
+		close(w.stop)
+		<-w.done
+		err = w.Logger.Close()
+	})
+	return err
+}
+
+// GetLogWriter 获取日志文件写入器：写入 <log_path>/<app_name>.log，超过 max_size 或每天零点轮转，
+// 旧文件按 compress 压缩，超过 max_age 天或 max_backups 个后删除
+func GetLogWriter(cfg globals.LogConfig) (*FileWriter, error) {
 	// 确保日志目录存在
-	if err := os.MkdirAll(logPath, os.ModePerm); err != nil {
-		log.Printf("failed to create log directory: %v\n", err)
-		return nil
+	if err := os.MkdirAll(cfg.LogPath, 0o755); err != nil {
+		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	fileName := filepath.Join(cfg.LogPath, cfg.AppName+".log")
+	// 提前打开一次，目录不可写等问题在启动时就暴露出来
+	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	_ = f.Close()
+
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSizeMB
+	}
+	maxAge := cfg.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultMaxAgeDays
+	}
+	w := &FileWriter{
+		Logger: &lumberjack.Logger{
+			Filename:   fileName,
+			MaxSize:    maxSize,
+			MaxAge:     maxAge,
+			MaxBackups: cfg.MaxBackups,
+			LocalTime:  true,
+			Compress:   cfg.Compress,
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go w.rotateDaily()
+	return w, nil
+}
+
+// rotateDaily 每天零点（本地时间）轮转一次日志文件，直到 Close
+func (w *FileWriter) rotateDaily() {
+	defer close(w.done)
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			if err := w.Rotate(); err != nil {
+				fmt.Fprintf(os.Stderr, "日志文件轮转失败: %v\n", err)
+			}
+		case <-w.stop:
+			timer.Stop()
+			return
+		}
 	}
-	currentDate := time.Now().Format("2006-01-02")
-	fileName := fmt.Sprintf("./%s/%s-%s.log", logPath, appName, currentDate)
-	file, _ := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	return zapcore.AddSync(file)
 }
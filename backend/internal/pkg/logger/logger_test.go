@@ -0,0 +1,57 @@
+package logger_test
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/logger"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileWriterClose(t *testing.T) {
+	dir := t.TempDir()
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		w, err := logger.GetLogWriter(globals.LogConfig{LogPath: dir, AppName: "app"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("重复 Close: %v", err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before+3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Close 后轮转协程没有退出: %d -> %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 关闭后仍有日志写入时重新打开文件
+	w, err := logger.GetLogWriter(globals.LogConfig{LogPath: dir, AppName: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), "line\n"); n != 2 {
+		t.Fatalf("日志文件中有 %d 行，期望 2", n)
+	}
+}
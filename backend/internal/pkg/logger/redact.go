@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const masked = "***"
+
+var (
+	emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+	jwtPattern   = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	// 消息中形如 code: 123456、password=xxx、Bearer xxx 的片段
+	secretPattern = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|code)\s*[:=]\s*"?)[^\s,"]+|(Bearer\s+)\S+`)
+)
+
+// redactCore 在写入前脱敏：键名敏感的字段整体隐藏，邮箱只保留首字母和域名，消息中的令牌、验证码、密码一并处理
+type redactCore struct {
+	zapcore.Core
+}
+
+// NewRedactCore 为 core 加上脱敏层
+func NewRedactCore(core zapcore.Core) zapcore.Core {
+	return redactCore{Core: core}
+}
+
+func (c redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = RedactString(ent.Message)
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// RedactString 脱敏一段文本中的邮箱、JWT 和 key=value 形式的密钥
+func RedactString(s string) string {
+	s = jwtPattern.ReplaceAllString(s, masked)
+	s = secretPattern.ReplaceAllString(s, "${1}${2}"+masked)
+	return emailPattern.ReplaceAllString(s, "${1}"+masked+"@${2}")
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = redactField(f)
+	}
+	return out
+}
+
+func redactField(f zapcore.Field) zapcore.Field {
+	key := strings.ToLower(f.Key)
+	switch {
+	case sensitiveKey(key):
+		return zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: masked}
+	case strings.Contains(key, "email") && f.Type == zapcore.StringType:
+		f.String = emailPattern.ReplaceAllString(f.String, "${1}"+masked+"@${2}")
+		return f
+	case f.Type == zapcore.StringType:
+		f.String = RedactString(f.String)
+		return f
+	case f.Type == zapcore.ErrorType:
+		// 错误信息中也可能带邮箱、令牌
+		if err, ok := f.Interface.(error); ok {
+			return zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: RedactString(err.Error())}
+		}
+	}
+	return f
+}
+
+// sensitiveKey 需要整体隐藏的字段名
+func sensitiveKey(key string) bool {
+	if key == "code" || strings.HasSuffix(key, "_code") {
+		return true
+	}
+	for _, s := range []string{"password", "passwd", "secret", "token", "authorization", "cookie"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+// Package mail 通过 SMTP（STARTTLS）发送 HTML 邮件
+package mail
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/metrics"
+	"blueLock/backend/internal/pkg/tracing"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Message 一封待发送的邮件
+type Message struct {
+	To       string
+	FromName string // 发件人名称，可以是中文
+	Subject  string
+	HTML     string
+}
+
+// Send 使用 cfg 中的 SMTP 服务器发送邮件 - 使用SMTP方式（修复short response问题）
+func Send(ctx context.Context, cfg globals.MailConfig, m Message) (err error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "smtp.send",
+		attribute.String("server.address", cfg.SMTPHost),
+		attribute.Int("server.port", cfg.SMTPPort),
+	)
+	defer func() {
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		metrics.SMTPDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+		tracing.End(span, err)
+	}()
+
+	// 非 ASCII 的主题和发件人名称需要按 RFC 2047 编码
+	subject := fmt.Sprintf("Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", m.Subject))
+	from := fmt.Sprintf("From: %s <%s>\r\n", mime.BEncoding.Encode("UTF-8", m.FromName), cfg.FromEmail)
+	toHeader := fmt.Sprintf("To: %s\r\n", m.To)
+	mimeVersion := "MIME-Version: 1.0\r\n"
+	contentType := "Content-Type: text/html; charset=UTF-8\r\n"
+	body := "\r\n" + m.HTML
+
+	msg := []byte(from + toHeader + subject + mimeVersion + contentType + "\r\n" + body)
+
+	// 配置TLS
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: false,
+		ServerName:         cfg.SMTPHost,
+	}
+
+	// 连接到SMTP服务器
+	addr := fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort)
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+
+	// 使用defer确保连接关闭，但不使用Quit()避免short response错误
+	defer func() {
+		if client != nil {
+			client.Close()
+		}
+	}()
+
+	// 启动TLS
+	if err = client.StartTLS(tlsConfig); err != nil {
+		return fmt.Errorf("启动TLS失败: %w", err)
+	}
+
+	// 认证
+	auth := smtp.PlainAuth("", cfg.FromEmail, cfg.Password, cfg.SMTPHost)
+	if err = client.Auth(auth); err != nil {
+		return fmt.Errorf("SMTP认证失败: %w", err)
+	}
+
+	// 设置发件人和收件人
+	if err = client.Mail(cfg.FromEmail); err != nil {
+		return fmt.Errorf("设置发件人失败: %w", err)
+	}
+	if err = client.Rcpt(m.To); err != nil {
+		return fmt.Errorf("设置收件人失败: %w", err)
+	}
+
+	// 发送邮件内容
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("准备发送数据失败: %w", err)
+	}
+	if _, err = writer.Write(msg); err != nil {
+		return fmt.Errorf("写入邮件内容失败: %w", err)
+	}
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("关闭数据流失败: %w", err)
+	}
+
+	// 邮件已成功发送，直接返回，让defer处理连接关闭
+	// 不调用Quit()避免short response错误
+	return nil
+}
+
+// Ping 连接 SMTP 服务器并读取欢迎信息，用于健康检查，不做认证也不发信
+func Ping(ctx context.Context, cfg globals.MailConfig) error {
+	addr := fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort)
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		return fmt.Errorf("读取SMTP欢迎信息失败: %w", err)
+	}
+	// 与 Send 一致，不调用 Quit()
+	return client.Close()
+}
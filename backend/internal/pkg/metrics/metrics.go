@@ -0,0 +1,128 @@
+// Package metrics Prometheus 指标。所有标签取值都来自有限集合（路由模板、错误 Key 等），不能直接使用 URL、邮箱等用户输入
+package metrics
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bluelock"
+
+// 登录方式
+const (
+	LoginMethodPassword = "password"
+	LoginMethodCode     = "code"
+	LoginMethodMagic    = "magic_link"
+)
+
+// ResultSuccess 成功时的 result 标签，失败时使用错误的 Key
+const ResultSuccess = "success"
+
+// Registry 本服务的指标注册表，不使用 prometheus 的全局注册表，避免第三方库引入无关指标
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests 请求数，route 为 gin 的路由模板，未匹配到路由时为 unmatched
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP 请求数",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration 请求耗时
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP 请求耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// Logins 登录次数，result 为 success 或错误 Key
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "logins_total",
+		Help:      "登录次数",
+	}, []string{"method", "result"})
+
+	// VerificationCodes 验证码发送次数，result 为 sent 或 failed
+	VerificationCodes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "verification_codes_total",
+		Help:      "验证码发送次数",
+	}, []string{"result"})
+
+	// TokenRefreshes 刷新令牌次数，result 为 success 或错误 Key
+	TokenRefreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "token_refreshes_total",
+		Help:      "刷新令牌次数",
+	}, []string{"result"})
+
+	// Notifications 安全通知邮件发送次数，kind 为通知类型，result 为 sent 或 failed
+	Notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "mail",
+		Name:      "notifications_total",
+		Help:      "安全通知邮件发送次数",
+	}, []string{"kind", "result"})
+
+	// Challenges 人机校验次数，type 为校验方式，result 为 issued、passed、failed 或 rejected（获取题目过于频繁）
+	Challenges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "challenges_total",
+		Help:      "人机校验次数",
+	}, []string{"type", "result"})
+
+	// SMTPDuration 发信耗时，result 为 success 或 error
+	SMTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "mail",
+		Name:      "smtp_duration_seconds",
+		Help:      "SMTP 发信耗时（秒）",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		Logins,
+		VerificationCodes,
+		TokenRefreshes,
+		Notifications,
+		Challenges,
+		SMTPDuration,
+	)
+}
+
+// Handler 以 Prometheus 文本格式输出指标
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB 采集数据库连接池（sql.DBStats），name 用作 db_name 标签
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Result 把错误转换为 result 标签：nil 为 success，其余为领域错误的 Key
+func Result(err error) string {
+	if err == nil {
+		return ResultSuccess
+	}
+	return apperr.From(err).Key
+}
@@ -0,0 +1,323 @@
+// Package migrate 版本化的数据库迁移：每个迁移有唯一递增的版本号和 up/down 两个方向，
+// 已执行的版本记录在 schema_migrations 表中，执行期间通过锁表防止多个实例同时迁移。
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 一个迁移步骤，Up/Down 在同一个事务中执行（MySQL 的 DDL 会隐式提交，迁移需保证可重复执行）
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	// Down 为空表示该迁移不可回滚
+	Down func(tx *gorm.DB) error
+}
+
+// SchemaMigration 已执行迁移的记录
+type SchemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255;not null"`
+	AppliedAt time.Time
+}
+
+// SchemaMigrationLock 迁移锁，表中最多一行，插入成功即获得锁。
+// 持有期间定期刷新 LockedAt，超过过期时间未刷新说明持有者已异常退出，其他实例可以接管
+type SchemaMigrationLock struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string `gorm:"size:255;not null"`
+	LockedAt time.Time
+}
+
+// Status 单个迁移的执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// ErrLocked 迁移锁被其他实例持有
+var ErrLocked = errors.New("迁移锁被占用")
+
+const lockID = 1
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db          *gorm.DB
+	migrations  []Migration
+	owner       string
+	lockTimeout time.Duration
+	lockExpiry  time.Duration
+	logf        func(format string, args ...any)
+}
+
+// Option Migrator 的可选配置
+type Option func(m *Migrator)
+
+// WithLockTimeout 设置等待迁移锁的最长时间，默认 30 秒
+func WithLockTimeout(d time.Duration) Option {
+	return func(m *Migrator) { m.lockTimeout = d }
+}
+
+// WithLockExpiry 设置迁移锁的过期时间，默认 5 分钟。持有者每隔三分之一过期时间刷新一次加锁时间，
+// 超过过期时间未刷新的锁会被其他实例接管
+func WithLockExpiry(d time.Duration) Option {
+	return func(m *Migrator) { m.lockExpiry = d }
+}
+
+// WithLogf 设置迁移过程的日志输出
+func WithLogf(logf func(format string, args ...any)) Option {
+	return func(m *Migrator) { m.logf = logf }
+}
+
+// New 创建迁移执行器，版本号重复或不大于 0 时返回错误
+func New(db *gorm.DB, migrations []Migration, opts ...Option) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, mg := range sorted {
+		if mg.Version <= 0 || mg.Up == nil {
+			return nil, fmt.Errorf("迁移 %d(%s) 定义不完整", mg.Version, mg.Name)
+		}
+		if i > 0 && sorted[i-1].Version == mg.Version {
+			return nil, fmt.Errorf("迁移版本 %d 重复", mg.Version)
+		}
+	}
+	host, _ := os.Hostname()
+	m := &Migrator{
+		db:          db,
+		migrations:  sorted,
+		owner:       fmt.Sprintf("%s:%d", host, os.Getpid()),
+		lockTimeout: 30 * time.Second,
+		lockExpiry:  5 * time.Minute,
+		logf:        func(string, ...any) {},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// Up 依次执行所有未执行的迁移，返回本次执行的数量
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	var count int
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		for _, mg := range m.migrations {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			m.logf("执行迁移 %d_%s", mg.Version, mg.Name)
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := mg.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: mg.Version, Name: mg.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("迁移 %d_%s 失败: %w", mg.Version, mg.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down 按版本从新到旧回滚 steps 个已执行的迁移，返回本次回滚的数量
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	var count int
+	err := m.withLock(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			mg := m.migrations[i]
+			if _, ok := applied[mg.Version]; !ok {
+				continue
+			}
+			if mg.Down == nil {
+				return fmt.Errorf("迁移 %d_%s 不可回滚", mg.Version, mg.Name)
+			}
+			m.logf("回滚迁移 %d_%s", mg.Version, mg.Name)
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := mg.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, mg.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("回滚 %d_%s 失败: %w", mg.Version, mg.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status 返回每个迁移的执行状态，按版本升序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	db := m.db.WithContext(ctx)
+	if err := m.ensureTables(db); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Status, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		s := Status{Version: mg.Version, Name: mg.Name}
+		if rec, ok := applied[mg.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = &rec.AppliedAt
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// Pending 返回未执行的迁移数量，服务启动时据此判断表结构是否为最新
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	list, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, s := range list {
+		if !s.Applied {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// ForceUnlock 强制释放迁移锁，用于迁移进程异常退出后不想等待锁过期的情况
+func (m *Migrator) ForceUnlock(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	if err := m.ensureTables(db); err != nil {
+		return err
+	}
+	return db.Delete(&SchemaMigrationLock{}, lockID).Error
+}
+
+// withLock 获取迁移锁后执行 fn，结束后释放
+func (m *Migrator) withLock(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if err := m.ensureTables(db); err != nil {
+		return err
+	}
+	if err := m.acquire(ctx, db); err != nil {
+		return err
+	}
+	stop := m.heartbeat()
+	defer func() {
+		stop()
+		// 使用独立的 context，避免 ctx 取消后锁无法释放
+		err := m.db.Where("id = ? AND owner = ?", lockID, m.owner).Delete(&SchemaMigrationLock{}).Error
+		if err != nil {
+			m.logf("释放迁移锁失败: %v", err)
+		}
+	}()
+	return fn(db)
+}
+
+// heartbeat 定期刷新加锁时间，避免耗时较长的迁移被当作过期锁接管，返回停止函数
+func (m *Migrator) heartbeat() (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(m.lockExpiry / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := m.db.Model(&SchemaMigrationLock{}).
+					Where("id = ? AND owner = ?", lockID, m.owner).
+					Update("locked_at", time.Now()).Error
+				if err != nil {
+					m.logf("刷新迁移锁失败: %v", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// acquire 插入锁记录，主键冲突说明锁被占用，轮询等待直到超时。锁已过期时删除后重试
+func (m *Migrator) acquire(ctx context.Context, db *gorm.DB) error {
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		err := db.Create(&SchemaMigrationLock{ID: lockID, Owner: m.owner, LockedAt: time.Now()}).Error
+		if err == nil {
+			return nil
+		}
+		var holder SchemaMigrationLock
+		if db.First(&holder, lockID).Error != nil {
+			// 锁记录不存在，说明不是锁冲突，而是其他错误
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				continue
+			}
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if cutoff := time.Now().Add(-m.lockExpiry); holder.LockedAt.Before(cutoff) {
+			// 只删除仍未刷新的锁，持有者恰好在此期间刷新时不受影响
+			res := db.Where("id = ? AND owner = ? AND locked_at < ?", lockID, holder.Owner, cutoff).
+				Delete(&SchemaMigrationLock{})
+			if res.Error != nil {
+				return fmt.Errorf("删除过期的迁移锁失败: %w", res.Error)
+			}
+			if res.RowsAffected > 0 {
+				m.logf("迁移锁已过期，接管 %s 的锁（加锁时间 %s）", holder.Owner, holder.LockedAt.Format(time.RFC3339))
+				continue
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: 持有者 %s，加锁时间 %s", ErrLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339))
+		}
+		m.logf("迁移锁被 %s 持有，等待中...", holder.Owner)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (m *Migrator) ensureTables(db *gorm.DB) error {
+	if err := db.AutoMigrate(&SchemaMigration{}, &SchemaMigrationLock{}); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(db *gorm.DB) (map[int64]SchemaMigration, error) {
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %w", err)
+	}
+	applied := make(map[int64]SchemaMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
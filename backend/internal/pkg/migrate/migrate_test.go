@@ -0,0 +1,289 @@
+package migrate_test
+
+import (
+	"blueLock/backend/internal/pkg/database"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/migrate"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openDB 打开 path 指向的 SQLite 文件，同一个文件可以打开多次，模拟多个实例
+func openDB(t *testing.T, path string) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(globals.DatabaseConfig{Driver: database.DriverSQLite, Path: path},
+		logger.Default.LogMode(logger.Silent))
+	if err != nil {
+		t.Fatalf("打开 SQLite 失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// recorder 记录迁移的执行顺序
+type recorder struct {
+	mu  sync.Mutex
+	ops []string
+}
+
+func (r *recorder) migration(version int64, name string) migrate.Migration {
+	return migrate.Migration{
+		Version: version,
+		Name:    name,
+		Up:      func(*gorm.DB) error { r.add("up " + name); return nil },
+		Down:    func(*gorm.DB) error { r.add("down " + name); return nil },
+	}
+}
+
+func (r *recorder) add(op string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = append(r.ops, op)
+}
+
+func (r *recorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ops := r.ops
+	r.ops = nil
+	return ops
+}
+
+func TestNewRejectsInvalid(t *testing.T) {
+	db := openDB(t, filepath.Join(t.TempDir(), "m.db"))
+	var r recorder
+	if _, err := migrate.New(db, []migrate.Migration{r.migration(1, "a"), r.migration(1, "b")}); err == nil {
+		t.Fatal("重复的版本号应当报错")
+	}
+	if _, err := migrate.New(db, []migrate.Migration{{Version: 0, Name: "zero", Up: func(*gorm.DB) error { return nil }}}); err == nil {
+		t.Fatal("版本号为 0 应当报错")
+	}
+	if _, err := migrate.New(db, []migrate.Migration{{Version: 1, Name: "no_up"}}); err == nil {
+		t.Fatal("缺少 Up 应当报错")
+	}
+}
+
+func TestUpDownOrder(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "m.db"))
+	var r recorder
+	// 注册顺序与版本顺序不同，执行时按版本排序
+	m, err := migrate.New(db, []migrate.Migration{r.migration(3, "c"), r.migration(1, "a"), r.migration(2, "b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := m.Up(ctx); err != nil || n != 3 {
+		t.Fatalf("Up = %d, %v", n, err)
+	}
+	if ops := r.take(); !slices.Equal(ops, []string{"up a", "up b", "up c"}) {
+		t.Fatalf("执行顺序不正确: %v", ops)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 0 {
+		t.Fatalf("重复执行 Up = %d, %v", n, err)
+	}
+	if pending, err := m.Pending(ctx); err != nil || pending != 0 {
+		t.Fatalf("Pending = %d, %v", pending, err)
+	}
+
+	if n, err := m.Down(ctx, 2); err != nil || n != 2 {
+		t.Fatalf("Down = %d, %v", n, err)
+	}
+	if ops := r.take(); !slices.Equal(ops, []string{"down c", "down b"}) {
+		t.Fatalf("回滚顺序不正确: %v", ops)
+	}
+	list, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied []bool
+	for _, s := range list {
+		applied = append(applied, s.Applied)
+	}
+	if !slices.Equal(applied, []bool{true, false, false}) {
+		t.Fatalf("回滚后状态不正确: %v", applied)
+	}
+
+	// 新增的迁移版本较小时也会被执行
+	m, err = migrate.New(db, []migrate.Migration{r.migration(1, "a"), r.migration(2, "b"), r.migration(3, "c")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 2 {
+		t.Fatalf("Up = %d, %v", n, err)
+	}
+	if ops := r.take(); !slices.Equal(ops, []string{"up b", "up c"}) {
+		t.Fatalf("执行顺序不正确: %v", ops)
+	}
+}
+
+func TestFailedMigrationStops(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "m.db"))
+	var r recorder
+	failing := migrate.Migration{Version: 2, Name: "fail", Up: func(*gorm.DB) error { return errors.New("boom") }}
+	m, err := migrate.New(db, []migrate.Migration{r.migration(1, "a"), failing, r.migration(3, "c")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := m.Up(ctx); err == nil || n != 1 {
+		t.Fatalf("Up = %d, %v，期望执行 1 个后失败", n, err)
+	}
+	if ops := r.take(); !slices.Equal(ops, []string{"up a"}) {
+		t.Fatalf("失败后不应继续执行: %v", ops)
+	}
+	if pending, _ := m.Pending(ctx); pending != 2 {
+		t.Fatalf("Pending = %d，期望 2", pending)
+	}
+
+	irreversible := migrate.Migration{Version: 1, Name: "a", Up: func(*gorm.DB) error { return nil }}
+	m, _ = migrate.New(db, []migrate.Migration{irreversible})
+	if _, err := m.Down(ctx, 1); err == nil {
+		t.Fatal("不可回滚的迁移应当报错")
+	}
+}
+
+func TestLockHeldByOtherInstance(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "m.db")
+	var r recorder
+
+	// 第一个实例在迁移中途暂停，持有锁
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := migrate.Migration{Version: 1, Name: "slow", Up: func(*gorm.DB) error {
+		close(started)
+		<-release
+		return nil
+	}}
+	first, err := migrate.New(openDB(t, path), []migrate.Migration{slow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := first.Up(ctx)
+		done <- err
+	}()
+	<-started
+
+	second, err := migrate.New(openDB(t, path), []migrate.Migration{r.migration(1, "slow")},
+		migrate.WithLockTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Up(ctx); !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("锁被持有时应当返回 ErrLocked，实际为 %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("第一个实例迁移失败: %v", err)
+	}
+	// 锁释放后第二个实例可以执行，迁移已由第一个实例完成
+	if n, err := second.Up(ctx); err != nil || n != 0 {
+		t.Fatalf("Up = %d, %v", n, err)
+	}
+	if ops := r.take(); len(ops) != 0 {
+		t.Fatalf("已执行的迁移被重复执行: %v", ops)
+	}
+}
+
+func TestStaleLockTakenOver(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "m.db"))
+	var r recorder
+	m, err := migrate.New(db, []migrate.Migration{r.migration(1, "a")},
+		migrate.WithLockTimeout(0), migrate.WithLockExpiry(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Status(ctx); err != nil { // 建表
+		t.Fatal(err)
+	}
+
+	// 未过期的锁不能接管
+	lock := migrate.SchemaMigrationLock{ID: 1, Owner: "crashed:1", LockedAt: time.Now().Add(-30 * time.Second)}
+	if err := db.Create(&lock).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("未过期的锁被接管: %v", err)
+	}
+
+	// 超过过期时间未刷新的锁被接管
+	if err := db.Model(&lock).Update("locked_at", time.Now().Add(-2*time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 1 {
+		t.Fatalf("Up = %d, %v", n, err)
+	}
+	var count int64
+	if err := db.Model(&migrate.SchemaMigrationLock{}).Count(&count).Error; err != nil || count != 0 {
+		t.Fatalf("迁移结束后锁未释放: %d, %v", count, err)
+	}
+}
+
+func TestHeartbeatKeepsLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "m.db")
+	const expiry = 300 * time.Millisecond
+
+	// 迁移耗时超过过期时间，期间其他实例仍不能接管
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := migrate.Migration{Version: 1, Name: "slow", Up: func(*gorm.DB) error {
+		close(started)
+		<-release
+		return nil
+	}}
+	first, _ := migrate.New(openDB(t, path), []migrate.Migration{slow}, migrate.WithLockExpiry(expiry))
+	done := make(chan error, 1)
+	go func() {
+		_, err := first.Up(ctx)
+		done <- err
+	}()
+	<-started
+	time.Sleep(2 * expiry)
+
+	var r recorder
+	second, _ := migrate.New(openDB(t, path), []migrate.Migration{r.migration(1, "slow")},
+		migrate.WithLockTimeout(0), migrate.WithLockExpiry(expiry))
+	_, err := second.Up(ctx)
+	close(release)
+	if !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("持有者仍在刷新的锁被接管: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestForceUnlock(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "m.db"))
+	var r recorder
+	m, _ := migrate.New(db, []migrate.Migration{r.migration(1, "a")}, migrate.WithLockTimeout(0))
+	if _, err := m.Status(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&migrate.SchemaMigrationLock{ID: 1, Owner: "crashed:1", LockedAt: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ForceUnlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 1 {
+		t.Fatalf("Up = %d, %v", n, err)
+	}
+}
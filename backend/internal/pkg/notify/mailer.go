@@ -0,0 +1,38 @@
+// Package notify 模板化的邮件通知：验证码邮件和账号安全通知都经由 Mailer 渲染 i18n 模板后通过 SMTP 发送
+package notify
+
+import (
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/pkg/mail"
+	"context"
+)
+
+// Mailer 渲染并发送模板邮件。模板 templates/<locale>/<name>.html 的主题取自 mail.<name>.subject
+type Mailer struct {
+	cfg globals.MailConfig
+}
+
+// NewMailer 创建模板邮件发送器
+func NewMailer(cfg globals.MailConfig) *Mailer {
+	return &Mailer{cfg: cfg}
+}
+
+// Enabled 是否配置了 SMTP 服务器
+func (m *Mailer) Enabled() bool {
+	return m.cfg.SMTPHost != ""
+}
+
+// Send 用 locale 语言渲染模板 name 并发送给 to
+func (m *Mailer) Send(ctx context.Context, to, locale, name string, data map[string]any) error {
+	html, err := i18n.Render(locale, name, data)
+	if err != nil {
+		return err
+	}
+	return mail.Send(ctx, m.cfg, mail.Message{
+		To:       to,
+		FromName: i18n.T(locale, "mail.from_name"),
+		Subject:  i18n.T(locale, "mail."+name+".subject"),
+		HTML:     html,
+	})
+}
@@ -0,0 +1,237 @@
+package notify
+
+import (
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/clientinfo"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/pkg/metrics"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Kind 通知类型，对应模板 notify_<kind>.html
+type Kind string
+
+// 通知类型
+const (
+	KindNewLogin         Kind = "new_login"          // 新设备登录
+	KindPasswordChanged  Kind = "password_changed"   // 修改密码
+	KindEmailChanged     Kind = "email_changed"      // 修改邮箱，发往旧邮箱并带撤销链接
+	KindTwoFactorChanged Kind = "two_factor_changed" // 开启或关闭两步验证；两步验证尚未实现，类型和模板留给该功能使用
+	KindTokenReuse       Kind = "token_reuse"        // 已轮换的刷新令牌被再次使用，令牌可能泄露
+)
+
+// kinds 全部通知类型及是否为关键通知，关键通知不能退订
+var kinds = []struct {
+	kind     Kind
+	critical bool
+}{
+	{KindNewLogin, false},
+	{KindPasswordChanged, true},
+	{KindEmailChanged, true},
+	{KindTwoFactorChanged, true},
+	{KindTokenReuse, true},
+}
+
+const defaultSendTimeout = 30 * time.Second
+
+// Setting 用户对一种通知的设置
+type Setting struct {
+	Kind     Kind `json:"kind"`
+	Critical bool `json:"critical"` // 关键通知始终发送，不能关闭
+	Enabled  bool `json:"enabled"`
+}
+
+// Lookup 查询通知类型，ok 为 false 表示没有这种通知
+func Lookup(kind Kind) (critical, ok bool) {
+	for _, k := range kinds {
+		if k.kind == kind {
+			return k.critical, true
+		}
+	}
+	return false, false
+}
+
+// Settings 根据用户的退订列表（models.User.NotifyOptOut）列出全部通知的设置
+func Settings(optOut string) []Setting {
+	settings := make([]Setting, 0, len(kinds))
+	for _, k := range kinds {
+		settings = append(settings, Setting{
+			Kind:     k.kind,
+			Critical: k.critical,
+			Enabled:  k.critical || !optedOut(optOut, k.kind),
+		})
+	}
+	return settings
+}
+
+// SetEnabled 返回开启或关闭 kind 后的退订列表
+func SetEnabled(optOut string, kind Kind, enabled bool) string {
+	out := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		off := optedOut(optOut, k.kind)
+		if k.kind == kind {
+			off = !enabled
+		}
+		if off {
+			out = append(out, string(k.kind))
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+func optedOut(optOut string, kind Kind) bool {
+	for _, k := range strings.Split(optOut, ",") {
+		if Kind(strings.TrimSpace(k)) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Event 一条待发送的通知
+type Event struct {
+	Kind Kind
+	User *models.User
+	To   string         // 收件人，为空时发给 User.Email
+	Data map[string]any // 模板数据，IP、Device、Time 由 Notifier 补充
+}
+
+// Notifier 异步发送账号安全通知。通知不影响业务结果：发送失败只记录日志，
+// 请求结束（context 取消）也不会中断发送，退出时由 Close 等待发送中的通知
+type Notifier struct {
+	mailer  *Mailer
+	timeout time.Duration
+	log     *zap.SugaredLogger
+
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewNotifier 创建通知发送器
+func NewNotifier(mailer *Mailer, cfg globals.NotifyConfig, log *zap.SugaredLogger) *Notifier {
+	timeout := cfg.SendTimeout
+	if timeout <= 0 {
+		timeout = defaultSendTimeout
+	}
+	return &Notifier{mailer: mailer, timeout: timeout, log: log}
+}
+
+// Notify 在后台发送通知。非关键通知在用户退订时不发送；IP 和设备取自 ctx 中的 clientinfo
+func (n *Notifier) Notify(ctx context.Context, e Event) {
+	log := logger.FromContext(ctx, n.log)
+	critical, ok := Lookup(e.Kind)
+	if !ok || e.User == nil {
+		log.Errorf("无效的通知 kind=%s", e.Kind)
+		return
+	}
+	if !critical && optedOut(e.User.NotifyOptOut, e.Kind) {
+		log.Debugf("用户已退订通知 kind=%s userID=%d", e.Kind, e.User.ID)
+		return
+	}
+	if !n.mailer.Enabled() {
+		log.Debugf("未配置邮件服务，跳过通知 kind=%s userID=%d", e.Kind, e.User.ID)
+		return
+	}
+
+	to := e.To
+	if to == "" {
+		to = e.User.Email
+	}
+	locale := e.User.Locale
+	if !i18n.IsSupported(locale) {
+		locale = i18n.DefaultLocale
+	}
+	data := n.templateData(ctx, locale, e)
+
+	n.goSend(ctx, to, locale, "notify_"+string(e.Kind), data, func(err error) {
+		if err != nil {
+			metrics.Notifications.WithLabelValues(string(e.Kind), "failed").Inc()
+			log.Warnf("发送通知失败 kind=%s userID=%d err=%v", e.Kind, e.User.ID, err)
+			return
+		}
+		metrics.Notifications.WithLabelValues(string(e.Kind), "sent").Inc()
+	})
+}
+
+// Deliver 在后台发送一封模板邮件，不检查退订设置，失败只记录日志。
+// 用于接口结果不能取决于邮件是否发送成功的场景，例如不能据此判断邮箱是否注册
+func (n *Notifier) Deliver(ctx context.Context, to, locale, name string, data map[string]any) {
+	log := logger.FromContext(ctx, n.log)
+	if !n.mailer.Enabled() {
+		log.Warnf("未配置邮件服务，跳过邮件 name=%s", name)
+		return
+	}
+	n.goSend(ctx, to, locale, name, data, func(err error) {
+		if err != nil {
+			log.Warnf("发送邮件失败 name=%s err=%v", name, err)
+		}
+	})
+}
+
+// goSend 在后台发送邮件，发送结束后调用 done。请求结束不会中断发送，服务关闭后丢弃新邮件
+func (n *Notifier) goSend(ctx context.Context, to, locale, name string, data map[string]any, done func(err error)) {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		logger.FromContext(ctx, n.log).Warnf("通知服务已关闭，丢弃邮件 name=%s", name)
+		return
+	}
+	n.wg.Add(1)
+	n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), n.timeout)
+	go func() {
+		defer n.wg.Done()
+		defer cancel()
+		done(n.mailer.Send(ctx, to, locale, name, data))
+	}()
+}
+
+// templateData 合并调用方的数据和请求方信息
+func (n *Notifier) templateData(ctx context.Context, locale string, e Event) map[string]any {
+	info := clientinfo.FromContext(ctx)
+	unknown := i18n.T(locale, "notify.unknown")
+	data := map[string]any{
+		"Email":  e.User.Email,
+		"IP":     info.IP,
+		"Device": info.Device(),
+		"Time":   time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
+	}
+	if data["IP"] == "" {
+		data["IP"] = unknown
+	}
+	if data["Device"] == "" {
+		data["Device"] = unknown
+	}
+	for k, v := range e.Data {
+		data[k] = v
+	}
+	return data
+}
+
+// Close 不再接受新通知，并等待发送中的通知完成或 ctx 结束
+func (n *Notifier) Close(ctx context.Context) error {
+	n.mu.Lock()
+	n.closed = true
+	n.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,219 @@
+package password
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/tracing"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 支持的哈希算法
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	defaultMemory      = 64 * 1024
+	defaultIterations  = 3
+	defaultParallelism = 2
+	defaultMaxWait     = 3 * time.Second
+
+	saltLength = 16
+	keyLength  = 32
+)
+
+// ErrUnsupportedHash 数据库中的哈希既不是 argon2id 也不是 bcrypt 格式
+var ErrUnsupportedHash = errors.New("不支持的密码哈希格式")
+
+// Hasher 计算和校验密码哈希。新哈希使用配置的算法，argon2id 以 PHC 格式存储：
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+//
+// bcrypt 哈希（$2a$、$2b$、$2y$）始终可以校验。两种算法都很耗 CPU（argon2id 还占用内存），
+// 同时进行的计算数受信号量限制，登录请求过多时排队，排队超过 MaxWait 返回 ErrUnavailable
+type Hasher struct {
+	cfg globals.PasswordHashConfig
+	sem chan struct{}
+
+	dummyOnce sync.Once
+	dummy     string
+}
+
+// NewHasher 根据配置创建密码哈希器，未配置的参数使用默认值
+func NewHasher(cfg globals.PasswordHashConfig) *Hasher {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = AlgorithmArgon2id
+	}
+	if cfg.Memory == 0 {
+		cfg.Memory = defaultMemory
+	}
+	if cfg.Iterations == 0 {
+		cfg.Iterations = defaultIterations
+	}
+	if cfg.Parallelism == 0 {
+		cfg.Parallelism = defaultParallelism
+	}
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = runtime.NumCPU()
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = defaultMaxWait
+	}
+	return &Hasher{cfg: cfg, sem: make(chan struct{}, cfg.Concurrency)}
+}
+
+// Hash 使用配置的算法计算密码哈希。bcrypt 只使用密码的前 72 个字节，超出时返回错误
+func (h *Hasher) Hash(ctx context.Context, password string) (encoded string, err error) {
+	ctx, span := tracing.Start(ctx, "password.hash", attribute.String("password.algorithm", h.cfg.Algorithm))
+	defer func() { tracing.End(span, err) }()
+
+	if err := h.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer h.release()
+	return h.hash(password)
+}
+
+func (h *Hasher) hash(password string) (string, error) {
+	if h.cfg.Algorithm == AlgorithmBcrypt {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+		return string(hashed), err
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.cfg.Iterations, h.cfg.Memory, h.cfg.Parallelism, keyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.cfg.Memory, h.cfg.Iterations, h.cfg.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify 校验密码。rehash 为 true 表示密码正确但哈希的算法或参数与当前配置不同，调用方应重新计算并保存。
+// encoded 为空（用户不存在或未设置密码）时仍计算一次哈希再返回 false，避免通过响应时间判断账号是否存在
+func (h *Hasher) Verify(ctx context.Context, encoded, password string) (ok, rehash bool, err error) {
+	ctx, span := tracing.Start(ctx, "password.verify", attribute.String("password.algorithm", algorithmOf(encoded)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := h.acquire(ctx); err != nil {
+		return false, false, err
+	}
+	defer h.release()
+
+	if encoded == "" {
+		h.dummyOnce.Do(func() { h.dummy, _ = h.hash("dummy password") })
+		_, _, _ = h.verify(h.dummy, password)
+		return false, false, nil
+	}
+	return h.verify(encoded, password)
+}
+
+func (h *Hasher) verify(encoded, password string) (ok, rehash bool, err error) {
+	switch algorithmOf(encoded) {
+	case AlgorithmBcrypt:
+		if bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) != nil {
+			return false, false, nil
+		}
+		cost, _ := bcrypt.Cost([]byte(encoded))
+		return true, h.cfg.Algorithm != AlgorithmBcrypt || cost != h.cfg.BcryptCost, nil
+	case AlgorithmArgon2id:
+		p, salt, key, err := parseArgon2id(encoded)
+		if err != nil {
+			return false, false, err
+		}
+		actual := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(actual, key) != 1 {
+			return false, false, nil
+		}
+		outdated := h.cfg.Algorithm != AlgorithmArgon2id ||
+			p.memory != h.cfg.Memory || p.iterations != h.cfg.Iterations || p.parallelism != h.cfg.Parallelism ||
+			len(salt) != saltLength || len(key) != keyLength
+		return true, outdated, nil
+	default:
+		return false, false, ErrUnsupportedHash
+	}
+}
+
+// acquire 获取计算名额，请求取消或排队超时返回错误
+func (h *Hasher) acquire(ctx context.Context) error {
+	select {
+	case h.sem <- struct{}{}:
+		return nil
+	default:
+	}
+	timer := time.NewTimer(h.cfg.MaxWait)
+	defer timer.Stop()
+	select {
+	case h.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return apperr.ErrUnavailable.Wrap(errors.New("密码哈希计算排队超时"))
+	}
+}
+
+func (h *Hasher) release() {
+	<-h.sem
+}
+
+// algorithmOf 根据前缀判断哈希算法，无法识别时返回空字符串
+func algorithmOf(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return AlgorithmBcrypt
+	}
+	return ""
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// parseArgon2id 解析 PHC 格式的 argon2id 哈希
+func parseArgon2id(encoded string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrUnsupportedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("%w: argon2 版本 %q", ErrUnsupportedHash, parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: argon2 参数 %q", ErrUnsupportedHash, parts[3])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", ErrUnsupportedHash, err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, fmt.Errorf("%w: argon2 哈希值无效", ErrUnsupportedHash)
+	}
+	return p, salt, key, nil
+}
@@ -0,0 +1,144 @@
+package password_test
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/password"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 测试使用很小的 argon2id 参数，保证速度
+var fast = globals.PasswordHashConfig{Memory: 64, Iterations: 1, Parallelism: 1, BcryptCost: bcrypt.MinCost}
+
+func verify(t *testing.T, h *password.Hasher, encoded, pass string) (ok, rehash bool) {
+	t.Helper()
+	ok, rehash, err := h.Verify(context.Background(), encoded, pass)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return ok, rehash
+}
+
+func TestArgon2id(t *testing.T) {
+	ctx := context.Background()
+	h := password.NewHasher(fast)
+	encoded, err := h.Hash(ctx, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	phc := regexp.MustCompile(`^\$argon2id\$v=19\$m=64,t=1,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`)
+	if !phc.MatchString(encoded) {
+		t.Fatalf("哈希不是 PHC 格式: %s", encoded)
+	}
+	if again, _ := h.Hash(ctx, "correct horse"); again == encoded {
+		t.Fatal("两次哈希使用了相同的盐")
+	}
+
+	if ok, rehash := verify(t, h, encoded, "correct horse"); !ok || rehash {
+		t.Fatalf("正确密码: ok=%v rehash=%v", ok, rehash)
+	}
+	if ok, _ := verify(t, h, encoded, "wrong horse"); ok {
+		t.Fatal("错误密码校验通过")
+	}
+
+	// bcrypt 只使用前 72 个字节，argon2id 没有这个限制
+	long := strings.Repeat("a", 72)
+	encoded, _ = h.Hash(ctx, long+"1")
+	if ok, _ := verify(t, h, encoded, long+"2"); ok {
+		t.Fatal("超过 72 字节的部分没有参与计算")
+	}
+}
+
+func TestRehash(t *testing.T) {
+	ctx := context.Background()
+	old := password.NewHasher(fast)
+	encoded, err := old.Hash(ctx, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 参数变化后旧哈希仍能校验，但要求重新计算
+	stronger := fast
+	stronger.Iterations = 2
+	if ok, rehash := verify(t, password.NewHasher(stronger), encoded, "pw"); !ok || !rehash {
+		t.Fatalf("参数变化: ok=%v rehash=%v", ok, rehash)
+	}
+	// 密码错误时不要求重新计算
+	if ok, rehash := verify(t, password.NewHasher(stronger), encoded, "bad"); ok || rehash {
+		t.Fatalf("密码错误: ok=%v rehash=%v", ok, rehash)
+	}
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, rehash := verify(t, old, string(legacy), "pw"); !ok || !rehash {
+		t.Fatalf("bcrypt 升级为 argon2id: ok=%v rehash=%v", ok, rehash)
+	}
+
+	bcryptCfg := fast
+	bcryptCfg.Algorithm = password.AlgorithmBcrypt
+	if ok, rehash := verify(t, password.NewHasher(bcryptCfg), string(legacy), "pw"); !ok || rehash {
+		t.Fatalf("bcrypt 参数相同: ok=%v rehash=%v", ok, rehash)
+	}
+	bcryptCfg.BcryptCost = bcrypt.MinCost + 1
+	if ok, rehash := verify(t, password.NewHasher(bcryptCfg), string(legacy), "pw"); !ok || !rehash {
+		t.Fatalf("bcrypt 成本变化: ok=%v rehash=%v", ok, rehash)
+	}
+	if ok, rehash := verify(t, password.NewHasher(bcryptCfg), encoded, "pw"); !ok || !rehash {
+		t.Fatalf("argon2id 降级为 bcrypt: ok=%v rehash=%v", ok, rehash)
+	}
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	h := password.NewHasher(fast)
+	// 用户不存在时不报错，只返回不匹配
+	if ok, rehash := verify(t, h, "", "pw"); ok || rehash {
+		t.Fatal("空哈希校验通过")
+	}
+	for _, encoded := range []string{
+		"plaintext",
+		"$argon2i$v=19$m=64,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=x$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA",
+	} {
+		if _, _, err := h.Verify(context.Background(), encoded, "pw"); !errors.Is(err, password.ErrUnsupportedHash) {
+			t.Errorf("Verify(%q) 错误 = %v", encoded, err)
+		}
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	// 只有一个名额，用很高的 bcrypt 成本让第一个计算占住它
+	cfg := fast
+	cfg.Algorithm = password.AlgorithmBcrypt
+	cfg.BcryptCost = 14
+	cfg.Concurrency = 1
+	cfg.MaxWait = 20 * time.Millisecond
+	h := password.NewHasher(cfg)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = h.Hash(context.Background(), "pw")
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if _, err := h.Hash(context.Background(), "pw"); !errors.Is(err, apperr.ErrUnavailable) {
+		t.Fatalf("排队超时应当返回 ErrUnavailable，实际为 %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := h.Verify(ctx, "", "pw"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("请求取消后应当返回 context.Canceled，实际为 %v", err)
+	}
+	<-done
+}
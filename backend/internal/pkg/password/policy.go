@@ -0,0 +1,124 @@
+// Package password 密码策略与密码哈希：设置新密码时校验长度、字符类别，并拒绝内置列表中的常见弱密码；哈希默认使用 argon2id
+package password
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/globals"
+	_ "embed"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMinLength 未配置 password.min_length 时的最小长度
+const DefaultMinLength = 8
+
+const defaultMaxLength = 128
+
+// 密码未通过的规则，作为字段错误的 Rule 返回给客户端
+const (
+	RuleMinLength = "password_min_length"
+	RuleMaxLength = "password_max_length"
+	RuleLower     = "password_lower"
+	RuleUpper     = "password_upper"
+	RuleDigit     = "password_digit"
+	RuleSymbol    = "password_symbol"
+	RuleBreached  = "password_breached"
+)
+
+//go:embed breached.txt
+var breachedList string
+
+// breached 常见弱密码集合，比较时忽略大小写
+var breached = func() map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, line := range strings.Split(breachedList, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}()
+
+// Violation 一条未通过的规则
+type Violation struct {
+	Rule  string
+	Param string // 规则参数，如最小长度
+}
+
+// Policy 密码策略
+type Policy struct {
+	cfg globals.PasswordConfig
+}
+
+// NewPolicy 根据配置创建密码策略，未配置的长度限制使用默认值
+func NewPolicy(cfg globals.PasswordConfig) *Policy {
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = DefaultMinLength
+	}
+	if cfg.MaxLength <= 0 {
+		cfg.MaxLength = defaultMaxLength
+	}
+	return &Policy{cfg: cfg}
+}
+
+// Check 返回密码未通过的全部规则，全部通过时返回 nil
+func (p *Policy) Check(password string) []Violation {
+	var violations []Violation
+	length := utf8.RuneCountInString(password)
+	if length < p.cfg.MinLength {
+		violations = append(violations, Violation{Rule: RuleMinLength, Param: strconv.Itoa(p.cfg.MinLength)})
+	}
+	if length > p.cfg.MaxLength {
+		violations = append(violations, Violation{Rule: RuleMaxLength, Param: strconv.Itoa(p.cfg.MaxLength)})
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if p.cfg.RequireLower && !lower {
+		violations = append(violations, Violation{Rule: RuleLower})
+	}
+	if p.cfg.RequireUpper && !upper {
+		violations = append(violations, Violation{Rule: RuleUpper})
+	}
+	if p.cfg.RequireDigit && !digit {
+		violations = append(violations, Violation{Rule: RuleDigit})
+	}
+	if p.cfg.RequireSymbol && !symbol {
+		violations = append(violations, Violation{Rule: RuleSymbol})
+	}
+
+	if !p.cfg.AllowBreached {
+		if _, ok := breached[strings.ToLower(password)]; ok {
+			violations = append(violations, Violation{Rule: RuleBreached})
+		}
+	}
+	return violations
+}
+
+// Validate 校验密码，不通过时返回带字段错误的 ErrWeakPassword，field 为请求中密码字段的名字
+func (p *Policy) Validate(field, password string) error {
+	violations := p.Check(password)
+	if len(violations) == 0 {
+		return nil
+	}
+	fields := make([]apperr.FieldError, 0, len(violations))
+	for _, v := range violations {
+		fields = append(fields, apperr.FieldError{Field: field, Rule: v.Rule, Param: v.Param})
+	}
+	return apperr.ErrWeakPassword.WithFields(fields...)
+}
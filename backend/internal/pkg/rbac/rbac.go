@@ -0,0 +1,29 @@
+// Package rbac 定义系统内置的角色与权限
+package rbac
+
+const (
+	// RoleAdmin 管理员角色
+	RoleAdmin = "admin"
+)
+
+// 权限标识
+const (
+	PermUserRead      = "user:read"      // 查看、搜索用户
+	PermUserWrite     = "user:write"     // 禁用、启用用户
+	PermSessionRevoke = "session:revoke" // 强制用户下线
+	PermDeviceRead    = "device:read"    // 查看用户设备
+	PermAuditRead     = "audit:read"     // 查询审计日志
+	PermLogLevel      = "log:level"      // 查看、修改日志级别
+)
+
+// BuiltinRoles 内置角色及其权限，启动时同步到数据库
+var BuiltinRoles = map[string][]string{
+	RoleAdmin: {
+		PermUserRead,
+		PermUserWrite,
+		PermSessionRevoke,
+		PermDeviceRead,
+		PermAuditRead,
+		PermLogLevel,
+	},
+}
@@ -0,0 +1,50 @@
+// Package requestid 请求 ID：优先沿用客户端或网关传入的 X-Request-ID，否则生成一个新的
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header 请求和响应中携带请求 ID 的头
+const Header = "X-Request-ID"
+
+// maxLen 与审计表 request_id 列的长度一致
+const maxLen = 64
+
+type ctxKey struct{}
+
+// New 生成一个 32 位十六进制的请求 ID
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Valid 判断传入的请求 ID 是否可以沿用：非空、不超过 64 个字符，且只包含字母、数字和 - _ . :
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext 返回携带请求 ID 的 context
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext 取出请求 ID，没有时返回空字符串
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
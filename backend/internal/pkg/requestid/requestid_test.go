@@ -0,0 +1,49 @@
+package requestid_test
+
+import (
+	"blueLock/backend/internal/pkg/requestid"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	cases := map[string]bool{
+		"":                          false,
+		"abc-123_DEF.4:5":           true,
+		"0f8fad5b-d9cb-469f-a165":   true,
+		strings.Repeat("a", 64):     true,
+		strings.Repeat("a", 65):     false,
+		"has space":                 false,
+		"line\nbreak":               false,
+		"quote\"":                   false,
+		"slash/":                    false,
+		"中文":                        false,
+		"<script>alert(1)</script>": false,
+	}
+	for id, want := range cases {
+		if got := requestid.Valid(id); got != want {
+			t.Errorf("Valid(%q) = %v，期望 %v", id, got, want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	a, b := requestid.New(), requestid.New()
+	if len(a) != 32 || !requestid.Valid(a) {
+		t.Fatalf("生成的请求 ID 不合法: %q", a)
+	}
+	if a == b {
+		t.Fatal("两次生成的请求 ID 相同")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if id := requestid.FromContext(context.Background()); id != "" {
+		t.Fatalf("空 context 中取到了请求 ID: %q", id)
+	}
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	if id := requestid.FromContext(ctx); id != "req-1" {
+		t.Fatalf("FromContext = %q", id)
+	}
+}
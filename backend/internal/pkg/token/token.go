@@ -1,6 +1,8 @@
 package token
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"time"
@@ -9,6 +11,9 @@ import (
 type TokenClaims struct {
 	UserID    uint64 `json:"user_id"`
 	TokenType string `json:"token_type"`
+	// 签发时间（unix 毫秒）。标准的 iat 只精确到秒，吊销时间精确到毫秒，
+	// 吊销后立即重新登录得到的令牌不会因为同一秒签发而被误判为已吊销
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -41,14 +46,21 @@ func (s *Service) GenerateRefreshToken(userID uint64) (string, error) {
 // generateToken 生成访问令牌
 func (s *Service) generateToken(userID uint64, tokenType string, expires time.Duration) (string, error) {
 	now := time.Now()
+	// 随机的 jti 保证同一秒内为同一用户签发的令牌也互不相同，刷新令牌轮换依赖这一点
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("生成令牌 ID 失败：%w", err)
+	}
 	claims := TokenClaims{
-		UserID:    userID,
-		TokenType: tokenType,
+		UserID:     userID,
+		TokenType:  tokenType,
+		IssuedAtMs: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(expires)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "bluetooth-safe-box",
+			ID:        hex.EncodeToString(jti),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -96,3 +108,19 @@ func IsAccessToken(claims *TokenClaims) bool {
 func IsRefreshToken(claims *TokenClaims) bool {
 	return claims.TokenType == "refresh"
 }
+
+// IsRevoked 检查令牌是否在吊销时间 revokedAt（unix 毫秒，0 表示未吊销）之前或同一毫秒内签发。
+// 没有 iat_ms 的旧令牌按 iat 截断到秒比较，同一秒内签发的视为已吊销
+func IsRevoked(claims *TokenClaims, revokedAt int64) bool {
+	if revokedAt <= 0 {
+		return false
+	}
+	issuedAt := claims.IssuedAtMs
+	if issuedAt == 0 {
+		if claims.IssuedAt == nil {
+			return true
+		}
+		issuedAt = claims.IssuedAt.Truncate(time.Second).UnixMilli()
+	}
+	return issuedAt <= revokedAt
+}
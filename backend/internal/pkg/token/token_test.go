@@ -0,0 +1,51 @@
+package token_test
+
+import (
+	"blueLock/backend/internal/pkg/token"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestIsRevoked(t *testing.T) {
+	service := token.NewService(token.Config{SecretKey: "test-secret-key-test-secret-key-0123", AccessTokenExpiry: time.Minute})
+	raw, err := service.GenerateAccessToken(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := service.ParseToken(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 标准的 iat 仍只精确到秒，毫秒精度只来自 iat_ms
+	if claims.IssuedAt.Time.Nanosecond() != 0 || claims.IssuedAtMs/1000 != claims.IssuedAt.Unix() {
+		t.Fatalf("iat = %v，iat_ms = %d", claims.IssuedAt.Time, claims.IssuedAtMs)
+	}
+
+	iat := claims.IssuedAtMs
+	cases := []struct {
+		name      string
+		revokedAt int64
+		want      bool
+	}{
+		{"未吊销", 0, false},
+		{"签发之前吊销", iat - 1, false},
+		{"同一毫秒内吊销", iat, true},
+		{"签发之后吊销", iat + 1, true},
+	}
+	for _, tc := range cases {
+		if got := token.IsRevoked(claims, tc.revokedAt); got != tc.want {
+			t.Errorf("%s: IsRevoked = %v，期望 %v", tc.name, got, tc.want)
+		}
+	}
+
+	// 没有 iat_ms 的旧令牌按秒比较，同一秒内吊销即失效
+	legacy := &token.TokenClaims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.UnixMilli(10_900))}}
+	if !token.IsRevoked(legacy, 10_000) || token.IsRevoked(legacy, 9_999) {
+		t.Fatal("旧令牌没有按秒比较吊销时间")
+	}
+	if !token.IsRevoked(&token.TokenClaims{}, 1) {
+		t.Fatal("没有签发时间的令牌在吊销后仍然有效")
+	}
+}
@@ -0,0 +1,80 @@
+// Package validate 请求参数校验：注册自定义校验规则，并把校验失败转换为字段级错误
+package validate
+
+import (
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/emailaddr"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Setup 向 gin 的参数绑定校验器注册自定义规则，需在处理请求前调用一次
+func Setup() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("不支持的校验器: %T", binding.Validator.Engine())
+	}
+	return Register(v)
+}
+
+// Register 注册自定义规则，字段名使用请求中的名字（json、form、uri 标签）而不是结构体字段名
+//
+//	email_addr  合法的邮箱地址，长度不超过 254，首尾空白会在规范化时去掉
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+	return v.RegisterValidation("email_addr", func(fl validator.FieldLevel) bool {
+		return emailaddr.Valid(fl.Field().String())
+	})
+}
+
+// fieldName 依次取 json、form、uri 标签中的名字
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Fields 将参数绑定返回的校验错误转换为字段错误，err 中不包含校验错误时返回 false（如 JSON 格式错误）
+func Fields(err error) ([]apperr.FieldError, bool) {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+	fields := make([]apperr.FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, apperr.FieldError{
+			Field: fe.Field(),
+			Rule:  rule(fe),
+			Param: fe.Param(),
+		})
+	}
+	return fields, true
+}
+
+// rule 字符串的 min、max、len 限制的是长度，换成单独的规则名，客户端不需要再根据字段类型区分
+func rule(fe validator.FieldError) string {
+	if fe.Kind() != reflect.String {
+		return fe.Tag()
+	}
+	switch fe.Tag() {
+	case "min":
+		return "min_length"
+	case "max":
+		return "max_length"
+	case "len":
+		return "length"
+	}
+	return fe.Tag()
+}
@@ -0,0 +1,38 @@
+// Package version 构建信息，发布时通过 -ldflags 注入：
+//
+//	go build -ldflags "-X blueLock/backend/internal/pkg/version.Version=v1.2.0 -X blueLock/backend/internal/pkg/version.Commit=$(git rev-parse --short HEAD) -X blueLock/backend/internal/pkg/version.BuildTime=$(date -u +%FT%TZ)" ./backend
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info 构建信息
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get 返回构建信息，未注入 Commit 时取 go 工具链记录的 vcs.revision
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if info.Commit == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range bi.Settings {
+				if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+					info.Commit = s.Value[:7]
+				}
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"blueLock/backend/internal/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditFilter 审计日志查询条件，零值表示不过滤
+type AuditFilter struct {
+	ActorID  uint
+	Action   string
+	TargetID string
+	Result   string
+	From     time.Time
+	To       time.Time
+	Offset   int
+	Limit    int
+}
+
+// AuditRepository 审计日志数据访问层，只提供追加和查询
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository 创建并返回一个新的 AuditRepository 实例
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// auditChainHeadID 链头表中唯一一行的ID
+const auditChainHeadID = 1
+
+// ErrAuditChainHeadMissing 链头不存在，通常是没有执行数据库迁移
+var ErrAuditChainHeadMissing = errors.New("审计日志链头不存在，请先执行数据库迁移")
+
+// Append 批量追加审计日志。在同一事务内锁住链头行再计算哈希，表为空时也能串行化，多实例同时写入时链不会分叉
+func (r *AuditRepository) Append(ctx context.Context, logs []models.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var head models.AuditChainHead
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", auditChainHeadID).
+			Limit(1).
+			Find(&head)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAuditChainHeadMissing
+		}
+		prev := head.Hash
+		for i := range logs {
+			logs[i].PrevHash = prev
+			logs[i].Hash = AuditHash(&logs[i])
+			prev = logs[i].Hash
+		}
+		if err := tx.Create(&logs).Error; err != nil {
+			return err
+		}
+		return tx.Model(&head).Updates(map[string]any{
+			"hash":    prev,
+			"last_id": logs[len(logs)-1].ID,
+			"count":   gorm.Expr("count + ?", len(logs)),
+		}).Error
+	})
+}
+
+// Query 按条件分页查询审计日志，按时间倒序
+func (r *AuditRepository) Query(ctx context.Context, f AuditFilter) ([]models.AuditLog, int64, error) {
+	var (
+		logs  []models.AuditLog
+		total int64
+	)
+	query := r.db.WithContext(ctx).Model(&models.AuditLog{})
+	if f.ActorID != 0 {
+		query = query.Where("actor_id = ?", f.ActorID)
+	}
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.TargetID != "" {
+		query = query.Where("target_id = ?", f.TargetID)
+	}
+	if f.Result != "" {
+		query = query.Where("result = ?", f.Result)
+	}
+	if !f.From.IsZero() {
+		query = query.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		query = query.Where("created_at < ?", f.To)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(f.Offset).Limit(f.Limit).Find(&logs).Error
+	return logs, total, err
+}
+
+// VerifyChain 从头校验哈希链，返回校验的条数；发现篡改时返回第一条异常记录的ID。
+// 链尾与链头记录的哈希、ID 或条数不一致时 truncated 为 true，说明末尾的日志被删除
+func (r *AuditRepository) VerifyChain(ctx context.Context) (checked int64, brokenID uint, truncated bool, err error) {
+	const batch = 500
+	db := r.db.WithContext(ctx)
+	// 先读取链头作为快照，之后追加的日志不在本次校验范围内
+	var head models.AuditChainHead
+	result := db.Where("id = ?", auditChainHeadID).Limit(1).Find(&head)
+	if result.Error != nil {
+		return 0, 0, false, result.Error
+	}
+	var (
+		lastID uint
+		prev   string
+	)
+	for {
+		var logs []models.AuditLog
+		err = db.Where("id > ? AND id <= ?", lastID, head.LastID).
+			Order("id").
+			Limit(batch).
+			Find(&logs).
+			Error
+		if err != nil {
+			return checked, 0, false, err
+		}
+		for i := range logs {
+			if logs[i].PrevHash != prev || AuditHash(&logs[i]) != logs[i].Hash {
+				return checked, logs[i].ID, false, nil
+			}
+			prev = logs[i].Hash
+			lastID = logs[i].ID
+			checked++
+		}
+		if len(logs) < batch {
+			break
+		}
+	}
+	if result.RowsAffected == 0 {
+		// 链头被删除：只要表中还有日志就视为被截断
+		var count int64
+		if err := db.Model(&models.AuditLog{}).Count(&count).Error; err != nil {
+			return checked, 0, false, err
+		}
+		return checked, 0, count > 0, nil
+	}
+	truncated = lastID != head.LastID || prev != head.Hash || checked != head.Count
+	return checked, 0, truncated, nil
+}
+
+// AuditHash 计算单条审计日志的哈希，覆盖除ID和Hash以外的全部字段
+func AuditHash(log *models.AuditLog) string {
+	h := sha256.New()
+	// 字段之间用不可见分隔符隔开，避免拼接产生歧义
+	fmt.Fprintf(h, "%s\x1f%d\x1f%d\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s\x1f%s",
+		log.PrevHash,
+		log.CreatedAt.UnixMilli(),
+		log.ActorID,
+		log.Action,
+		log.TargetType,
+		log.TargetID,
+		log.IP,
+		log.UserAgent,
+		log.RequestID,
+		log.Result,
+		log.Details,
+	)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,161 @@
+package repository_test
+
+import (
+	"blueLock/backend/internal/migrations"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/database"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/migrate"
+	"blueLock/backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// auditLogs 生成 n 条待追加的审计日志
+func auditLogs(n int, action string) []models.AuditLog {
+	logs := make([]models.AuditLog, n)
+	for i := range logs {
+		logs[i] = models.AuditLog{
+			CreatedAt: time.Now().Truncate(time.Millisecond),
+			Action:    action,
+			TargetID:  fmt.Sprint(i),
+			Result:    "success",
+			Details:   "{}",
+		}
+	}
+	return logs
+}
+
+// verifyChain 校验哈希链并检查结果
+func verifyChain(t *testing.T, repo *repository.AuditRepository, checked int64, brokenID uint, truncated bool) {
+	t.Helper()
+	gotChecked, gotBroken, gotTruncated, err := repo.VerifyChain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotChecked != checked || gotBroken != brokenID || gotTruncated != truncated {
+		t.Fatalf("VerifyChain = (%d, %d, %v)，期望 (%d, %d, %v)",
+			gotChecked, gotBroken, gotTruncated, checked, brokenID, truncated)
+	}
+}
+
+func TestAuditChain(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	repo := repository.NewAuditRepository(db)
+
+	verifyChain(t, repo, 0, 0, false)
+
+	// 多个写入者同时追加，链不分叉
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.Append(ctx, auditLogs(5, "test.append"))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	verifyChain(t, repo, 20, 0, false)
+
+	var head models.AuditChainHead
+	if err := db.First(&head).Error; err != nil {
+		t.Fatal(err)
+	}
+	if head.Count != 20 {
+		t.Fatalf("链头记录的条数为 %d，期望 20", head.Count)
+	}
+
+	// 修改中间的一条
+	if err := db.Model(&models.AuditLog{}).Where("id = ?", 7).Update("action", "test.tampered").Error; err != nil {
+		t.Fatal(err)
+	}
+	verifyChain(t, repo, 6, 7, false)
+	if err := db.Model(&models.AuditLog{}).Where("id = ?", 7).Update("action", "test.append").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 删除末尾的日志，剩余部分的哈希链仍然完整，只能靠链头发现
+	if err := db.Where("id > ?", 18).Delete(&models.AuditLog{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	verifyChain(t, repo, 18, 0, true)
+
+	// 链头和日志一起删除
+	if err := db.Where("1 = 1").Delete(&models.AuditChainHead{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	verifyChain(t, repo, 0, 0, true)
+	if err := repo.Append(ctx, auditLogs(1, "test.append")); !errors.Is(err, repository.ErrAuditChainHeadMissing) {
+		t.Fatalf("链头不存在时追加: %v", err)
+	}
+}
+
+// TestAuditChainHeadMigration 新增链头的迁移按已有日志初始化链头
+func TestAuditChainHeadMigration(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.Open(globals.DatabaseConfig{Driver: database.DriverSQLite, Path: filepath.Join(t.TempDir(), "a.db")},
+		logger.Default.LogMode(logger.Silent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	all := migrations.All()
+	var before []migrate.Migration
+	for _, m := range all {
+		if m.Version < 2026101906 {
+			before = append(before, m)
+		}
+	}
+	m, err := migrate.New(db, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 升级前按旧方式写入的日志
+	prev := ""
+	logs := auditLogs(3, "test.legacy")
+	for i := range logs {
+		logs[i].PrevHash = prev
+		logs[i].Hash = repository.AuditHash(&logs[i])
+		prev = logs[i].Hash
+	}
+	if err := db.Create(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if m, err = migrate.New(db, all); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewAuditRepository(db)
+	verifyChain(t, repo, 3, 0, false)
+	if err := repo.Append(ctx, auditLogs(2, "test.append")); err != nil {
+		t.Fatal(err)
+	}
+	verifyChain(t, repo, 5, 0, false)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"blueLock/backend/internal/pkg/emailaddr"
+	"blueLock/backend/internal/pkg/kv"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CodeRepository 验证码存储
+type CodeRepository struct {
+	store kv.Store
+}
+
+// NewCodeRepository 创建验证码数据访问实现
+func NewCodeRepository(store kv.Store) *CodeRepository {
+	return &CodeRepository{store: store}
+}
+
+// SaveCode 保存验证码
+func (r *CodeRepository) SaveCode(ctx context.Context, email string, code string, expiry time.Duration) error {
+	return r.store.Set(ctx, codeKey(email), code, expiry)
+}
+
+// GetCode 获取验证码，不存在时返回空字符串
+func (r *CodeRepository) GetCode(ctx context.Context, email string) (string, error) {
+	code, err := r.store.Get(ctx, codeKey(email))
+	if errors.Is(err, kv.ErrNotFound) {
+		return "", nil
+	}
+	return code, err
+}
+
+// ConsumeCode 验证码匹配时原子地删除并返回 true，并发的两次校验只有一次能成功
+func (r *CodeRepository) ConsumeCode(ctx context.Context, email string, code string) (bool, error) {
+	return r.store.CompareAndDelete(ctx, codeKey(email), code)
+}
+
+// DeleteCode 删除验证码
+func (r *CodeRepository) DeleteCode(ctx context.Context, email string) error {
+	return r.store.Del(ctx, codeKey(email))
+}
+
+func codeKey(email string) string {
+	return fmt.Sprintf("verify_code:%s", emailaddr.Normalize(email))
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeviceRepository 设备数据访问层
+type DeviceRepository struct {
+	db *gorm.DB
+}
+
+// NewDeviceRepository 创建并返回一个新的 DeviceRepository 实例
+func NewDeviceRepository(db *gorm.DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+// ListByUserID 查询用户绑定的设备
+func (r *DeviceRepository) ListByUserID(ctx context.Context, userID uint) ([]models.Device, error) {
+	var devices []models.Device
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&devices).
+		Error
+	return devices, err
+}
+
+// GetBySerial 根据序列号查询设备，不存在时返回 nil
+func (r *DeviceRepository) GetBySerial(ctx context.Context, serial string) (*models.Device, error) {
+	var device models.Device
+	err := r.db.WithContext(ctx).Where("serial = ?", serial).First(&device).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// TouchLastSeen 更新设备最近一次连接的时间
+func (r *DeviceRepository) TouchLastSeen(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Device{}).
+		Where("id = ?", id).
+		Update("last_seen_at", at).
+		Error
+}
+
+// Create 新增设备
+func (r *DeviceRepository) Create(ctx context.Context, device *models.Device) error {
+	err := r.db.WithContext(ctx).Create(device).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperr.ErrDeviceTaken.Wrap(err)
+	}
+	return err
+}
+
+// Delete 解绑用户的设备，返回删除的行数
+func (r *DeviceRepository) Delete(ctx context.Context, userID, id uint) (int64, error) {
+	// 物理删除，解绑后序列号可以重新绑定（唯一索引）
+	res := r.db.WithContext(ctx).
+		Unscoped().
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&models.Device{})
+	return res.RowsAffected, res.Error
+}
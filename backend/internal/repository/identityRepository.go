@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// IdentityRepository 第三方身份绑定数据访问层
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository 创建并返回一个新的 IdentityRepository 实例
+func NewIdentityRepository(db *gorm.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// GetBySubject 根据提供方和外部用户标识查询绑定关系，不存在时返回 nil
+func (r *IdentityRepository) GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListByUserID 查询用户绑定的全部第三方身份
+func (r *IdentityRepository) ListByUserID(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&identities).
+		Error
+	return identities, err
+}
+
+// Create 新增绑定关系
+func (r *IdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	err := r.db.WithContext(ctx).Create(identity).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperr.ErrIdentityTaken.Wrap(err)
+	}
+	return err
+}
+
+// DeleteByProvider 解除用户在某个提供方上的绑定，返回删除的行数
+func (r *IdentityRepository) DeleteByProvider(ctx context.Context, userID uint, provider string) (int64, error) {
+	// 使用 Unscoped 物理删除，保证解绑后同一外部账号可以重新绑定（唯一索引）
+	res := r.db.WithContext(ctx).
+		Unscoped().
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&models.UserIdentity{})
+	return res.RowsAffected, res.Error
+}
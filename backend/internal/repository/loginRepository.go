@@ -2,13 +2,15 @@ package repository
 
 import (
 	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/emailaddr"
 	"context"
 	"errors"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"strings"
 )
 
-// LoginRepository 封装了对学校（school）数据的数据库操作
+// LoginRepository 封装了对用户（user）数据的数据库操作
 type LoginRepository struct {
 	db *gorm.DB
 }
@@ -20,7 +22,12 @@ func NewLoginRepository(db *gorm.DB) *LoginRepository {
 
 // CreateUser 注册时将邮箱密码存入数据库
 func (r *LoginRepository) CreateUser(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	user.Email = emailaddr.Normalize(user.Email)
+	err := r.db.WithContext(ctx).Create(user).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperr.ErrEmailTaken.Wrap(err)
+	}
+	return err
 }
 
 // ExistsByEmail 判断用户是否存在
@@ -28,8 +35,7 @@ func (r *LoginRepository) ExistsByEmail(c context.Context, email string) (bool,
 	var count int64
 	err := r.db.WithContext(c).
 		Model(&models.User{}).
-		Where("email = ?", email).
-		Where("deleted_at IS NULL").
+		Where("email = ?", emailaddr.Normalize(email)).
 		Count(&count).
 		Error
 	return count > 0, err
@@ -38,42 +44,107 @@ func (r *LoginRepository) ExistsByEmail(c context.Context, email string) (bool,
 // GetUserByID 根据id查询用户
 func (r *LoginRepository) GetUserByID(c context.Context, id uint) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(c).First(&user, id).Error
-	return &user, err
-}
-
-// GetPasswordByEmail 邮箱是否存在数据库并且其对应的密码是否正确
-func (r *LoginRepository) GetPasswordByEmail(c context.Context, email string, password string) error {
-	var user models.User
-	// 1. 验证邮箱是否存在
-	res := r.db.WithContext(c).
-		Model(&models.User{}).
-		Where("email = ?", email).
-		Where("deleted_at IS NULL").
-		First(&user)
-	if res.Error != nil {
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return errors.New("邮箱或密码错误")
-		}
-		return res.Error
+	err := r.db.WithContext(c).Preload("Roles").First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperr.ErrUserNotFound.Wrap(err)
 	}
-	// 2. 邮箱对应密码是否正确
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PassWord), []byte(password)); err != nil {
-		return errors.New("邮箱或密码错误")
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &user, nil
 }
 
 // GetUserByEmail 根据邮箱获取用户信息
 func (r *LoginRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	err := r.db.WithContext(ctx).
-		Where("email = ?", email).
-		Where("deleted_at IS NULL").
+		Where("email = ?", emailaddr.Normalize(email)).
 		First(&user).
 		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperr.ErrUserNotFound.Wrap(err)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// ListUsers 分页查询用户，keyword 非空时按邮箱模糊搜索
+func (r *LoginRepository) ListUsers(ctx context.Context, keyword string, offset, limit int) ([]models.User, int64, error) {
+	var (
+		users []models.User
+		total int64
+	)
+	query := r.db.WithContext(ctx).Model(&models.User{})
+	if keyword != "" {
+		// LOWER 保证 MySQL、PostgreSQL、SQLite 下都是大小写不敏感的匹配
+		query = query.Where("LOWER(email) LIKE ?", "%"+strings.ToLower(keyword)+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.
+		Preload("Roles").
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Find(&users).
+		Error
+	return users, total, err
+}
+
+// SetDisabled 禁用或启用用户
+func (r *LoginRepository) SetDisabled(ctx context.Context, id uint, disabled bool) error {
+	res := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("disabled", disabled)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apperr.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePassword 更新用户密码哈希
+func (r *LoginRepository) UpdatePassword(ctx context.Context, id uint, hashed string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("pass_word", hashed).
+		Error
+}
+
+// UpdateLocale 更新用户语言偏好
+func (r *LoginRepository) UpdateLocale(ctx context.Context, id uint, locale string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("locale", locale).
+		Error
+}
+
+// UpdateEmail 修改用户邮箱，新邮箱已被使用时返回 apperr.ErrEmailTaken
+func (r *LoginRepository) UpdateEmail(ctx context.Context, id uint, email string) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("email", emailaddr.Normalize(email)).
+		Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperr.ErrEmailTaken.Wrap(err)
+	}
+	return err
+}
+
+// UpdateNotifyOptOut 更新用户退订的通知类型
+func (r *LoginRepository) UpdateNotifyOptOut(ctx context.Context, id uint, optOut string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("notify_opt_out", optOut).
+		Error
+}
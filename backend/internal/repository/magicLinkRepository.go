@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"blueLock/backend/internal/pkg/kv"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MagicLinkRepository 邮件链接登录的待完成请求。每个请求有三个键：
+// 请求记录、链接未使用标记（打开或批准时原子删除，链接只能用一次）、批准标记（发起方轮询时原子取出，令牌只签发一次）
+type MagicLinkRepository struct {
+	store kv.Store
+}
+
+// NewMagicLinkRepository 创建邮件链接登录数据访问实现
+func NewMagicLinkRepository(store kv.Store) *MagicLinkRepository {
+	return &MagicLinkRepository{store: store}
+}
+
+// MagicLink 一次邮件链接登录请求
+type MagicLink struct {
+	UserID    uint   `json:"user_id"`
+	NonceHash string `json:"nonce_hash"` // 发起方 nonce 的 sha256，nonce 本身只在发起方客户端
+	IP        string `json:"ip"`
+	Device    string `json:"device"`
+	CreatedAt int64  `json:"created_at"` // unix 秒
+}
+
+// Create 保存请求并标记链接未使用
+func (r *MagicLinkRepository) Create(ctx context.Context, id string, link MagicLink, ttl time.Duration) error {
+	value, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+	if err := r.store.Set(ctx, magicLinkKey(id), string(value), ttl); err != nil {
+		return err
+	}
+	return r.store.Set(ctx, magicLinkUnusedKey(id), "1", ttl)
+}
+
+// Get 查询请求，不存在或已过期时返回 nil
+func (r *MagicLinkRepository) Get(ctx context.Context, id string) (*MagicLink, error) {
+	value, err := r.store.Get(ctx, magicLinkKey(id))
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var link MagicLink
+	if err := json.Unmarshal([]byte(value), &link); err != nil {
+		return nil, fmt.Errorf("解析邮件链接登录请求失败: %w", err)
+	}
+	return &link, nil
+}
+
+// ConsumeLink 使用链接，并发的多次使用只有一次返回 true
+func (r *MagicLinkRepository) ConsumeLink(ctx context.Context, id string) (bool, error) {
+	return r.store.CompareAndDelete(ctx, magicLinkUnusedKey(id), "1")
+}
+
+// Approve 在其他设备上批准请求，发起方下次轮询时完成登录
+func (r *MagicLinkRepository) Approve(ctx context.Context, id string, ttl time.Duration) error {
+	return r.store.Set(ctx, magicLinkApprovedKey(id), "1", ttl)
+}
+
+// TakeApproval 取出批准标记，已批准时只有第一次调用返回 true
+func (r *MagicLinkRepository) TakeApproval(ctx context.Context, id string) (bool, error) {
+	_, err := r.store.GetDel(ctx, magicLinkApprovedKey(id))
+	if errors.Is(err, kv.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete 删除请求的全部键
+func (r *MagicLinkRepository) Delete(ctx context.Context, id string) error {
+	return r.store.Del(ctx, magicLinkKey(id), magicLinkUnusedKey(id), magicLinkApprovedKey(id))
+}
+
+// 同一请求的键使用相同的哈希标签，集群模式下落在同一个槽
+func magicLinkKey(id string) string {
+	return fmt.Sprintf("%s:request", kv.HashTag("magic_link:"+id))
+}
+
+func magicLinkUnusedKey(id string) string {
+	return fmt.Sprintf("%s:unused", kv.HashTag("magic_link:"+id))
+}
+
+func magicLinkApprovedKey(id string) string {
+	return fmt.Sprintf("%s:approved", kv.HashTag("magic_link:"+id))
+}
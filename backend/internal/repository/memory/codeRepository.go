@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"blueLock/backend/internal/pkg/emailaddr"
+	"blueLock/backend/internal/repository"
+	"context"
+	"time"
+)
+
+var _ repository.CodeStore = (*CodeRepository)(nil)
+
+// CodeRepository 验证码的内存实现
+type CodeRepository struct {
+	codes *expiringMap
+}
+
+// NewCodeRepository 创建内存验证码存储，now 为空时使用 time.Now
+func NewCodeRepository(now func() time.Time) *CodeRepository {
+	if now == nil {
+		now = time.Now
+	}
+	return &CodeRepository{codes: newExpiringMap(now)}
+}
+
+// SaveCode 保存验证码
+func (r *CodeRepository) SaveCode(_ context.Context, email string, code string, expiry time.Duration) error {
+	r.codes.set(emailaddr.Normalize(email), code, expiry)
+	return nil
+}
+
+// GetCode 获取验证码，不存在或已过期时返回空字符串
+func (r *CodeRepository) GetCode(_ context.Context, email string) (string, error) {
+	code, _ := r.codes.get(emailaddr.Normalize(email))
+	return code, nil
+}
+
+// ConsumeCode 验证码匹配时删除并返回 true
+func (r *CodeRepository) ConsumeCode(_ context.Context, email string, code string) (bool, error) {
+	return r.codes.compareAndDelete(emailaddr.Normalize(email), code), nil
+}
+
+// DeleteCode 删除验证码
+func (r *CodeRepository) DeleteCode(_ context.Context, email string) error {
+	r.codes.delete(emailaddr.Normalize(email))
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package memory 提供 repository 存储接口的内存实现，数据只保存在进程内，用于离线测试和本地调试
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+// entry 带过期时间的值，expiresAt 为零值表示永不过期
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// expiringMap 并发安全、带过期时间的字符串表，过期的键在读取时清理
+type expiringMap struct {
+	mu    sync.Mutex
+	items map[string]entry
+	now   func() time.Time
+}
+
+func newExpiringMap(now func() time.Time) *expiringMap {
+	return &expiringMap{items: make(map[string]entry), now: now}
+}
+
+func (m *expiringMap) set(key, value string, expiry time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := entry{value: value}
+	if expiry > 0 {
+		e.expiresAt = m.now().Add(expiry)
+	}
+	m.items[key] = e
+}
+
+func (m *expiringMap) get(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.items[key]
+	if !ok {
+		return "", false
+	}
+	if !e.expiresAt.IsZero() && !m.now().Before(e.expiresAt) {
+		delete(m.items, key)
+		return "", false
+	}
+	return e.value, true
+}
+
+func (m *expiringMap) delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+}
+
+// compareAndDelete 值相等且未过期时删除并返回 true
+func (m *expiringMap) compareAndDelete(key, expected string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.items[key]
+	if !ok || e.value != expected {
+		return false
+	}
+	delete(m.items, key)
+	return e.expiresAt.IsZero() || m.now().Before(e.expiresAt)
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"blueLock/backend/internal/repository"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var _ repository.PermissionStore = (*RoleRepository)(nil)
+
+// RoleRepository 角色与权限的内存实现
+type RoleRepository struct {
+	mu        sync.RWMutex
+	roles     map[string][]string // 角色名 -> 权限
+	userRoles map[uint]map[string]bool
+}
+
+// NewRoleRepository 创建空的内存角色表
+func NewRoleRepository() *RoleRepository {
+	return &RoleRepository{roles: make(map[string][]string), userRoles: make(map[uint]map[string]bool)}
+}
+
+// EnsureRole 确保角色存在，并把它的权限同步为 permissions
+func (r *RoleRepository) EnsureRole(_ context.Context, name string, permissions []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles[name] = append([]string(nil), permissions...)
+	return nil
+}
+
+// AssignRole 为用户分配角色，角色不存在时返回错误
+func (r *RoleRepository) AssignRole(_ context.Context, userID uint, roleName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.roles[roleName]; !ok {
+		return fmt.Errorf("角色 %s 不存在", roleName)
+	}
+	if r.userRoles[userID] == nil {
+		r.userRoles[userID] = make(map[string]bool)
+	}
+	r.userRoles[userID][roleName] = true
+	return nil
+}
+
+// GetPermissionsByUserID 查询用户通过角色获得的全部权限，按名称排序并去重
+func (r *RoleRepository) GetPermissionsByUserID(_ context.Context, userID uint) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seen := make(map[string]bool)
+	var perms []string
+	for role := range r.userRoles[userID] {
+		for _, p := range r.roles[role] {
+			if !seen[p] {
+				seen[p] = true
+				perms = append(perms, p)
+			}
+		}
+	}
+	sort.Strings(perms)
+	return perms, nil
+}
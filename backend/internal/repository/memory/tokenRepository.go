@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"blueLock/backend/internal/repository"
+	"context"
+	"strconv"
+	"time"
+)
+
+var _ repository.TokenStore = (*TokenRepository)(nil)
+
+// TokenRepository 刷新令牌和吊销记录的内存实现
+type TokenRepository struct {
+	refresh *expiringMap
+	revoked *expiringMap
+	used    *expiringMap
+	now     func() time.Time
+}
+
+// NewTokenRepository 创建内存令牌存储，now 为空时使用 time.Now，测试中可传入可控时钟
+func NewTokenRepository(now func() time.Time) *TokenRepository {
+	if now == nil {
+		now = time.Now
+	}
+	return &TokenRepository{
+		refresh: newExpiringMap(now),
+		revoked: newExpiringMap(now),
+		used:    newExpiringMap(now),
+		now:     now,
+	}
+}
+
+// SaveRefreshToken 保存刷新令牌
+func (r *TokenRepository) SaveRefreshToken(_ context.Context, userID uint, token string, expiry time.Duration) error {
+	r.refresh.set(userKey(userID), token, expiry)
+	return nil
+}
+
+// GetRefreshToken 获取刷新令牌，不存在时返回空字符串
+func (r *TokenRepository) GetRefreshToken(_ context.Context, userID uint) (string, error) {
+	token, _ := r.refresh.get(userKey(userID))
+	return token, nil
+}
+
+// DeleteRefreshToken 删除刷新令牌
+func (r *TokenRepository) DeleteRefreshToken(_ context.Context, userID uint) error {
+	r.refresh.delete(userKey(userID))
+	return nil
+}
+
+// ConsumeRefreshToken 刷新令牌与 token 相同时删除
+func (r *TokenRepository) ConsumeRefreshToken(_ context.Context, userID uint, token string) (bool, error) {
+	return r.refresh.compareAndDelete(userKey(userID), token), nil
+}
+
+// RevokeUserTokens 删除刷新令牌并记录吊销时间
+func (r *TokenRepository) RevokeUserTokens(ctx context.Context, userID uint, expiry time.Duration) error {
+	if err := r.DeleteRefreshToken(ctx, userID); err != nil {
+		return err
+	}
+	r.revoked.set(userKey(userID), strconv.FormatInt(r.now().UnixMilli(), 10), expiry)
+	return nil
+}
+
+// GetRevokedAt 获取吊销时间（unix 毫秒），未吊销时返回 0
+func (r *TokenRepository) GetRevokedAt(_ context.Context, userID uint) (int64, error) {
+	value, ok := r.revoked.get(userKey(userID))
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// MarkRefreshTokenUsed 记录已被轮换掉的刷新令牌
+func (r *TokenRepository) MarkRefreshTokenUsed(_ context.Context, userID uint, token string, expiry time.Duration) error {
+	r.used.set(userKey(userID)+":"+token, "1", expiry)
+	return nil
+}
+
+// IsRefreshTokenUsed 判断刷新令牌是否已被轮换掉
+func (r *TokenRepository) IsRefreshTokenUsed(_ context.Context, userID uint, token string) (bool, error) {
+	_, ok := r.used.get(userKey(userID) + ":" + token)
+	return ok, nil
+}
+
+func userKey(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/pkg/emailaddr"
+	"blueLock/backend/internal/repository"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ repository.UserStore = (*UserRepository)(nil)
+
+// UserRepository 用户数据的内存实现
+type UserRepository struct {
+	mu     sync.RWMutex
+	users  map[uint]models.User
+	nextID uint
+}
+
+// NewUserRepository 创建空的内存用户表
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: make(map[uint]models.User)}
+}
+
+// CreateUser 创建用户并回填 id 和时间戳
+func (r *UserRepository) CreateUser(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user.Email = emailaddr.Normalize(user.Email)
+	for _, u := range r.users {
+		if u.Email == user.Email {
+			return apperr.ErrEmailTaken
+		}
+	}
+	r.nextID++
+	now := time.Now()
+	user.ID = r.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.users[user.ID] = *user
+	return nil
+}
+
+// ExistsByEmail 判断用户是否存在
+func (r *UserRepository) ExistsByEmail(_ context.Context, email string) (bool, error) {
+	_, ok := r.findByEmail(email)
+	return ok, nil
+}
+
+// GetUserByID 根据id查询用户
+func (r *UserRepository) GetUserByID(_ context.Context, id uint) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, apperr.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// GetUserByEmail 根据邮箱获取用户信息
+func (r *UserRepository) GetUserByEmail(_ context.Context, email string) (*models.User, error) {
+	user, ok := r.findByEmail(email)
+	if !ok {
+		return nil, apperr.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// ListUsers 分页查询用户，keyword 非空时按邮箱模糊搜索
+func (r *UserRepository) ListUsers(_ context.Context, keyword string, offset, limit int) ([]models.User, int64, error) {
+	r.mu.RLock()
+	matched := make([]models.User, 0, len(r.users))
+	for _, u := range r.users {
+		if keyword == "" || strings.Contains(u.Email, keyword) {
+			matched = append(matched, u)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return []models.User{}, total, nil
+	}
+	matched = matched[offset:]
+	if limit >= 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+// SetDisabled 禁用或启用用户
+func (r *UserRepository) SetDisabled(_ context.Context, id uint, disabled bool) error {
+	return r.update(id, func(u *models.User) { u.Disabled = disabled })
+}
+
+// UpdatePassword 更新用户密码哈希
+func (r *UserRepository) UpdatePassword(_ context.Context, id uint, hashed string) error {
+	return r.update(id, func(u *models.User) { u.PassWord = hashed })
+}
+
+// UpdateLocale 更新用户语言偏好
+func (r *UserRepository) UpdateLocale(_ context.Context, id uint, locale string) error {
+	return r.update(id, func(u *models.User) { u.Locale = locale })
+}
+
+// UpdateEmail 修改用户邮箱，新邮箱已被其他用户使用时返回 apperr.ErrEmailTaken
+func (r *UserRepository) UpdateEmail(_ context.Context, id uint, email string) error {
+	email = emailaddr.Normalize(email)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return apperr.ErrUserNotFound
+	}
+	for _, u := range r.users {
+		if u.ID != id && u.Email == email {
+			return apperr.ErrEmailTaken
+		}
+	}
+	user.Email = email
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+	return nil
+}
+
+// UpdateNotifyOptOut 更新用户退订的通知类型
+func (r *UserRepository) UpdateNotifyOptOut(_ context.Context, id uint, optOut string) error {
+	return r.update(id, func(u *models.User) { u.NotifyOptOut = optOut })
+}
+
+func (r *UserRepository) findByEmail(email string) (models.User, bool) {
+	email = emailaddr.Normalize(email)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, true
+		}
+	}
+	return models.User{}, false
+}
+
+func (r *UserRepository) update(id uint, fn func(u *models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return apperr.ErrUserNotFound
+	}
+	fn(&user)
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"blueLock/backend/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepository 角色与权限数据访问层
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository 创建并返回一个新的 RoleRepository 实例
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// EnsureRole 确保角色存在，并把它的权限同步为 permissions
+func (r *RoleRepository) EnsureRole(ctx context.Context, name string, permissions []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		if err := tx.Where(models.Role{Name: name}).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+		perms := make([]models.Permission, 0, len(permissions))
+		for _, p := range permissions {
+			var perm models.Permission
+			if err := tx.Where(models.Permission{Name: p}).FirstOrCreate(&perm).Error; err != nil {
+				return err
+			}
+			perms = append(perms, perm)
+		}
+		return tx.Model(&role).Association("Permissions").Replace(perms)
+	})
+}
+
+// AssignRole 为用户分配角色
+func (r *RoleRepository) AssignRole(ctx context.Context, userID uint, roleName string) error {
+	var role models.Role
+	if err := r.db.WithContext(ctx).Where("name = ?", roleName).First(&role).Error; err != nil {
+		return err
+	}
+	user := models.User{Model: gorm.Model{ID: userID}}
+	return r.db.WithContext(ctx).Model(&user).Association("Roles").Append(&role)
+}
+
+// CountUsersWithRole 统计拥有某角色的用户数量
+func (r *RoleRepository) CountUsersWithRole(ctx context.Context, roleName string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("b_user_roles").
+		Joins("JOIN b_roles ON b_roles.id = b_user_roles.role_id").
+		Where("b_roles.name = ?", roleName).
+		Count(&count).
+		Error
+	return count, err
+}
+
+// GetPermissionsByUserID 查询用户通过角色获得的全部权限
+func (r *RoleRepository) GetPermissionsByUserID(ctx context.Context, userID uint) ([]string, error) {
+	var perms []string
+	err := r.db.WithContext(ctx).
+		Table("b_permissions").
+		Distinct("b_permissions.name").
+		Joins("JOIN b_role_permissions ON b_role_permissions.permission_id = b_permissions.id").
+		Joins("JOIN b_user_roles ON b_user_roles.role_id = b_role_permissions.role_id").
+		Where("b_user_roles.user_id = ?", userID).
+		Pluck("b_permissions.name", &perms).
+		Error
+	return perms, err
+}
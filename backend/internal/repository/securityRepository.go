@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"blueLock/backend/internal/pkg/kv"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SecurityRepository 账号安全相关的临时数据：用户登录过的设备、修改邮箱后的撤销令牌
+type SecurityRepository struct {
+	store kv.Store
+}
+
+// NewSecurityRepository 创建账号安全数据访问实现
+func NewSecurityRepository(store kv.Store) *SecurityRepository {
+	return &SecurityRepository{store: store}
+}
+
+// EmailRevert 修改邮箱的撤销记录，通过发往旧邮箱的链接可以改回旧邮箱
+type EmailRevert struct {
+	UserID   uint   `json:"user_id"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+}
+
+// TouchLoginDevice 记录用户在 device 上登录，并刷新有效期 ttl。
+// known 表示该设备此前登录过，firstLogin 表示用户此前没有任何登录记录（如刚注册或记录已过期）
+func (r *SecurityRepository) TouchLoginDevice(ctx context.Context, userID uint, device string, ttl time.Duration) (known, firstLogin bool, err error) {
+	known, err = r.exists(ctx, loginDeviceKey(userID, device))
+	if err != nil {
+		return false, false, err
+	}
+	seen, err := r.exists(ctx, loginSeenKey(userID))
+	if err != nil {
+		return false, false, err
+	}
+	if err := r.store.Set(ctx, loginDeviceKey(userID, device), "1", ttl); err != nil {
+		return false, false, err
+	}
+	if err := r.store.Set(ctx, loginSeenKey(userID), "1", ttl); err != nil {
+		return false, false, err
+	}
+	return known, !seen, nil
+}
+
+// SaveEmailRevert 保存修改邮箱的撤销令牌，只保存令牌摘要
+func (r *SecurityRepository) SaveEmailRevert(ctx context.Context, token string, rec EmailRevert, ttl time.Duration) error {
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return r.store.Set(ctx, emailRevertKey(token), string(value), ttl)
+}
+
+// TakeEmailRevert 取出并删除撤销记录，令牌只能使用一次，不存在或已过期时返回 nil
+func (r *SecurityRepository) TakeEmailRevert(ctx context.Context, token string) (*EmailRevert, error) {
+	value, err := r.store.GetDel(ctx, emailRevertKey(token))
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec EmailRevert
+	if err := json.Unmarshal([]byte(value), &rec); err != nil {
+		return nil, fmt.Errorf("解析邮箱撤销记录失败: %w", err)
+	}
+	return &rec, nil
+}
+
+func (r *SecurityRepository) exists(ctx context.Context, key string) (bool, error) {
+	_, err := r.store.Get(ctx, key)
+	if errors.Is(err, kv.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func loginDeviceKey(userID uint, device string) string {
+	return fmt.Sprintf("%s:login_device:%s", kv.HashTag(fmt.Sprintf("user:%d", userID)), digest(device))
+}
+
+func loginSeenKey(userID uint) string {
+	return fmt.Sprintf("%s:login_seen", kv.HashTag(fmt.Sprintf("user:%d", userID)))
+}
+
+func emailRevertKey(token string) string {
+	return fmt.Sprintf("email_revert:%s", digest(token))
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"blueLock/backend/internal/models"
+	"context"
+	"time"
+)
+
+// UserStore 用户数据的存储接口，LoginRepository（gorm）和 memory.UserRepository（内存）都实现了它。
+// 邮箱在存储和查询前由实现按 emailaddr.Normalize 规范化，调用方传入大小写不同的邮箱视为同一个
+type UserStore interface {
+	// CreateUser 创建用户，邮箱重复时返回 apperr.ErrEmailTaken
+	CreateUser(ctx context.Context, user *models.User) error
+	// ExistsByEmail 判断邮箱是否已注册
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	// GetUserByID 按 id 查询用户（含角色），不存在时返回 apperr.ErrUserNotFound
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	// GetUserByEmail 按邮箱查询用户，不存在时返回 apperr.ErrUserNotFound
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	// ListUsers 按 id 升序分页查询用户，keyword 非空时按邮箱模糊搜索
+	ListUsers(ctx context.Context, keyword string, offset, limit int) ([]models.User, int64, error)
+	// SetDisabled 禁用或启用用户，不存在时返回 apperr.ErrUserNotFound
+	SetDisabled(ctx context.Context, id uint, disabled bool) error
+	// UpdatePassword 更新密码哈希
+	UpdatePassword(ctx context.Context, id uint, hashed string) error
+	// UpdateLocale 更新语言偏好
+	UpdateLocale(ctx context.Context, id uint, locale string) error
+	// UpdateEmail 更新邮箱，邮箱已被其他用户使用时返回 apperr.ErrEmailTaken
+	UpdateEmail(ctx context.Context, id uint, email string) error
+	// UpdateNotifyOptOut 更新用户退订的通知类型（逗号分隔）
+	UpdateNotifyOptOut(ctx context.Context, id uint, optOut string) error
+}
+
+// TokenStore 刷新令牌和令牌吊销记录的存储接口
+type TokenStore interface {
+	// SaveRefreshToken 保存刷新令牌，同一用户只保留最新一个
+	SaveRefreshToken(ctx context.Context, userID uint, token string, expiry time.Duration) error
+	// GetRefreshToken 获取刷新令牌，不存在或已过期时返回空字符串
+	GetRefreshToken(ctx context.Context, userID uint) (string, error)
+	// DeleteRefreshToken 删除刷新令牌
+	DeleteRefreshToken(ctx context.Context, userID uint) error
+	// ConsumeRefreshToken 保存的刷新令牌与 token 相同时原子地删除并返回 true，否则不做修改并返回 false。
+	// 同一个令牌并发刷新时只有一个请求能成功
+	ConsumeRefreshToken(ctx context.Context, userID uint, token string) (bool, error)
+	// RevokeUserTokens 删除刷新令牌并记录吊销时间
+	RevokeUserTokens(ctx context.Context, userID uint, expiry time.Duration) error
+	// GetRevokedAt 获取吊销时间（unix 毫秒），未吊销时返回 0
+	GetRevokedAt(ctx context.Context, userID uint) (int64, error)
+	// MarkRefreshTokenUsed 记录已被轮换掉的刷新令牌，expiry 为该令牌原本剩余的有效期
+	MarkRefreshTokenUsed(ctx context.Context, userID uint, token string, expiry time.Duration) error
+	// IsRefreshTokenUsed 判断刷新令牌是否已被轮换掉
+	IsRefreshTokenUsed(ctx context.Context, userID uint, token string) (bool, error)
+}
+
+// CodeStore 邮箱验证码的存储接口，邮箱同样由实现规范化
+type CodeStore interface {
+	// SaveCode 保存验证码，覆盖该邮箱之前的验证码
+	SaveCode(ctx context.Context, email string, code string, expiry time.Duration) error
+	// GetCode 获取验证码，不存在或已过期时返回空字符串
+	GetCode(ctx context.Context, email string) (string, error)
+	// ConsumeCode 验证码与 code 相同时删除并返回 true，否则不做修改并返回 false
+	ConsumeCode(ctx context.Context, email string, code string) (bool, error)
+	// DeleteCode 删除验证码
+	DeleteCode(ctx context.Context, email string) error
+}
+
+// SecurityStore 登录设备记录和邮箱撤销令牌的存储接口
+type SecurityStore interface {
+	// TouchLoginDevice 记录用户在 device 上登录，返回该设备此前是否登录过（ttl 内）以及这是否是该用户的首次登录
+	TouchLoginDevice(ctx context.Context, userID uint, device string, ttl time.Duration) (known, firstLogin bool, err error)
+	// SaveEmailRevert 保存撤销邮箱修改的令牌
+	SaveEmailRevert(ctx context.Context, token string, rec EmailRevert, ttl time.Duration) error
+	// TakeEmailRevert 取出并删除撤销令牌，令牌不存在或已过期时返回 nil
+	TakeEmailRevert(ctx context.Context, token string) (*EmailRevert, error)
+}
+
+// PermissionStore 权限查询接口，RoleRepository（gorm）和 memory.RoleRepository（内存）都实现了它
+type PermissionStore interface {
+	// GetPermissionsByUserID 查询用户通过角色获得的全部权限
+	GetPermissionsByUserID(ctx context.Context, userID uint) ([]string, error)
+}
+
+var (
+	_ UserStore     = (*LoginRepository)(nil)
+	_ TokenStore    = (*TokenRepository)(nil)
+	_ CodeStore     = (*CodeRepository)(nil)
+	_ SecurityStore = (*SecurityRepository)(nil)
+
+	_ PermissionStore = (*RoleRepository)(nil)
+)
@@ -0,0 +1,207 @@
+// 存储接口的一致性测试：同一组用例分别跑在内存实现和真实实现上，保证两者行为一致，
+// logic 层因此可以放心地用内存实现做离线单元测试。
+//
+// 真实实现使用 SQLite（代替 MySQL）和 miniredis（代替 Redis），不依赖外部服务。
+package repository_test
+
+import (
+	"blueLock/backend/internal/migrations"
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/database"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/kv"
+	"blueLock/backend/internal/pkg/migrate"
+	"blueLock/backend/internal/repository"
+	"blueLock/backend/internal/repository/memory"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openSQLite 打开一个内存 SQLite 数据库并执行全部迁移，连接配置与线上一致（表前缀、错误转换）
+func openSQLite(t testing.TB) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(globals.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"},
+		logger.Default.LogMode(logger.Silent))
+	if err != nil {
+		t.Fatalf("打开 SQLite 失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	// 与线上一样通过版本化迁移建表
+	m, err := migrate.New(db, migrations.All())
+	if err != nil {
+		t.Fatalf("创建迁移执行器失败: %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("迁移 SQLite 表失败: %v", err)
+	}
+	return db
+}
+
+// newRedisStore 启动一个进程内的 Redis 替身，返回带测试前缀的键值存储，测试结束时自动关闭
+func newRedisStore(t testing.TB) kv.Store {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return kv.NewRedisStore(rdb, "test:")
+}
+
+func TestUserStore(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		userStoreSuite(t, func(t *testing.T) repository.UserStore {
+			return memory.NewUserRepository()
+		})
+	})
+	t.Run("sql", func(t *testing.T) {
+		userStoreSuite(t, func(t *testing.T) repository.UserStore {
+			return repository.NewLoginRepository(openSQLite(t))
+		})
+	})
+}
+
+func TestTokenStore(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		tokenStoreSuite(t, func(t *testing.T) repository.TokenStore {
+			return memory.NewTokenRepository(nil)
+		})
+	})
+	t.Run("kv-memory", func(t *testing.T) {
+		tokenStoreSuite(t, func(t *testing.T) repository.TokenStore {
+			return repository.NewTokenRepository(kv.NewMemoryStore(0))
+		})
+	})
+	t.Run("redis", func(t *testing.T) {
+		tokenStoreSuite(t, func(t *testing.T) repository.TokenStore {
+			return repository.NewTokenRepository(newRedisStore(t))
+		})
+	})
+}
+
+// TestTokenRepositoryLegacyKeys 升级前写入旧键名的刷新令牌和吊销时间仍然有效。
+// 旧键写入时还没有键前缀，Redis 配置了 key_prefix 后也要能读到和删除不带前缀的旧键
+func TestTokenRepositoryLegacyKeys(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		store := kv.NewMemoryStore(0)
+		t.Cleanup(func() { _ = store.Close() })
+		legacyKeysSuite(t, store, store)
+	})
+	t.Run("redis-prefixed", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { _ = rdb.Close() })
+		// 旧键由升级前的代码直接写入，不带前缀
+		legacyKeysSuite(t, kv.NewRedisStore(rdb, "dev:"), kv.NewRedisStore(rdb, ""))
+	})
+}
+
+// legacyKeysSuite raw 为写入旧键时使用的存储
+func legacyKeysSuite(t *testing.T, store, raw kv.Store) {
+	ctx := context.Background()
+	repo := repository.NewTokenRepository(store)
+
+	revokedAt := time.Now().Add(-time.Minute).Unix() // 旧版本按秒保存
+	if err := raw.Set(ctx, "user:refresh_token:7", "legacy-token", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := raw.Set(ctx, "user:revoked_at:7", strconv.FormatInt(revokedAt, 10), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := repo.GetRefreshToken(ctx, 7); err != nil || got != "legacy-token" {
+		t.Fatalf("GetRefreshToken = %q, %v", got, err)
+	}
+	if got, err := repo.GetRevokedAt(ctx, 7); err != nil || got != revokedAt*1000 {
+		t.Fatalf("GetRevokedAt = %d, %v", got, err)
+	}
+	if ok, err := repo.ConsumeRefreshToken(ctx, 7, "other-token"); err != nil || ok {
+		t.Fatalf("不同的令牌被消费: %v, %v", ok, err)
+	}
+	if ok, err := repo.ConsumeRefreshToken(ctx, 7, "legacy-token"); err != nil || !ok {
+		t.Fatalf("ConsumeRefreshToken = %v, %v", ok, err)
+	}
+	if got, _ := repo.GetRefreshToken(ctx, 7); got != "" {
+		t.Fatalf("消费后仍能读到旧令牌 %q", got)
+	}
+	if _, err := raw.Get(ctx, "user:refresh_token:7"); !errors.Is(err, kv.ErrNotFound) {
+		t.Fatalf("消费后旧键仍然存在: %v", err)
+	}
+
+	// 新键优先，删除时新旧键一起删除
+	if err := raw.Set(ctx, "user:refresh_token:7", "legacy-token", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveRefreshToken(ctx, 7, "new-token", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetRefreshToken(ctx, 7); got != "new-token" {
+		t.Fatalf("GetRefreshToken = %q，期望新键的值", got)
+	}
+	if err := repo.DeleteRefreshToken(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetRefreshToken(ctx, 7); got != "" {
+		t.Fatalf("删除后仍能读到令牌 %q", got)
+	}
+	if _, err := raw.Get(ctx, "user:refresh_token:7"); !errors.Is(err, kv.ErrNotFound) {
+		t.Fatalf("删除后旧键仍然存在: %v", err)
+	}
+
+	// 新的吊销时间写入带前缀的新键，优先于旧键
+	if err := repo.RevokeUserTokens(ctx, 7, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetRevokedAt(ctx, 7); got <= revokedAt*1000 {
+		t.Fatalf("GetRevokedAt = %d，期望新的吊销时间", got)
+	}
+}
+
+func TestPermissionStore(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		permissionStoreSuite(t, func(t *testing.T) roleStore {
+			return memory.NewRoleRepository()
+		})
+	})
+	t.Run("sql", func(t *testing.T) {
+		permissionStoreSuite(t, func(t *testing.T) roleStore {
+			db := openSQLite(t)
+			users := repository.NewLoginRepository(db)
+			for _, email := range []string{"a@example.com", "b@example.com"} {
+				if err := users.CreateUser(context.Background(), &models.User{Email: email, PassWord: "x"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return repository.NewRoleRepository(db)
+		})
+	})
+}
+
+func TestCodeStore(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		codeStoreSuite(t, func(t *testing.T) repository.CodeStore {
+			return memory.NewCodeRepository(nil)
+		})
+	})
+	t.Run("kv-memory", func(t *testing.T) {
+		codeStoreSuite(t, func(t *testing.T) repository.CodeStore {
+			return repository.NewCodeRepository(kv.NewMemoryStore(0))
+		})
+	})
+	t.Run("redis", func(t *testing.T) {
+		codeStoreSuite(t, func(t *testing.T) repository.CodeStore {
+			return repository.NewCodeRepository(newRedisStore(t))
+		})
+	})
+}
@@ -0,0 +1,380 @@
+package repository_test
+
+import (
+	"blueLock/backend/internal/models"
+	"blueLock/backend/internal/pkg/apperr"
+	"blueLock/backend/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userStoreSuite UserStore 的一致性用例，newStore 每次返回一个空的存储
+func userStoreSuite(t *testing.T, newStore func(t *testing.T) repository.UserStore) {
+	ctx := context.Background()
+
+	create := func(t *testing.T, s repository.UserStore, email, password string) *models.User {
+		t.Helper()
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user := &models.User{Email: email, PassWord: string(hashed), Locale: "zh-CN"}
+		if err := s.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser(%s): %v", email, err)
+		}
+		if user.ID == 0 {
+			t.Fatalf("CreateUser(%s) 没有回填 id", email)
+		}
+		return user
+	}
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		s := newStore(t)
+		user := create(t, s, "a@example.com", "secret1")
+
+		byID, err := s.GetUserByID(ctx, user.ID)
+		if err != nil || byID.Email != "a@example.com" || byID.Locale != "zh-CN" {
+			t.Fatalf("GetUserByID = %+v, %v", byID, err)
+		}
+		byEmail, err := s.GetUserByEmail(ctx, "a@example.com")
+		if err != nil || byEmail.ID != user.ID {
+			t.Fatalf("GetUserByEmail = %+v, %v", byEmail, err)
+		}
+		exists, err := s.ExistsByEmail(ctx, "a@example.com")
+		if err != nil || !exists {
+			t.Fatalf("ExistsByEmail = %v, %v", exists, err)
+		}
+	})
+
+	t.Run("DuplicateEmail", func(t *testing.T) {
+		s := newStore(t)
+		create(t, s, "a@example.com", "secret1")
+		err := s.CreateUser(ctx, &models.User{Email: "a@example.com"})
+		if !errors.Is(err, apperr.ErrEmailTaken) {
+			t.Fatalf("重复邮箱 CreateUser 错误 = %v, 期望 ErrEmailTaken", err)
+		}
+		err = s.CreateUser(ctx, &models.User{Email: " A@Example.COM "})
+		if !errors.Is(err, apperr.ErrEmailTaken) {
+			t.Fatalf("大小写不同的重复邮箱 CreateUser 错误 = %v, 期望 ErrEmailTaken", err)
+		}
+	})
+
+	t.Run("NormalizedEmail", func(t *testing.T) {
+		s := newStore(t)
+		user := create(t, s, "Mixed.Case@Example.com", "secret1")
+		if user.Email != "mixed.case@example.com" {
+			t.Fatalf("CreateUser 后 Email = %q, 期望规范化为小写", user.Email)
+		}
+		byEmail, err := s.GetUserByEmail(ctx, "MIXED.CASE@example.com")
+		if err != nil || byEmail.ID != user.ID {
+			t.Fatalf("GetUserByEmail = %+v, %v", byEmail, err)
+		}
+		if exists, err := s.ExistsByEmail(ctx, " mixed.case@EXAMPLE.com"); err != nil || !exists {
+			t.Fatalf("ExistsByEmail = %v, %v", exists, err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		s := newStore(t)
+		if _, err := s.GetUserByID(ctx, 42); !errors.Is(err, apperr.ErrUserNotFound) {
+			t.Fatalf("GetUserByID 错误 = %v", err)
+		}
+		if _, err := s.GetUserByEmail(ctx, "none@example.com"); !errors.Is(err, apperr.ErrUserNotFound) {
+			t.Fatalf("GetUserByEmail 错误 = %v", err)
+		}
+		if exists, err := s.ExistsByEmail(ctx, "none@example.com"); err != nil || exists {
+			t.Fatalf("ExistsByEmail = %v, %v", exists, err)
+		}
+		if err := s.SetDisabled(ctx, 42, true); !errors.Is(err, apperr.ErrUserNotFound) {
+			t.Fatalf("SetDisabled 错误 = %v", err)
+		}
+	})
+
+	t.Run("Password", func(t *testing.T) {
+		s := newStore(t)
+		// 密码校验由 password.Hasher 完成，存储层只负责原样保存和读取哈希
+		user := create(t, s, "a@example.com", "secret1")
+		if got, err := s.GetUserByEmail(ctx, "a@example.com"); err != nil || got.PassWord != user.PassWord {
+			t.Fatalf("GetUserByEmail 返回的哈希 = %q, %v, 期望 %q", got.PassWord, err, user.PassWord)
+		}
+
+		hashed := "$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHRzYWx0c2FsdA$aGFzaGhhc2hoYXNoaGFzaGhhc2hoYXNoaGFzaGhhc2g"
+		if err := s.UpdatePassword(ctx, user.ID, hashed); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.GetUserByID(ctx, user.ID); err != nil || got.PassWord != hashed {
+			t.Fatalf("UpdatePassword 后哈希 = %q, %v", got.PassWord, err)
+		}
+	})
+
+	t.Run("DisableAndLocale", func(t *testing.T) {
+		s := newStore(t)
+		user := create(t, s, "a@example.com", "secret1")
+		if err := s.SetDisabled(ctx, user.ID, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.UpdateLocale(ctx, user.ID, "en"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.GetUserByID(ctx, user.ID)
+		if err != nil || !got.Disabled || got.Locale != "en" {
+			t.Fatalf("GetUserByID = %+v, %v", got, err)
+		}
+		if err := s.SetDisabled(ctx, user.ID, false); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := s.GetUserByID(ctx, user.ID); got.Disabled {
+			t.Fatal("启用后仍是禁用状态")
+		}
+	})
+
+	t.Run("EmailAndNotify", func(t *testing.T) {
+		s := newStore(t)
+		user := create(t, s, "a@example.com", "secret1")
+		other := create(t, s, "b@example.com", "secret1")
+		if err := s.UpdateEmail(ctx, user.ID, " New@Example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.GetUserByEmail(ctx, "new@example.com"); err != nil || got.ID != user.ID {
+			t.Fatalf("UpdateEmail 后 GetUserByEmail = %+v, %v", got, err)
+		}
+		if exists, _ := s.ExistsByEmail(ctx, "a@example.com"); exists {
+			t.Fatal("UpdateEmail 后旧邮箱仍存在")
+		}
+		if err := s.UpdateEmail(ctx, other.ID, "NEW@example.com"); !errors.Is(err, apperr.ErrEmailTaken) {
+			t.Fatalf("改成已被使用的邮箱 UpdateEmail 错误 = %v, 期望 ErrEmailTaken", err)
+		}
+		if err := s.UpdateNotifyOptOut(ctx, user.ID, "new_login"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.GetUserByID(ctx, user.ID); err != nil || got.NotifyOptOut != "new_login" {
+			t.Fatalf("UpdateNotifyOptOut 后 NotifyOptOut = %q, %v", got.NotifyOptOut, err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		s := newStore(t)
+		for i := 1; i <= 5; i++ {
+			create(t, s, fmt.Sprintf("user%d@example.com", i), "secret1")
+		}
+		create(t, s, "other@test.org", "secret1")
+
+		users, total, err := s.ListUsers(ctx, "", 0, 10)
+		if err != nil || total != 6 || len(users) != 6 {
+			t.Fatalf("ListUsers 全部 = %d 条/共 %d, %v", len(users), total, err)
+		}
+		for i := 1; i < len(users); i++ {
+			if users[i-1].ID >= users[i].ID {
+				t.Fatal("ListUsers 没有按 id 升序")
+			}
+		}
+
+		users, total, err = s.ListUsers(ctx, "example.com", 2, 2)
+		if err != nil || total != 5 || len(users) != 2 || users[0].Email != "user3@example.com" {
+			t.Fatalf("ListUsers 分页搜索 = %+v/共 %d, %v", users, total, err)
+		}
+
+		users, total, err = s.ListUsers(ctx, "example.com", 10, 2)
+		if err != nil || total != 5 || len(users) != 0 {
+			t.Fatalf("ListUsers 越界分页 = %d 条/共 %d, %v", len(users), total, err)
+		}
+	})
+}
+
+// tokenStoreSuite TokenStore 的一致性用例
+func tokenStoreSuite(t *testing.T, newStore func(t *testing.T) repository.TokenStore) {
+	ctx := context.Background()
+
+	t.Run("RefreshToken", func(t *testing.T) {
+		s := newStore(t)
+		if got, err := s.GetRefreshToken(ctx, 1); err != nil || got != "" {
+			t.Fatalf("未保存时 GetRefreshToken = %q, %v", got, err)
+		}
+		if err := s.SaveRefreshToken(ctx, 1, "t1", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveRefreshToken(ctx, 1, "t2", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.GetRefreshToken(ctx, 1); err != nil || got != "t2" {
+			t.Fatalf("GetRefreshToken = %q, %v, 期望最新的 t2", got, err)
+		}
+		if got, _ := s.GetRefreshToken(ctx, 2); got != "" {
+			t.Fatalf("其他用户 GetRefreshToken = %q", got)
+		}
+		if err := s.DeleteRefreshToken(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.GetRefreshToken(ctx, 1); err != nil || got != "" {
+			t.Fatalf("删除后 GetRefreshToken = %q, %v", got, err)
+		}
+	})
+
+	t.Run("ConsumeRefreshToken", func(t *testing.T) {
+		s := newStore(t)
+		if ok, err := s.ConsumeRefreshToken(ctx, 1, "t1"); err != nil || ok {
+			t.Fatalf("未保存时 ConsumeRefreshToken = %v, %v", ok, err)
+		}
+		if err := s.SaveRefreshToken(ctx, 1, "t1", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := s.ConsumeRefreshToken(ctx, 1, "other"); err != nil || ok {
+			t.Fatalf("令牌不同时 ConsumeRefreshToken = %v, %v", ok, err)
+		}
+		if ok, err := s.ConsumeRefreshToken(ctx, 2, "t1"); err != nil || ok {
+			t.Fatalf("其他用户 ConsumeRefreshToken = %v, %v", ok, err)
+		}
+		if ok, err := s.ConsumeRefreshToken(ctx, 1, "t1"); err != nil || !ok {
+			t.Fatalf("令牌相同时 ConsumeRefreshToken = %v, %v", ok, err)
+		}
+		if ok, _ := s.ConsumeRefreshToken(ctx, 1, "t1"); ok {
+			t.Fatal("刷新令牌被使用了两次")
+		}
+		if got, _ := s.GetRefreshToken(ctx, 1); got != "" {
+			t.Fatalf("使用后 GetRefreshToken = %q", got)
+		}
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		s := newStore(t)
+		if at, err := s.GetRevokedAt(ctx, 1); err != nil || at != 0 {
+			t.Fatalf("未吊销时 GetRevokedAt = %d, %v", at, err)
+		}
+		if err := s.SaveRefreshToken(ctx, 1, "t1", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		before := time.Now().UnixMilli()
+		if err := s.RevokeUserTokens(ctx, 1, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		at, err := s.GetRevokedAt(ctx, 1)
+		if err != nil || at < before || at > time.Now().UnixMilli() {
+			t.Fatalf("GetRevokedAt = %d, %v", at, err)
+		}
+		if got, _ := s.GetRefreshToken(ctx, 1); got != "" {
+			t.Fatalf("吊销后刷新令牌仍存在: %q", got)
+		}
+	})
+
+	t.Run("UsedRefreshToken", func(t *testing.T) {
+		s := newStore(t)
+		if used, err := s.IsRefreshTokenUsed(ctx, 1, "t1"); err != nil || used {
+			t.Fatalf("未标记时 IsRefreshTokenUsed = %v, %v", used, err)
+		}
+		if err := s.MarkRefreshTokenUsed(ctx, 1, "t1", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if used, err := s.IsRefreshTokenUsed(ctx, 1, "t1"); err != nil || !used {
+			t.Fatalf("标记后 IsRefreshTokenUsed = %v, %v", used, err)
+		}
+		if used, _ := s.IsRefreshTokenUsed(ctx, 1, "t2"); used {
+			t.Fatal("未标记的令牌被认为已使用")
+		}
+		if used, _ := s.IsRefreshTokenUsed(ctx, 2, "t1"); used {
+			t.Fatal("其他用户的同名令牌被认为已使用")
+		}
+	})
+}
+
+// codeStoreSuite CodeStore 的一致性用例
+func codeStoreSuite(t *testing.T, newStore func(t *testing.T) repository.CodeStore) {
+	ctx := context.Background()
+
+	s := newStore(t)
+	if got, err := s.GetCode(ctx, "a@example.com"); err != nil || got != "" {
+		t.Fatalf("未保存时 GetCode = %q, %v", got, err)
+	}
+	if err := s.SaveCode(ctx, "a@example.com", "111111", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveCode(ctx, "a@example.com", "222222", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetCode(ctx, "a@example.com"); err != nil || got != "222222" {
+		t.Fatalf("GetCode = %q, %v, 期望覆盖后的 222222", got, err)
+	}
+	if got, err := s.GetCode(ctx, "A@Example.com"); err != nil || got != "222222" {
+		t.Fatalf("大小写不同的邮箱 GetCode = %q, %v, 期望 222222", got, err)
+	}
+	if got, _ := s.GetCode(ctx, "b@example.com"); got != "" {
+		t.Fatalf("其他邮箱 GetCode = %q", got)
+	}
+	if ok, err := s.ConsumeCode(ctx, "a@example.com", "111111"); err != nil || ok {
+		t.Fatalf("错误验证码 ConsumeCode = %v, %v", ok, err)
+	}
+	if ok, err := s.ConsumeCode(ctx, "a@example.com", "222222"); err != nil || !ok {
+		t.Fatalf("正确验证码 ConsumeCode = %v, %v", ok, err)
+	}
+	if ok, _ := s.ConsumeCode(ctx, "a@example.com", "222222"); ok {
+		t.Fatal("验证码被使用了两次")
+	}
+
+	if err := s.SaveCode(ctx, "a@example.com", "333333", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteCode(ctx, "a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetCode(ctx, "a@example.com"); err != nil || got != "" {
+		t.Fatalf("删除后 GetCode = %q, %v", got, err)
+	}
+}
+
+// roleStore 权限查询之外，用例还需要创建角色和分配角色
+type roleStore interface {
+	repository.PermissionStore
+	EnsureRole(ctx context.Context, name string, permissions []string) error
+	AssignRole(ctx context.Context, userID uint, roleName string) error
+}
+
+// permissionStoreSuite PermissionStore 的一致性用例，newStore 返回的存储中已有 id 为 1、2 的用户
+func permissionStoreSuite(t *testing.T, newStore func(t *testing.T) roleStore) {
+	ctx := context.Background()
+	s := newStore(t)
+
+	if perms, err := s.GetPermissionsByUserID(ctx, 1); err != nil || len(perms) != 0 {
+		t.Fatalf("没有角色时 GetPermissionsByUserID = %v, %v", perms, err)
+	}
+	if err := s.EnsureRole(ctx, "admin", []string{"user:read", "user:write"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EnsureRole(ctx, "viewer", []string{"user:read"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AssignRole(ctx, 1, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AssignRole(ctx, 1, "viewer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AssignRole(ctx, 2, "missing"); err == nil {
+		t.Fatal("分配不存在的角色没有报错")
+	}
+
+	// 多个角色的相同权限只返回一次
+	perms, err := s.GetPermissionsByUserID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(perms)
+	if fmt.Sprint(perms) != "[user:read user:write]" {
+		t.Fatalf("GetPermissionsByUserID = %v", perms)
+	}
+
+	// 同步角色的权限后立即生效
+	if err := s.EnsureRole(ctx, "admin", []string{"user:read"}); err != nil {
+		t.Fatal(err)
+	}
+	if perms, err := s.GetPermissionsByUserID(ctx, 1); err != nil || fmt.Sprint(perms) != "[user:read]" {
+		t.Fatalf("同步权限后 GetPermissionsByUserID = %v, %v", perms, err)
+	}
+	if perms, err := s.GetPermissionsByUserID(ctx, 2); err != nil || len(perms) != 0 {
+		t.Fatalf("其他用户 GetPermissionsByUserID = %v, %v", perms, err)
+	}
+}
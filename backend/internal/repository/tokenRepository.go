@@ -1,25 +1,30 @@
 package repository
 
 import (
+	"blueLock/backend/internal/pkg/kv"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"github.com/go-redis/redis/v8"
-	"gorm.io/gorm"
+	"strconv"
 	"time"
 )
 
 // TokenRepository Token相关数据访问层
 type TokenRepository struct {
-	db    *gorm.DB
-	redis *redis.Client
+	store kv.Store
+	// legacy 读写升级前旧键的存储。旧键写入时还没有 redis.key_prefix，Redis 存储下不加前缀
+	legacy kv.Store
 }
 
 // NewTokenRepository 创建Token数据访问实现
-func NewTokenRepository(db *gorm.DB, redis *redis.Client) *TokenRepository {
-	return &TokenRepository{
-		db:    db,
-		redis: redis,
+func NewTokenRepository(store kv.Store) *TokenRepository {
+	legacy := store
+	if s, ok := store.(interface{ Unprefixed() kv.Store }); ok {
+		legacy = s.Unprefixed()
 	}
+	return &TokenRepository{store: store, legacy: legacy}
 }
 
 // SaveRefreshToken 保存刷新令牌
@@ -28,18 +33,118 @@ func (r *TokenRepository) SaveRefreshToken(
 	userID uint, token string,
 	expiry time.Duration,
 ) error {
-	key := fmt.Sprintf("user:refresh_token:%d", userID)
-	return r.redis.Set(ctx, key, token, expiry).Err()
+	key := refreshTokenKey(userID)
+	return r.store.Set(ctx, key, token, expiry)
 }
 
-// GetRefreshToken 获取刷新令牌
+// GetRefreshToken 获取刷新令牌，不存在时返回空字符串
 func (r *TokenRepository) GetRefreshToken(ctx context.Context, userID uint) (string, error) {
-	key := fmt.Sprintf("user:refresh_token:%d", userID)
-	return r.redis.Get(ctx, key).Result()
+	token, err := r.getWithLegacy(ctx, refreshTokenKey(userID), legacyRefreshTokenKey(userID))
+	if errors.Is(err, kv.ErrNotFound) {
+		return "", nil
+	}
+	return token, err
 }
 
 // DeleteRefreshToken 删除刷新令牌
 func (r *TokenRepository) DeleteRefreshToken(ctx context.Context, userID uint) error {
-	key := fmt.Sprintf("user:refresh_token:%d", userID)
-	return r.redis.Del(ctx, key).Err()
+	if err := r.store.Del(ctx, refreshTokenKey(userID)); err != nil {
+		return err
+	}
+	return r.legacy.Del(ctx, legacyRefreshTokenKey(userID))
+}
+
+// ConsumeRefreshToken 刷新令牌与 token 相同时原子地删除
+func (r *TokenRepository) ConsumeRefreshToken(ctx context.Context, userID uint, token string) (bool, error) {
+	ok, err := r.store.CompareAndDelete(ctx, refreshTokenKey(userID), token)
+	if err != nil || ok {
+		return ok, err
+	}
+	return r.legacy.CompareAndDelete(ctx, legacyRefreshTokenKey(userID), token)
+}
+
+// RevokeUserTokens 强制用户下线：删除刷新令牌，并记录吊销时间，早于该时间签发的访问令牌一律失效
+func (r *TokenRepository) RevokeUserTokens(ctx context.Context, userID uint, expiry time.Duration) error {
+	if err := r.DeleteRefreshToken(ctx, userID); err != nil {
+		return err
+	}
+	key := revokedAtKey(userID)
+	return r.store.Set(ctx, key, strconv.FormatInt(time.Now().UnixMilli(), 10), expiry)
+}
+
+// GetRevokedAt 获取用户令牌的吊销时间（unix 毫秒），未吊销时返回 0
+func (r *TokenRepository) GetRevokedAt(ctx context.Context, userID uint) (int64, error) {
+	value, err := r.getWithLegacy(ctx, revokedAtKey(userID), legacyRevokedAtKey(userID))
+	if errors.Is(err, kv.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseRevokedAt(value)
+}
+
+// parseRevokedAt 解析吊销时间。旧版本按秒保存，统一换算为毫秒
+func parseRevokedAt(value string) (int64, error) {
+	at, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if at < 1e12 {
+		at *= 1000
+	}
+	return at, nil
+}
+
+// MarkRefreshTokenUsed 记录已被轮换掉的旧刷新令牌，只保存摘要，保留到它原本过期为止
+func (r *TokenRepository) MarkRefreshTokenUsed(ctx context.Context, userID uint, token string, expiry time.Duration) error {
+	return r.store.Set(ctx, usedRefreshTokenKey(userID, token), "1", expiry)
+}
+
+// IsRefreshTokenUsed 判断刷新令牌是否已被轮换掉
+func (r *TokenRepository) IsRefreshTokenUsed(ctx context.Context, userID uint, token string) (bool, error) {
+	_, err := r.store.Get(ctx, usedRefreshTokenKey(userID, token))
+	if errors.Is(err, kv.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// getWithLegacy 先读新键，不存在时读升级前的旧键
+func (r *TokenRepository) getWithLegacy(ctx context.Context, key, legacyKey string) (string, error) {
+	value, err := r.store.Get(ctx, key)
+	if !errors.Is(err, kv.ErrNotFound) {
+		return value, err
+	}
+	return r.legacy.Get(ctx, legacyKey)
+}
+
+// 同一用户的键使用相同的哈希标签 {user:<id>}，集群模式下落在同一个槽
+func refreshTokenKey(userID uint) string {
+	return fmt.Sprintf("%s:refresh_token", kv.HashTag(fmt.Sprintf("user:%d", userID)))
+}
+
+func revokedAtKey(userID uint) string {
+	return fmt.Sprintf("%s:revoked_at", kv.HashTag(fmt.Sprintf("user:%d", userID)))
+}
+
+// 使用哈希标签之前的键名。升级后旧键中的刷新令牌和吊销时间仍然有效，读取时回退到旧键，
+// 避免升级导致所有用户被迫重新登录、或已吊销的令牌重新生效。旧键最长保留 jwt.refresh_token_expiry，
+// 此后不再有旧键，可以删除这两个函数及回退逻辑
+func legacyRefreshTokenKey(userID uint) string {
+	return fmt.Sprintf("user:refresh_token:%d", userID)
+}
+
+func legacyRevokedAtKey(userID uint) string {
+	return fmt.Sprintf("user:revoked_at:%d", userID)
+}
+
+func usedRefreshTokenKey(userID uint, token string) string {
+	return fmt.Sprintf("%s:refresh_used:%s", kv.HashTag(fmt.Sprintf("user:%d", userID)), digest(token))
+}
+
+// digest 返回 sha256 摘要的十六进制字符串，令牌类的值不以明文作为键名
+func digest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
 }
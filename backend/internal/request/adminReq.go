@@ -0,0 +1,33 @@
+package request
+
+import "time"
+
+// AdminListUsersRequest 管理端查询用户列表的请求参数
+type AdminListUsersRequest struct {
+	Keyword string `form:"q"`
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	Size    int    `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+// AdminUserURI 管理端操作单个用户的路径参数
+type AdminUserURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// AdminAuditQueryRequest 管理端查询审计日志的请求参数，时间使用 RFC3339 格式
+type AdminAuditQueryRequest struct {
+	ActorID  uint      `form:"actor_id"`
+	Action   string    `form:"action"`
+	TargetID string    `form:"target_id"`
+	Result   string    `form:"result" binding:"omitempty,oneof=success failure"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page     int       `form:"page" binding:"omitempty,min=1"`
+	Size     int       `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+// AdminLogLevelRequest 管理端修改日志级别的请求体
+type AdminLogLevelRequest struct {
+	Output string `json:"output" binding:"required,oneof=console file"`
+	Level  string `json:"level" binding:"required,oneof=debug info warn error"`
+}
@@ -0,0 +1,6 @@
+package request
+
+// ChallengeURI 获取人机校验题目的路径参数
+type ChallengeURI struct {
+	Type string `uri:"type" binding:"required,max=16"`
+}
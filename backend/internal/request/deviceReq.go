@@ -0,0 +1,12 @@
+package request
+
+// BindDeviceRequest 绑定设备请求体
+type BindDeviceRequest struct {
+	Serial string `json:"serial" binding:"required,max=64"`
+	Name   string `json:"name" binding:"max=64"`
+}
+
+// DeviceURI 操作单个设备的路径参数
+type DeviceURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
@@ -2,24 +2,78 @@ package request
 
 // SendVerificationCodeRequest 发送验证码的请求体
 type SendVerificationCodeRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" binding:"required,email_addr"`
 }
 
 // RegisterByVerificationCodeRequest 邮箱注册的请求体
 type RegisterByVerificationCodeRequest struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Code     string `json:"code" binding:"required"`
+	Email    string `json:"email" binding:"required,email_addr"`
+	Password string `json:"password" binding:"required"` // 长度、字符类别等由密码策略校验
+	Code     string `json:"code" binding:"required,len=6,numeric"`
+	Locale   string `json:"locale" binding:"omitempty,max=16"` // 语言偏好，不传时使用请求协商出的语言
 }
 
-// LoginByPassORCode 登录的请求体
+// LoginByPassORCode 登录的请求体，密码和验证码二选一。登录不校验密码策略，策略收紧前设置的密码仍可使用
 type LoginByPassORCode struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Code     string `json:"code"`
+	Email    string `json:"email" binding:"required,email_addr"`
+	Password string `json:"password" binding:"max=1024"`
+	Code     string `json:"code" binding:"omitempty,len=6,numeric"`
 }
 
 // RefreshTokenRequest 刷新令牌请求体
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string `json:"refresh_token" binding:"required,max=4096"`
+}
+
+// ChangePasswordRequest 修改密码请求体，通过第三方登录创建、尚未设置密码的账号可以不传旧密码
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"max=1024"`
+	NewPassword string `json:"new_password" binding:"required"` // 由密码策略校验
+}
+
+// UpdateLocaleRequest 修改语言偏好请求体
+type UpdateLocaleRequest struct {
+	Locale string `json:"locale" binding:"required,max=16"`
+}
+
+// ChangeEmailRequest 修改邮箱请求体，验证码需发送到新邮箱；已设置密码的账号必须提供当前密码
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email_addr"`
+	Code     string `json:"code" binding:"required,len=6,numeric"`
+	Password string `json:"password" binding:"max=1024"`
+}
+
+// RevertEmailRequest 撤销邮箱修改请求体，token 来自发往旧邮箱的通知邮件
+type RevertEmailRequest struct {
+	Token string `json:"token" binding:"required,max=128"`
+}
+
+// UpdateNotificationRequest 开启或关闭一种通知
+type UpdateNotificationRequest struct {
+	Kind    string `json:"kind" binding:"required,max=32"`
+	Enabled *bool  `json:"enabled" binding:"required"`
+}
+
+// MagicLinkStartRequest 发起邮件链接登录请求体
+type MagicLinkStartRequest struct {
+	Email  string `json:"email" binding:"required,email_addr"`
+	Locale string `json:"locale" binding:"omitempty,max=16"` // 邮件语言，为空时使用用户保存的语言偏好
+}
+
+// MagicLinkVerifyRequest 打开邮件链接请求体，在发起登录的设备上打开时带上 nonce
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" binding:"required,max=512"`
+	Nonce string `json:"nonce" binding:"max=128"`
+}
+
+// MagicLinkApproveRequest 在其他设备上批准登录请求体
+type MagicLinkApproveRequest struct {
+	Token string `json:"token" binding:"required,max=512"`
+}
+
+// MagicLinkPollRequest 发起方轮询登录结果请求体，wait 为最长等待秒数，0 表示立即返回
+type MagicLinkPollRequest struct {
+	RequestID string `json:"request_id" binding:"required,max=64"`
+	Nonce     string `json:"nonce" binding:"required,max=128"`
+	Wait      int    `json:"wait" binding:"gte=0,lte=60"`
 }
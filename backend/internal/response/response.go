@@ -1,9 +1,18 @@
 package response
 
 type ErrorResponse struct {
-	Code    int    `json:"code"`
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Error   string       `json:"error"`
+	Fields  []FieldError `json:"fields,omitempty"` // 字段级校验错误，只在参数校验未通过时返回
+}
+
+// FieldError 单个字段的校验错误，客户端按 Rule 分支处理，Message 为已翻译的提示文案
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
 	Message string `json:"message"`
-	Error   string `json:"error"`
 }
 
 type Success struct {
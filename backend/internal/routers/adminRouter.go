@@ -0,0 +1,43 @@
+package routers
+
+import (
+	"blueLock/backend/internal/app"
+	"blueLock/backend/internal/controller"
+	"blueLock/backend/internal/middleware"
+	"blueLock/backend/internal/pkg/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRouter 管理端路由，全部需要登录并按接口校验权限
+func AdminRouter(r *gin.Engine, a *app.App) {
+	h := controller.NewAdminHandler(a.Admin, a.Audit)
+	logLevel := controller.NewLogLevelHandler(a.LogLevels)
+	perm := func(perms ...string) gin.HandlerFunc {
+		return middleware.RequirePermission(a.RoleRepo, perms...)
+	}
+
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(a.TokenService, a.TokenRepo))
+	{
+		// 用户列表、搜索
+		admin.GET("/users", perm(rbac.PermUserRead), h.ListUsers)
+		// 用户详情
+		admin.GET("/users/:id", perm(rbac.PermUserRead), h.GetUser)
+		// 禁用账号
+		admin.POST("/users/:id/disable", perm(rbac.PermUserWrite), h.DisableUser)
+		// 启用账号
+		admin.POST("/users/:id/enable", perm(rbac.PermUserWrite), h.EnableUser)
+		// 强制下线
+		admin.POST("/users/:id/logout", perm(rbac.PermSessionRevoke), h.ForceLogout)
+		// 查看设备
+		admin.GET("/users/:id/devices", perm(rbac.PermDeviceRead), h.ListDevices)
+		// 审计日志查询
+		admin.GET("/audit", perm(rbac.PermAuditRead), h.AuditQuery)
+		// 审计日志哈希链校验
+		admin.GET("/audit/verify", perm(rbac.PermAuditRead), h.AuditVerify)
+		// 查看日志级别
+		admin.GET("/log/level", perm(rbac.PermLogLevel), logLevel.GetLevels)
+		// 运行时修改日志级别
+		admin.PUT("/log/level", perm(rbac.PermLogLevel), logLevel.SetLevel)
+	}
+}
@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"blueLock/backend/internal/app"
+	"blueLock/backend/internal/controller"
+	"blueLock/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// ChallengeRouter 人机校验题目路由，不需要登录。受保护的接口在各自的路由上通过 middleware.Challenge 启用校验
+func ChallengeRouter(r *gin.Engine, a *app.App) {
+	h := controller.NewChallengeHandler(a.Challenge)
+	challenge := r.Group("/challenge")
+	challenge.Use(middleware.BodyLimit(smallBodyLimit))
+	// 可用的校验方式
+	challenge.GET("", h.Types)
+	// 获取题目
+	challenge.POST("/:type", h.Issue)
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"blueLock/backend/internal/app"
+	"blueLock/backend/internal/controller"
+	"blueLock/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceGatewayRouter 设备网关路由，以客户端证书（mTLS）认证设备，不接受用户令牌
+func DeviceGatewayRouter(r *gin.Engine, a *app.App) {
+	h := controller.NewDeviceGatewayHandler(a.Device)
+
+	gateway := r.Group("/gateway")
+	gateway.Use(middleware.BodyLimit(smallBodyLimit), middleware.DeviceCertMiddleware(a.DeviceRepo))
+	{
+		// 设备上报在线
+		gateway.POST("/heartbeat", h.Heartbeat)
+	}
+}
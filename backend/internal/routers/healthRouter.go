@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"blueLock/backend/internal/app"
+	"blueLock/backend/internal/controller"
+	"blueLock/backend/internal/middleware"
+	"blueLock/backend/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// HealthRouter 存活、就绪检查路由，不需要登录。配置了 app.metrics.token 时另外提供需要令牌的 Prometheus 指标，
+// 内部监听地址上的指标见 server.Start
+func HealthRouter(r *gin.Engine, a *app.App) {
+	h := controller.NewHealthHandler(a.Health)
+	// 存活检查
+	r.GET("/healthz", h.Healthz)
+	// 就绪检查
+	r.GET("/readyz", h.Readyz)
+	// Prometheus 指标
+	if token := a.Config.App.Metrics.Token; token != "" {
+		r.GET("/metrics", middleware.StaticToken(token), gin.WrapH(metrics.Handler()))
+	}
+}
@@ -0,0 +1,4 @@
+package routers
+
+// smallBodyLimit 只提交少量字段（登录、注册、修改密码等）的接口的请求体上限
+const smallBodyLimit = 16 << 10
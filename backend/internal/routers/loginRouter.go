@@ -1,35 +1,40 @@
 package routers
 
 import (
+	"blueLock/backend/internal/app"
 	"blueLock/backend/internal/controller"
 	"blueLock/backend/internal/middleware"
-	"blueLock/backend/internal/pkg/globals"
-	"blueLock/backend/internal/pkg/token"
 	"github.com/gin-gonic/gin"
 )
 
 // EmailLoginRouter 邮箱登录注册路由
-func EmailLoginRouter(r *gin.Engine) {
+func EmailLoginRouter(r *gin.Engine, a *app.App) {
+	h := controller.NewAuthHandler(a.Login)
 	login := r.Group("/login")
-	// 发送验证码接口
-	login.POST("/sendVerificationCode", controller.SendVerificationCode())
+	// 登录注册的请求体都很小，收紧限制
+	login.Use(middleware.BodyLimit(smallBodyLimit))
+	// 发送验证码接口，请求频繁时要求先完成人机校验
+	login.POST("/sendVerificationCode", middleware.Challenge(a.Challenge, "send_code"), h.SendVerificationCode)
 	// 注册接口
-	login.POST("/register/emailRegister", controller.RegisterHandler())
+	login.POST("/register/emailRegister", h.Register)
 	// 登录接口
-	login.POST("/emailLogin", controller.LoginHandler())
+	login.POST("/emailLogin", h.Login)
 	// 刷新token接口
-	login.POST("/refreshToken", controller.RefreshToken())
+	login.POST("/refreshToken", h.RefreshToken)
+	// 撤销邮箱修改接口（链接来自发往旧邮箱的通知）
+	login.POST("/email/revert", h.RevertEmail)
+	// 邮件链接登录：发起（会发邮件，同样需要人机校验）、打开链接、在其他设备上批准、发起方轮询
+	m := controller.NewMagicLinkHandler(a.Magic)
+	login.POST("/magic", middleware.Challenge(a.Challenge, "magic_link"), m.Start)
+	login.POST("/magic/verify", m.Verify)
+	login.POST("/magic/approve", m.Approve)
+	login.POST("/magic/poll", m.Poll)
 	
 	// 需要认证的路由组
-	tokenService := token.NewService(token.Config{
-		SecretKey:          globals.AppConfig.JWT.SecretKey,
-		AccessTokenExpiry:  globals.AppConfig.JWT.AccessTokenExpiry,
-		RefreshTokenExpiry: globals.AppConfig.JWT.RefreshTokenExpiry,
-	})
 	authGroup := login.Group("")
-	authGroup.Use(middleware.AuthMiddleware(tokenService))
+	authGroup.Use(middleware.AuthMiddleware(a.TokenService, a.TokenRepo))
 	{
 		// 登出接口
-		authGroup.POST("/logout", controller.LogoutHandler())
+		authGroup.POST("/logout", h.Logout)
 	}
 }
@@ -0,0 +1,33 @@
+package routers
+
+import (
+	"blueLock/backend/internal/app"
+	"blueLock/backend/internal/controller"
+	"blueLock/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthRouter 第三方(OIDC)登录与账号绑定路由
+func OAuthRouter(r *gin.Engine, a *app.App) {
+	h := controller.NewOAuthHandler(a.OAuth)
+	oauth := r.Group("/oauth")
+	oauth.Use(middleware.BodyLimit(smallBodyLimit))
+	// 可用的登录方式
+	oauth.GET("/providers", h.Providers)
+	// 获取授权地址
+	oauth.GET("/:provider/authorize", h.Authorize)
+	// 授权回调
+	oauth.GET("/:provider/callback", h.Callback)
+
+	// 需要认证的路由组
+	authGroup := oauth.Group("")
+	authGroup.Use(middleware.AuthMiddleware(a.TokenService, a.TokenRepo))
+	{
+		// 已绑定的第三方账号
+		authGroup.GET("/identities", h.Identities)
+		// 绑定第三方账号
+		authGroup.POST("/:provider/link", h.Link)
+		// 解绑第三方账号
+		authGroup.DELETE("/:provider/link", h.Unlink)
+	}
+}
@@ -0,0 +1,33 @@
+package routers
+
+import (
+	"blueLock/backend/internal/app"
+	"blueLock/backend/internal/controller"
+	"blueLock/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// UserRouter 当前登录用户的账号与设备路由
+func UserRouter(r *gin.Engine, a *app.App) {
+	h := controller.NewUserHandler(a.Login, a.Device)
+
+	user := r.Group("/user")
+	user.Use(middleware.BodyLimit(smallBodyLimit), middleware.AuthMiddleware(a.TokenService, a.TokenRepo))
+	{
+		// 修改密码
+		user.POST("/password", h.ChangePassword)
+		// 修改邮箱
+		user.PUT("/email", h.ChangeEmail)
+		// 修改语言偏好
+		user.PUT("/locale", h.UpdateLocale)
+		// 安全通知设置
+		user.GET("/notifications", h.NotificationSettings)
+		user.PUT("/notifications", h.UpdateNotification)
+		// 设备列表
+		user.GET("/devices", h.ListDevices)
+		// 绑定设备
+		user.POST("/devices", h.BindDevice)
+		// 解绑设备
+		user.DELETE("/devices/:id", h.UnbindDevice)
+	}
+}
@@ -1,7 +1,7 @@
 package main
 
-import "blueLock/backend/server"
+import "blueLock/backend/cmd"
 
 func main() {
-	server.Run()
+	cmd.Execute()
 }
@@ -1,18 +1,68 @@
 package router
 
 import (
+	"blueLock/backend/internal/app"
 	"blueLock/backend/internal/middleware"
 	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/i18n"
 	"blueLock/backend/internal/routers"
 	"github.com/gin-gonic/gin"
 )
 
-func SetUpRouter() {
-	// 创建 Gin 引擎
-	globals.Router = gin.Default()
+// SetUpRouter 创建 Gin 引擎并注册中间件和路由，依赖全部来自应用容器
+func SetUpRouter(a *app.App) *gin.Engine {
+	// 创建 Gin 引擎，访问日志由 RequestLogger 输出，不使用 gin 自带的 Logger
+	r := gin.New()
+	r.Use(gin.Recovery())
+	// 处理器把 *gin.Context 当作 context.Context 传给下层，需要回退到 c.Request.Context() 才能取到链路信息和请求日志器
+	r.ContextWithFallback = true
+	// 只采用可信代理传来的 X-Forwarded-For，默认不信任任何代理，否则客户端可以伪造 IP 绕过按 IP 的限流
+	if err := r.SetTrustedProxies(a.Config.App.TrustedProxies); err != nil {
+		a.Log.Fatalf("app.trusted_proxies 配置错误: %v", err)
+	}
 	
+	// 链路追踪，解析上游的 traceparent
+	r.Use(middleware.Tracing())
+	// 请求 ID、请求日志器和访问日志
+	r.Use(middleware.RequestLogger(a.Log))
+	// 请求方 IP 和 User-Agent，用于安全通知
+	r.Use(middleware.ClientInfo())
+	// 请求指标，放在错误处理之前以统计完整耗时和最终状态码
+	r.Use(middleware.Metrics())
+	// 统一错误处理
+	r.Use(middleware.ErrorHandler(a.Log))
+	// 请求体大小限制，默认 1MB
+	r.Use(middleware.BodyLimit(maxBodyBytes(a.Config.App.Server.MaxBodyBytes)))
+	// 安全响应头
+	r.Use(middleware.SecurityHeaders(a.Config.App.SecurityHeaders))
+	// 多语言协商
+	r.Use(i18n.Middleware())
 	// 跨域
-	globals.Router.Use(middleware.CorsMiddleware())
+	r.Use(middleware.CorsMiddleware(a.Cors))
+	// 存活、就绪检查和指标
+	routers.HealthRouter(r, a)
+	// 人机校验题目路由
+	routers.ChallengeRouter(r, a)
 	// 登录路由
-	routers.EmailLoginRouter(globals.Router)
+	routers.EmailLoginRouter(r, a)
+	// 第三方登录路由
+	routers.OAuthRouter(r, a)
+	// 用户账号与设备路由
+	routers.UserRouter(r, a)
+	// 管理端路由
+	routers.AdminRouter(r, a)
+	// 设备网关路由（mTLS）
+	routers.DeviceGatewayRouter(r, a)
+
+	// 兼容仍通过全局变量取路由的代码
+	globals.Router = r
+	return r
+}
+
+// maxBodyBytes 请求体大小的全局上限，未配置时为 1MB
+func maxBodyBytes(n int64) int64 {
+	if n <= 0 {
+		return 1 << 20
+	}
+	return n
 }
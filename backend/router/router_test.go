@@ -0,0 +1,90 @@
+package router_test
+
+import (
+	"blueLock/backend/internal/app"
+	"blueLock/backend/internal/pkg/database"
+	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/kv"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/router"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newRouter 用内存存储构造完整的路由，只配置测试需要的部分
+func newRouter(t *testing.T, trusted []string) http.Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store := kv.NewMemoryStore(0)
+	t.Cleanup(func() { _ = store.Close() })
+	cfg := &globals.Config{
+		App: globals.App{Port: 8090, TrustedProxies: trusted},
+		JWT: globals.JWTConfig{SecretKey: "test-secret-key-test-secret-key-0123", AccessTokenExpiry: time.Minute},
+		Challenge: globals.ChallengeConfig{
+			Providers:      []string{"pow"},
+			PowDifficulty:  8,
+			IssueIPLimit:   2,
+			MaxOutstanding: 100,
+		},
+	}
+	db, err := database.Open(globals.DatabaseConfig{Driver: database.DriverSQLite, Path: ":memory:"},
+		gormlogger.Default.LogMode(gormlogger.Silent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	levels := logger.Levels{Console: zap.NewAtomicLevel(), File: zap.NewAtomicLevel()}
+	a := app.New(cfg, zap.NewNop().Sugar(), levels, db, store)
+	return router.SetUpRouter(a)
+}
+
+// issue 以 remote 为连接对端地址、xff 为 X-Forwarded-For 获取一道题目，返回状态码
+func issue(h http.Handler, remote, xff string) int {
+	req := httptest.NewRequest(http.MethodPost, "/challenge/pow", nil)
+	req.RemoteAddr = remote + ":12345"
+	if xff != "" {
+		req.Header.Set("X-Forwarded-For", xff)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+// 默认不信任任何代理，每次伪造不同的 X-Forwarded-For 也计入同一个 IP
+func TestForgedForwardedForIgnored(t *testing.T) {
+	h := newRouter(t, nil)
+	for i, xff := range []string{"198.51.100.1", "198.51.100.2"} {
+		if code := issue(h, "192.0.2.1", xff); code != http.StatusOK {
+			t.Fatalf("第 %d 次获取题目: %d", i+1, code)
+		}
+	}
+	if code := issue(h, "192.0.2.1", "198.51.100.3"); code != http.StatusTooManyRequests {
+		t.Fatalf("伪造 X-Forwarded-For 绕过了单 IP 限制: %d", code)
+	}
+}
+
+// 来自可信代理的请求按 X-Forwarded-For 中的客户端 IP 计数
+func TestTrustedProxyForwardedFor(t *testing.T) {
+	h := newRouter(t, []string{"10.0.0.0/8"})
+	for i := 0; i < 2; i++ {
+		if code := issue(h, "10.0.0.1", "198.51.100.1"); code != http.StatusOK {
+			t.Fatalf("第 %d 次获取题目: %d", i+1, code)
+		}
+	}
+	if code := issue(h, "10.0.0.1", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("同一客户端超过限制: %d", code)
+	}
+	if code := issue(h, "10.0.0.1", "198.51.100.2"); code != http.StatusOK {
+		t.Fatalf("可信代理后的另一个客户端被限流: %d", code)
+	}
+}
@@ -2,63 +2,253 @@ package server
 
 import (
 	"blueLock/backend/init"
+	"blueLock/backend/internal/app"
+	"blueLock/backend/internal/pkg/audit"
+	"blueLock/backend/internal/pkg/certs"
 	"blueLock/backend/internal/pkg/globals"
+	"blueLock/backend/internal/pkg/lifecycle"
+	"blueLock/backend/internal/pkg/logger"
+	"blueLock/backend/internal/pkg/metrics"
+	"blueLock/backend/internal/pkg/tracing"
 	"blueLock/backend/router"
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 )
 
+// 未配置时使用的服务端超时和大小限制
+const (
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultReadTimeout       = 30 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultMaxHeaderBytes    = 64 << 10
+	defaultShutdownTimeout   = 15 * time.Second
+)
+
 func Run() {
 	inits.Init()
-	// 运行结束时，刷新日志的缓冲区（缓存区的信息写入到文件中）
-	defer func() {
-		if err := globals.Log.Sync(); err != nil {
-			fmt.Println("日志同步失败:", err)
+
+	// 退出时按阶段关闭：停止接收请求 → 停止后台任务 → 关闭连接池 → 刷新日志并关闭日志文件
+	lc := lifecycle.New(globals.Log)
+	lc.Register(lifecycle.PhasePools, "database", func(context.Context) error {
+		sqlDB, err := globals.DB.DB()
+		if err != nil {
+			return err
 		}
-	}()
+		return sqlDB.Close()
+	})
+	lc.Register(lifecycle.PhasePools, "kv", func(context.Context) error {
+		return globals.KV.Close()
+	})
+	// 停止日志文件的按天轮转并关闭文件。同一阶段按注册的逆序关闭，先注册保证在刷新缓冲区之后执行
+	lc.Register(lifecycle.PhaseLogs, "log-file", func(context.Context) error {
+		return globals.LogFile.Close()
+	})
+	// 刷新日志的缓冲区（缓存区的信息写入到文件中）
+	lc.Register(lifecycle.PhaseLogs, "log", func(context.Context) error {
+		return syncLog()
+	})
+	// 把队列中剩余的审计日志写入数据库
+	lc.Register(lifecycle.PhaseWorkers, "audit", func(context.Context) error {
+		audit.Close()
+		return nil
+	})
+	// 导出剩余的 span
+	lc.Register(lifecycle.PhaseWorkers, "tracing", tracing.Shutdown)
+
+	// 构造应用容器，之后各层只从容器取依赖
+	levels := logger.Levels{Console: globals.LogLevel, File: globals.FileLogLevel}
+	a := app.New(&globals.AppConfig, globals.Log, levels, globals.DB, globals.KV)
+	// 等待发送中的安全通知邮件
+	lc.Register(lifecycle.PhaseWorkers, "notify", a.Notifier.Close)
 
 	// 启动处理函数
-	router.SetUpRouter()
+	handler := router.SetUpRouter(a)
+
+	// 配置热更新：日志级别和跨域白名单
+	stopWatch, err := inits.WatchConfig(func(cfg *globals.Config) {
+		globals.LogLevel.SetLevel(inits.ParseLogLevel(cfg.Log.Level))
+		globals.FileLogLevel.SetLevel(inits.ParseFileLogLevel(cfg.Log.FileLevel))
+		a.Cors.SetAllowedOrigins(cfg.Cors.AllowedOrigins)
+	})
+	if err != nil {
+		// 监听失败不影响服务，只是修改配置后需要重启
+		a.Log.Warnf("配置热更新不可用: %v", err)
+	} else {
+		lc.Register(lifecycle.PhaseWorkers, "config-watcher", func(context.Context) error {
+			return stopWatch()
+		})
+	}
 
 	// 启动http服务+ 平滑关闭
-	Start()
+	if err := Start(a, handler, lc); err != nil {
+		log.Printf("服务异常退出: %v", err)
+		os.Exit(1)
+	}
 }
 
-func Start() {
+// Start 启动 HTTP(S) 服务并阻塞，收到退出信号或监听失败后通过 lc 平滑关闭所有组件
+func Start(a *app.App, handler http.Handler, lc *lifecycle.Manager) error {
+	cfg := a.Config.App
 	// 构造服务地址
-	addr := fmt.Sprintf("%s:%d", globals.AppConfig.App.Host, globals.AppConfig.App.Port)
+	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 
 	// 创建 HTTP 服务器
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: globals.Router, // 路由处理器
+		Addr:              addr,
+		Handler:           handler, // 路由处理器
+		ReadHeaderTimeout: orDefault(cfg.Server.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		ReadTimeout:       orDefault(cfg.Server.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:      orDefault(cfg.Server.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       orDefault(cfg.Server.IdleTimeout, defaultIdleTimeout),
+		MaxHeaderBytes:    orDefault(cfg.Server.MaxHeaderBytes, defaultMaxHeaderBytes),
+	}
+
+	// 设备网关只认 TLS 层校验过的客户端证书
+	if !cfg.TLS.Enabled || cfg.TLS.ClientAuth == "" || cfg.TLS.ClientAuth == certs.ClientAuthNone {
+		a.Log.Warnf("未启用客户端证书校验（app.tls.client_auth），设备网关 /gateway 将拒绝所有请求")
+	}
+
+	// HTTPS：证书文件更新后自动重新加载
+	var redirect *http.Server
+	if cfg.TLS.Enabled {
+		reloader, err := certs.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, a.Log)
+		if err != nil {
+			lc.Shutdown(orDefault(cfg.Server.ShutdownTimeout, defaultShutdownTimeout))
+			return fmt.Errorf("TLS 初始化失败: %w", err)
+		}
+		lc.Register(lifecycle.PhaseWorkers, "cert-reloader", func(context.Context) error {
+			return reloader.Close()
+		})
+		srv.TLSConfig = reloader.TLSConfig(cfg.TLS.MinVersion, cfg.TLS.ClientAuth)
+		if cfg.TLS.RedirectPort > 0 {
+			redirect = &http.Server{
+				Addr:              fmt.Sprintf("%s:%d", cfg.Host, cfg.TLS.RedirectPort),
+				Handler:           redirectToHTTPS(cfg.Port),
+				ReadHeaderTimeout: srv.ReadHeaderTimeout,
+				ReadTimeout:       srv.ReadTimeout,
+				WriteTimeout:      srv.WriteTimeout,
+				IdleTimeout:       srv.IdleTimeout,
+				MaxHeaderBytes:    srv.MaxHeaderBytes,
+			}
+		}
+	}
+
+	// 指标单独监听内部地址，不经过对外端口的路由和中间件
+	var metricsSrv *http.Server
+	if cfg.Metrics.Addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsSrv = &http.Server{
+			Addr:              cfg.Metrics.Addr,
+			Handler:           mux,
+			ReadHeaderTimeout: srv.ReadHeaderTimeout,
+			ReadTimeout:       srv.ReadTimeout,
+			WriteTimeout:      srv.WriteTimeout,
+			IdleTimeout:       srv.IdleTimeout,
+			MaxHeaderBytes:    srv.MaxHeaderBytes,
+		}
+	} else if cfg.Metrics.Token == "" {
+		a.Log.Warnf("未配置 app.metrics.addr 或 app.metrics.token，不提供 /metrics")
 	}
 
-	// 启动服务
+	// 先让就绪检查失败，等负载均衡摘除流量后再停止接收请求，并等待处理中的请求完成
+	lc.Register(lifecycle.PhaseServer, "http", func(ctx context.Context) error {
+		a.Health.SetDraining()
+		if delay := cfg.DrainDelay; delay > 0 {
+			a.Log.Infof("等待 %v 让负载均衡摘除流量", delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+		}
+		if redirect != nil {
+			_ = redirect.Shutdown(ctx)
+		}
+		if metricsSrv != nil {
+			_ = metricsSrv.Shutdown(ctx)
+		}
+		return srv.Shutdown(ctx)
+	})
+
+	// 启动服务，监听失败时通过 errc 触发关闭
+	errc := make(chan error, 3)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("服务启动失败: %v", err)
+		var err error
+		if srv.TLSConfig != nil {
+			// 证书由 TLSConfig 提供，这里不再传文件路径
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errc <- fmt.Errorf("服务启动失败: %w", err)
 		}
 	}()
-	log.Printf("服务启动成功，监听地址: %v", addr)
+	if redirect != nil {
+		go func() {
+			if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errc <- fmt.Errorf("HTTP 重定向服务启动失败: %w", err)
+			}
+		}()
+		log.Printf("HTTP 重定向服务已启动，监听地址: %v", redirect.Addr)
+	}
+	if metricsSrv != nil {
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errc <- fmt.Errorf("指标服务启动失败: %w", err)
+			}
+		}()
+		log.Printf("指标服务已启动，监听地址: %v", metricsSrv.Addr)
+	}
+	log.Printf("服务启动成功，监听地址: %v，HTTPS: %v", addr, cfg.TLS.Enabled)
 
 	// 优雅关闭
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Printf("收到退出信号，正在关闭服务...")
-
-	// 设置超时时间，优雅关闭
-	ctxTimeout, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancelTimeout()
-	if err := srv.Shutdown(ctxTimeout); err != nil {
-		log.Fatalf("优雅关闭失败: %v", err)
+	err := lifecycle.WaitForSignal(errc)
+	if err != nil {
+		log.Printf("%v，正在关闭服务...", err)
+	} else {
+		log.Printf("收到退出信号，正在关闭服务...")
+	}
+	lc.Shutdown(orDefault(cfg.Server.ShutdownTimeout, defaultShutdownTimeout))
+	log.Printf("服务器已关闭")
+	return err
+}
+
+// redirectToHTTPS 把 HTTP 请求永久重定向到同一主机的 HTTPS 端口
+func redirectToHTTPS(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// syncLog 刷新日志缓冲。标准输出是终端或管道时 Sync 会返回 EINVAL/ENOTTY，可以忽略
+func syncLog() error {
+	err := globals.Log.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+	return err
+}
+
+func orDefault[T int | int64 | time.Duration](v, def T) T {
+	if v <= 0 {
+		return def
 	}
-	log.Printf("服务器已正常关闭")
+	return v
 }